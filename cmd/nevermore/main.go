@@ -10,8 +10,6 @@ import (
 	_ "nevermore/docs"
 	"nevermore/internal/app"
 	exit "nevermore/pkg/context"
-
-	"github.com/spf13/viper"
 )
 
 //export GOOSE_DBSTRING="user=postgres password=1 dbname=nevermore sslmode=disable
@@ -25,25 +23,17 @@ import (
 
 //swag init --generalInfo cmd/Cringe-Networks/main.go --output docs
 
-func init() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("config")
-	fmt.Println("Loading config...")
-
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Ошибка чтения конфигурации: %s", err)
-	}
-}
-
 // @title		Nevermore API
 // @version		1.0
 // @description	API для Nevermore
+
+// @securityDefinitions.apikey	ApiKeyAuth
+// @in							header
+// @name						Authorization
 func main() {
 	app, err := app.New()
 	if err != nil {
-		fmt.Println(err)
-		return
+		log.Fatal(err)
 	}
 
 	ctx, cancel := exit.WithSignal(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
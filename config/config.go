@@ -2,23 +2,63 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"net"
+	"net/url"
+	"nevermore/pkg/jobs"
 	"nevermore/pkg/logger"
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/oidc"
+	"nevermore/pkg/ratelimit"
+	"nevermore/pkg/tracing"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
-
+	"nevermore/internal/service/auth"
+	"nevermore/internal/service/booktext"
+	"nevermore/internal/service/loginguard"
 	"nevermore/internal/storage/postgres"
+	"nevermore/internal/storage/redis"
+)
 
-	"github.com/spf13/viper"
+const (
+	RateLimitBackendRedis  = "redis"
+	RateLimitBackendMemory = "memory"
 )
 
+// Порты по умолчанию для адресов без порта, например DB_HOST=db и REDIS_HOST=redis из docker-compose
+const (
+	defaultPostgresPort = 5432
+	defaultRedisPort    = 6379
+)
+
+type RateLimitRule struct {
+	Burst  int           `mapstructure:"burst"`
+	Period time.Duration `mapstructure:"period"`
+}
+
+type RateLimitPolicy struct {
+	Anonymous     *RateLimitRule           `mapstructure:"anonymous"`
+	Authenticated *RateLimitRule           `mapstructure:"authenticated"`
+	Roles         map[string]RateLimitRule `mapstructure:"roles"`
+}
+
 type Config struct {
 	Server struct {
-		Port int    `mapstructure:"port"`
-		Host string `mapstructure:"host"`
+		Port           int      `mapstructure:"port"`
+		Host           string   `mapstructure:"host"`
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
 	} `mapstructure:"server"`
+	Shutdown struct {
+		DrainDelay     time.Duration `mapstructure:"drain_delay"`
+		HTTPTimeout    time.Duration `mapstructure:"http_timeout"`
+		JobsTimeout    time.Duration `mapstructure:"jobs_timeout"`
+		FlushTimeout   time.Duration `mapstructure:"flush_timeout"`
+		StorageTimeout time.Duration `mapstructure:"storage_timeout"`
+	} `mapstructure:"shutdown"`
 	Postgres struct {
 		Url      string `mapstructure:"url"`
+		Port     int    `mapstructure:"port"`
 		User     string `mapstructure:"user"`
 		Password string `mapstructure:"password"`
 		Name     string `mapstructure:"name"`
@@ -28,6 +68,7 @@ type Config struct {
 		User     string `mapstructure:"user"`
 		Password string `mapstructure:"password"`
 		Url      string `mapstructure:"url"`
+		Port     int    `mapstructure:"port"`
 		DB       int    `mapstructure:"db"`
 	} `mapstructure:"redis"`
 	Minio struct {
@@ -50,34 +91,156 @@ type Config struct {
 		TimeFormat        string `mapstructure:"time_format"`
 		ServiceName       string `mapstructure:"service_name"`
 	} `mapstructure:"logger"`
+	Mail struct {
+		Driver      string        `mapstructure:"driver"`
+		Host        string        `mapstructure:"host"`
+		Port        int           `mapstructure:"port"`
+		Username    string        `mapstructure:"username"`
+		Password    string        `mapstructure:"password"`
+		ImplicitTLS bool          `mapstructure:"implicit_tls"`
+		From        string        `mapstructure:"from"`
+		Dir         string        `mapstructure:"dir"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		RetryDelay  time.Duration `mapstructure:"retry_delay"`
+		SendTimeout time.Duration `mapstructure:"send_timeout"`
+	} `mapstructure:"mail"`
+	Jobs struct {
+		PollInterval  time.Duration  `mapstructure:"poll_interval"`
+		RetryDelay    time.Duration  `mapstructure:"retry_delay"`
+		MaxRetryDelay time.Duration  `mapstructure:"max_retry_delay"`
+		Concurrency   map[string]int `mapstructure:"concurrency"`
+	} `mapstructure:"jobs"`
+	Auth struct {
+		JWTSecret        string        `mapstructure:"jwt_secret"`
+		AppURL           string        `mapstructure:"app_url"`
+		AccessTokenTTL   time.Duration `mapstructure:"access_token_ttl"`
+		VerifyEmailTTL   time.Duration `mapstructure:"verify_email_ttl"`
+		ResetPasswordTTL time.Duration `mapstructure:"reset_password_ttl"`
+		UnlockAccountTTL time.Duration `mapstructure:"unlock_account_ttl"`
+		ResendCooldown   time.Duration `mapstructure:"resend_cooldown"`
+		TOTPIssuer       string        `mapstructure:"totp_issuer"`
+	} `mapstructure:"auth"`
+	Lockout struct {
+		MaxAccountFailures int           `mapstructure:"max_account_failures"`
+		MaxIPFailures      int           `mapstructure:"max_ip_failures"`
+		FreeFailures       int           `mapstructure:"free_failures"`
+		BaseDelay          time.Duration `mapstructure:"base_delay"`
+		MaxDelay           time.Duration `mapstructure:"max_delay"`
+		FailureWindow      time.Duration `mapstructure:"failure_window"`
+		Duration           time.Duration `mapstructure:"duration"`
+	} `mapstructure:"lockout"`
+	RateLimit struct {
+		Backend string          `mapstructure:"backend"`
+		Default RateLimitPolicy `mapstructure:"default"`
+		Routes  []struct {
+			Route           string `mapstructure:"route"`
+			RateLimitPolicy `mapstructure:",squash"`
+		} `mapstructure:"routes"`
+	} `mapstructure:"rate_limit"`
+	Features map[string]bool `mapstructure:"features"`
+	Cache    struct {
+		Backend string `mapstructure:"backend"`
+	} `mapstructure:"cache"`
+	Tracing struct {
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+		ServiceName string  `mapstructure:"service_name"`
+	} `mapstructure:"tracing"`
+	OIDC struct {
+		Providers []struct {
+			Name         string   `mapstructure:"name"`
+			Issuer       string   `mapstructure:"issuer"`
+			ClientID     string   `mapstructure:"client_id"`
+			ClientSecret string   `mapstructure:"client_secret"`
+			RedirectURL  string   `mapstructure:"redirect_url"`
+			Scopes       []string `mapstructure:"scopes"`
+		} `mapstructure:"providers"`
+	} `mapstructure:"oidc"`
 }
 
 func (c Config) Psql() postgres.Config {
-	result := postgres.Config{
-		URL: fmt.Sprintf("postgresql://%s:%s@%s/%s?sslmode=disable",
-			c.Postgres.User,
-			c.Postgres.Password,
-			c.Postgres.Url,
-			c.Postgres.Name,
-		),
+	// Логин и пароль экранирует url.URL: в пароле бывают '@', '#' и '/'
+	dsn := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(c.Postgres.User, c.Postgres.Password),
+		Host:     hostPort(c.Postgres.Url, c.Postgres.Port, defaultPostgresPort),
+		Path:     c.Postgres.Name,
+		RawQuery: "sslmode=disable",
+	}
 
+	result := postgres.Config{
+		URL:    dsn.String(),
 		Driver: c.Postgres.Driver,
 	}
 
 	return result
 }
 
-func (c Config) Srv() string {
-	return fmt.Sprintf(":%d", c.Server.Port)
+func (c Config) NewRedis() redis.Config {
+	return redis.Config{
+		Addr:     hostPort(c.Redis.Url, c.Redis.Port, defaultRedisPort),
+		Username: c.Redis.User,
+		Password: c.Redis.Password,
+		DB:       c.Redis.DB,
+	}
 }
 
-func Init() (Config, error) {
-	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
-		log.Fatalf("Ошибка декодирования конфигурации: %s", err)
+// hostPort добавляет порт к адресу, если в самом адресе порта нет: заданный
+// отдельно (DB_PORT, REDIS_PORT) или порт по умолчанию. Нужен ради go-redis: адрес
+// без порта он не дополняет и не подключается. lib/pq сам берёт 5432, для Postgres
+// порт подставляется только ради единообразия адресов
+func hostPort(addr string, port, defaultPort int) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+
+	if port == 0 {
+		port = defaultPort
 	}
 
-	return config, nil
+	return net.JoinHostPort(addr, strconv.Itoa(port))
+}
+
+// ObjectStorageHealthURL возвращает адрес пробы живости MinIO или пустую строку,
+// если хранилище объектов не настроено
+func (c Config) ObjectStorageHealthURL() string {
+	endpoint := c.objectStorageEndpoint()
+	if endpoint == "" {
+		return ""
+	}
+
+	return strings.TrimRight(endpoint, "/") + "/minio/health/live"
+}
+
+// ObjectStorageHost возвращает host[:port] хранилища объектов — единственный хост,
+// с которого скачиваются файлы книг, — или пустую строку, если хранилище не настроено
+func (c Config) ObjectStorageHost() string {
+	endpoint, err := url.Parse(c.objectStorageEndpoint())
+	if err != nil {
+		return ""
+	}
+
+	return endpoint.Host
+}
+
+// objectStorageEndpoint дописывает схему к endpoint, в конфиге её обычно не указывают
+func (c Config) objectStorageEndpoint() string {
+	endpoint := c.Minio.Endpoint
+	if endpoint == "" {
+		return ""
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	return endpoint
+}
+
+func (c Config) Srv() string {
+	return fmt.Sprintf(":%d", c.Server.Port)
 }
 
 func (c Config) NewLogger() logger.Config {
@@ -95,35 +258,119 @@ func (c Config) NewLogger() logger.Config {
 	}
 }
 
-func LoadConfig() (Config, error) {
-	_ = godotenv.Load()
+func (c Config) NewMailer() mailer.Config {
+	return mailer.Config{
+		Driver:      c.Mail.Driver,
+		Host:        c.Mail.Host,
+		Port:        c.Mail.Port,
+		Username:    c.Mail.Username,
+		Password:    c.Mail.Password,
+		ImplicitTLS: c.Mail.ImplicitTLS,
+		From:        c.Mail.From,
+		Dir:         c.Mail.Dir,
+		MaxAttempts: c.Mail.MaxAttempts,
+		RetryDelay:  c.Mail.RetryDelay,
+		SendTimeout: c.Mail.SendTimeout,
+	}
+}
 
-	viper.AutomaticEnv()
+func (c Config) NewJobs() jobs.Config {
+	return jobs.Config{
+		PollInterval:  c.Jobs.PollInterval,
+		RetryDelay:    c.Jobs.RetryDelay,
+		MaxRetryDelay: c.Jobs.MaxRetryDelay,
+		Concurrency:   c.Jobs.Concurrency,
+	}
+}
 
-	viper.BindEnv("app.name", "APP_NAME")
-	viper.BindEnv("app.version", "APP_VERSION")
-	viper.BindEnv("app.port", "APP_PORT")
+func (c Config) NewAuth() auth.Config {
+	return auth.Config{
+		AppURL:           c.Auth.AppURL,
+		AccessTokenTTL:   c.Auth.AccessTokenTTL,
+		VerifyEmailTTL:   c.Auth.VerifyEmailTTL,
+		ResetPasswordTTL: c.Auth.ResetPasswordTTL,
+		UnlockAccountTTL: c.Auth.UnlockAccountTTL,
+		ResendCooldown:   c.Auth.ResendCooldown,
+		TOTPIssuer:       c.Auth.TOTPIssuer,
+	}
+}
 
-	viper.BindEnv("database.url", "DB_HOST")
-	viper.BindEnv("database.user", "DB_USER")
-	viper.BindEnv("database.password", "DB_PASSWORD")
-	viper.BindEnv("database.name", "DB_NAME")
-	viper.BindEnv("database.driver", "DB_DRIVER")
+func (c Config) NewBookText() booktext.Config {
+	return booktext.Config{
+		FileHost: c.ObjectStorageHost(),
+	}
+}
 
-	viper.BindEnv("minio.endpoint", "MINIO_ENDPOINT")
-	viper.BindEnv("minio.access_key", "MINIO_ACCESS_KEY")
-	viper.BindEnv("minio.secret_key", "MINIO_SECRET_KEY")
-	viper.BindEnv("minio.bucket", "MINIO_BUCKET")
+func (c Config) NewLoginGuard() loginguard.Config {
+	return loginguard.Config{
+		MaxAccountFailures: c.Lockout.MaxAccountFailures,
+		MaxIPFailures:      c.Lockout.MaxIPFailures,
+		FreeFailures:       c.Lockout.FreeFailures,
+		BaseDelay:          c.Lockout.BaseDelay,
+		MaxDelay:           c.Lockout.MaxDelay,
+		FailureWindow:      c.Lockout.FailureWindow,
+		LockoutDuration:    c.Lockout.Duration,
+	}
+}
+
+func (c Config) NewRateLimit() ratelimit.Policies {
+	result := ratelimit.Policies{
+		Default: c.RateLimit.Default.policy(),
+		Routes:  make(map[string]ratelimit.Policy, len(c.RateLimit.Routes)),
+	}
 
-	viper.BindEnv("redis.url", "REDIS_HOST")
-	viper.BindEnv("redis.user", "REDIS_USER")
-	viper.BindEnv("redis.password", "REDIS_PASSWORD")
-	viper.BindEnv("redis.db", "REDIS_DB")
+	for _, route := range c.RateLimit.Routes {
+		result.Routes[route.Route] = route.policy()
+	}
+
+	return result
+}
 
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		log.Fatalf("Ошибка декодирования конфигурации: %s", err)
+func (p RateLimitPolicy) policy() ratelimit.Policy {
+	result := ratelimit.Policy{
+		Roles: make(map[string]ratelimit.Limit, len(p.Roles)),
 	}
 
-	return cfg, nil
+	if p.Anonymous != nil {
+		limit := ratelimit.Limit(*p.Anonymous)
+		result.Anonymous = &limit
+	}
+
+	if p.Authenticated != nil {
+		limit := ratelimit.Limit(*p.Authenticated)
+		result.Authenticated = &limit
+	}
+
+	for role, rule := range p.Roles {
+		result.Roles[role] = ratelimit.Limit(rule)
+	}
+
+	return result
+}
+
+func (c Config) NewOIDC() []oidc.Config {
+	result := make([]oidc.Config, 0, len(c.OIDC.Providers))
+
+	for _, provider := range c.OIDC.Providers {
+		result = append(result, oidc.Config{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		})
+	}
+
+	return result
+}
+
+func (c Config) NewTracing() tracing.Config {
+	return tracing.Config{
+		Exporter:    c.Tracing.Exporter,
+		Endpoint:    c.Tracing.Endpoint,
+		Insecure:    c.Tracing.Insecure,
+		SampleRatio: c.Tracing.SampleRatio,
+		ServiceName: c.Tracing.ServiceName,
+	}
 }
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		port int
+		want string
+	}{
+		{name: "port in url", url: "localhost:6380", want: "localhost:6380"},
+		{name: "port in url wins over separate port", url: "localhost:6380", port: 6390, want: "localhost:6380"},
+		{name: "separate port", url: "redis", port: 6390, want: "redis:6390"},
+		{name: "default port", url: "redis", want: "redis:6379"},
+		{name: "ipv6 without port", url: "::1", want: "[::1]:6379"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostPort(tt.url, tt.port, defaultRedisPort); got != tt.want {
+				t.Fatalf("hostPort(%q, %d) = %q, want %q", tt.url, tt.port, got, tt.want)
+			}
+		})
+	}
+}
+
+// Окружение из docker-compose: REDIS_HOST и DB_HOST без портов, REDIS_PORT и DB_PORT не заданы
+func TestInitComposeEnvironment(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "config.yaml")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_NAME", "dima")
+	t.Setenv("DB_USER", "dima")
+	t.Setenv("DB_PASSWORD", "1")
+	t.Setenv("REDIS_HOST", "redis")
+	t.Setenv("JWT_SECRET", "compose-test-secret-0123456789abcdef")
+
+	cfg, err := Init()
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	if got := cfg.NewRedis().Addr; got != "redis:6379" {
+		t.Fatalf("redis addr = %q, want %q", got, "redis:6379")
+	}
+
+	if got, want := cfg.Psql().URL, "postgresql://dima:1@db:5432/dima?sslmode=disable"; got != want {
+		t.Fatalf("postgres url = %q, want %q", got, want)
+	}
+}
+
+// Пароль из .env со спецсимволами, которые ломают URL без экранирования
+func TestPsqlEscapesCredentials(t *testing.T) {
+	var cfg Config
+	cfg.Postgres.Url = "db"
+	cfg.Postgres.User = "dima"
+	cfg.Postgres.Password = "p@ss#97MR14F9X6>rd5~0RctT.p!G*x/"
+	cfg.Postgres.Name = "dima"
+
+	conn, err := pq.ParseURL(cfg.Psql().URL)
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+
+	for _, want := range []string{"host='db'", "port='5432'", "user='dima'", "dbname='dima'",
+		`password='p@ss#97MR14F9X6>rd5~0RctT.p!G*x/'`} {
+		if !strings.Contains(conn, want) {
+			t.Fatalf("connection string %q has no %q", conn, want)
+		}
+	}
+}
+
+func TestObjectStorageHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     string
+	}{
+		{name: "without scheme", endpoint: "minio:9000", want: "minio:9000"},
+		{name: "with scheme", endpoint: "https://files.example.com/", want: "files.example.com"},
+		{name: "not configured", endpoint: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			cfg.Minio.Endpoint = tt.endpoint
+
+			if got := cfg.ObjectStorageHost(); got != tt.want {
+				t.Fatalf("ObjectStorageHost() with %q = %q, want %q", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultConfigFile = "config/config.yaml"
+	redactedValue     = "***"
+)
+
+// envAliases — короткие имена переменных из docker-compose и .env. Каноническое имя
+// ключа (POSTGRES_URL для postgres.url) работает всегда и идёт первым
+var envAliases = map[string][]string{
+	"postgres.url":      {"DB_HOST"},
+	"postgres.port":     {"DB_PORT"},
+	"postgres.user":     {"DB_USER"},
+	"postgres.password": {"DB_PASSWORD"},
+	"postgres.name":     {"DB_NAME"},
+	"postgres.driver":   {"DB_DRIVER"},
+
+	"redis.url":      {"REDIS_HOST"},
+	"redis.port":     {"REDIS_PORT"},
+	"redis.user":     {"REDIS_USER"},
+	"redis.password": {"REDIS_PASSWORD"},
+
+	"mail.host":     {"SMTP_HOST"},
+	"mail.port":     {"SMTP_PORT"},
+	"mail.username": {"SMTP_USERNAME"},
+	"mail.password": {"SMTP_PASSWORD"},
+
+	"auth.jwt_secret": {"JWT_SECRET"},
+	"auth.app_url":    {"APP_URL"},
+}
+
+// secretMarkers — части ключей, значения которых не попадают в дамп конфигурации
+var secretMarkers = []string{"password", "secret", "access_key"}
+
+// Init собирает конфигурацию из слоёв, каждый следующий перекрывает предыдущий:
+// значения по умолчанию, YAML (CONFIG_FILE или config/config.yaml), .env,
+// переменные окружения и файлы секретов из переменных *_FILE
+func Init() (Config, error) {
+	var config Config
+
+	setDefaults()
+
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	viper.SetConfigFile(path)
+
+	// Без файла можно запуститься на значениях по умолчанию и окружении,
+	// но явно указанный CONFIG_FILE обязан существовать
+	err := viper.ReadInConfig()
+	if err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+		return config, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	// .env не перекрывает переменные, уже заданные в окружении
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return config, fmt.Errorf("read .env: %w", err)
+	}
+
+	for _, key := range viper.AllKeys() {
+		names := envNames(key)
+
+		if err := viper.BindEnv(append([]string{key}, names...)...); err != nil {
+			return config, err
+		}
+
+		if err := readSecretFile(key, names); err != nil {
+			return config, err
+		}
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		return config, fmt.Errorf("decode config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+func setDefaults() {
+	viper.SetDefault("server.port", 3000)
+	viper.SetDefault("server.host", "localhost")
+	viper.SetDefault("server.trusted_proxies", []string{})
+
+	viper.SetDefault("shutdown.drain_delay", 5*time.Second)
+	viper.SetDefault("shutdown.http_timeout", 20*time.Second)
+	viper.SetDefault("shutdown.jobs_timeout", 30*time.Second)
+	viper.SetDefault("shutdown.flush_timeout", 5*time.Second)
+	viper.SetDefault("shutdown.storage_timeout", 5*time.Second)
+
+	viper.SetDefault("postgres.url", "localhost:5432")
+	viper.SetDefault("postgres.port", 0)
+	viper.SetDefault("postgres.user", "")
+	viper.SetDefault("postgres.password", "")
+	viper.SetDefault("postgres.name", "nevermore")
+	viper.SetDefault("postgres.driver", "postgres")
+
+	viper.SetDefault("redis.url", "localhost:6379")
+	viper.SetDefault("redis.port", 0)
+	viper.SetDefault("redis.user", "")
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
+
+	viper.SetDefault("minio.endpoint", "")
+	viper.SetDefault("minio.access_key", "")
+	viper.SetDefault("minio.secret_key", "")
+
+	viper.SetDefault("logger.level", "INFO")
+
+	viper.SetDefault("mail.driver", "dir")
+	viper.SetDefault("mail.dir", "runtime/mail")
+	viper.SetDefault("mail.host", "")
+	viper.SetDefault("mail.port", 587)
+	viper.SetDefault("mail.username", "")
+	viper.SetDefault("mail.password", "")
+	viper.SetDefault("mail.from", "")
+
+	viper.SetDefault("jobs.poll_interval", time.Second)
+	viper.SetDefault("jobs.retry_delay", 10*time.Second)
+	viper.SetDefault("jobs.max_retry_delay", time.Hour)
+
+	viper.SetDefault("auth.jwt_secret", "")
+	viper.SetDefault("auth.app_url", "http://localhost:5173")
+
+	viper.SetDefault("rate_limit.backend", RateLimitBackendRedis)
+	viper.SetDefault("cache.backend", cache.BackendRedis)
+
+	viper.SetDefault("tracing.exporter", tracing.ExporterNone)
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", false)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.service_name", "nevermore")
+}
+
+// envNames возвращает переменные окружения для ключа: каноническое имя и псевдонимы
+func envNames(key string) []string {
+	names := []string{strings.ToUpper(strings.ReplaceAll(key, ".", "_"))}
+
+	return append(names, envAliases[key]...)
+}
+
+// readSecretFile подставляет содержимое файла из <ИМЯ>_FILE, например
+// POSTGRES_PASSWORD_FILE=/run/secrets/db_password, поверх остальных источников
+func readSecretFile(key string, names []string) error {
+	for _, name := range names {
+		path := os.Getenv(name + "_FILE")
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s_FILE: %w", name, err)
+		}
+
+		viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+
+		return nil
+	}
+
+	return nil
+}
+
+// Redacted возвращает итоговую конфигурацию со скрытыми секретами для лога при старте
+func Redacted() map[string]any {
+	return redact(viper.AllSettings()).(map[string]any)
+}
+
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			if isSecret(key) && item != "" {
+				result[key] = redactedValue
+				continue
+			}
+
+			result[key] = redact(item)
+		}
+
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			result = append(result, redact(item))
+		}
+
+		return result
+	default:
+		return v
+	}
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+
+	return slices.ContainsFunc(secretMarkers, func(marker string) bool {
+		return strings.Contains(key, marker)
+	})
+}
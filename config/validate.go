@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/ratelimit"
+	"nevermore/pkg/tracing"
+)
+
+// minJWTSecretLength — короче HMAC-ключ для HS256 перебирается слишком легко
+const minJWTSecretLength = 32
+
+// weakJWTSecrets — заглушки из примеров, с которыми токены может подделать кто угодно
+var weakJWTSecrets = []string{"change-me", "changeme", "secret"}
+
+// Validate проверяет конфигурацию до подключения к зависимостям и возвращает
+// все найденные ошибки разом, чтобы их можно было исправить за один запуск
+func (c Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535")
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validProxy(proxy), "server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+	}
+	check(c.Shutdown.DrainDelay >= 0, "shutdown.drain_delay must not be negative")
+	check(c.Shutdown.HTTPTimeout > 0 && c.Shutdown.JobsTimeout > 0 && c.Shutdown.FlushTimeout > 0 &&
+		c.Shutdown.StorageTimeout > 0, "shutdown timeouts must be positive")
+
+	check(c.Postgres.Url != "", "postgres.url is required")
+	check(c.Postgres.Name != "", "postgres.name is required")
+	check(c.Postgres.Driver != "", "postgres.driver is required")
+	check(c.Postgres.Port >= 0 && c.Postgres.Port <= 65535, "postgres.port must be between 0 and 65535")
+
+	check(c.Redis.Url != "", "redis.url is required")
+	check(c.Redis.Port >= 0 && c.Redis.Port <= 65535, "redis.port must be between 0 and 65535")
+	check(c.Redis.DB >= 0, "redis.db must not be negative")
+
+	check(c.Logger.Level == "" || logger.ValidLevel(c.Logger.Level),
+		"logger.level must be one of DEBUG, INFO, WARNING, ERROR, FATAL, PANIC")
+
+	switch c.Mail.Driver {
+	case "", mailer.DriverDir:
+	case mailer.DriverSMTP:
+		check(c.Mail.Host != "", "mail.host is required for the smtp driver")
+		check(c.Mail.Port > 0 && c.Mail.Port <= 65535, "mail.port must be between 1 and 65535")
+	default:
+		check(false, "mail.driver must be %s or %s", mailer.DriverSMTP, mailer.DriverDir)
+	}
+
+	check(c.Jobs.PollInterval > 0 && c.Jobs.RetryDelay > 0, "jobs.poll_interval and jobs.retry_delay must be positive")
+	check(c.Jobs.MaxRetryDelay >= c.Jobs.RetryDelay, "jobs.max_retry_delay must not be less than jobs.retry_delay")
+	for queue, n := range c.Jobs.Concurrency {
+		check(n > 0, "jobs.concurrency.%s must be positive", queue)
+	}
+
+	switch {
+	case c.Auth.JWTSecret == "":
+		check(false, "auth.jwt_secret is required")
+	case slices.Contains(weakJWTSecrets, c.Auth.JWTSecret):
+		check(false, "auth.jwt_secret must not be a placeholder value")
+	default:
+		check(len(c.Auth.JWTSecret) >= minJWTSecretLength, "auth.jwt_secret must be at least %d bytes", minJWTSecretLength)
+	}
+
+	check(c.Auth.AccessTokenTTL >= 0 && c.Auth.VerifyEmailTTL >= 0 && c.Auth.ResetPasswordTTL >= 0 &&
+		c.Auth.UnlockAccountTTL >= 0 && c.Auth.ResendCooldown >= 0, "auth durations must not be negative")
+
+	check(c.Lockout.MaxAccountFailures >= 0 && c.Lockout.MaxIPFailures >= 0 && c.Lockout.FreeFailures >= 0,
+		"lockout limits must not be negative")
+	check(c.Lockout.BaseDelay >= 0 && c.Lockout.MaxDelay >= 0 && c.Lockout.FailureWindow >= 0 && c.Lockout.Duration >= 0,
+		"lockout durations must not be negative")
+
+	check(c.RateLimit.Backend == RateLimitBackendRedis || c.RateLimit.Backend == RateLimitBackendMemory,
+		"rate_limit.backend must be %s or %s", RateLimitBackendRedis, RateLimitBackendMemory)
+	errs = append(errs, c.RateLimit.Default.validate("rate_limit.default")...)
+
+	routes := make(map[string]bool, len(c.RateLimit.Routes))
+	for i, route := range c.RateLimit.Routes {
+		check(route.Route != "", "rate_limit.routes[%d].route is required", i)
+		check(!routes[route.Route], "rate_limit.routes[%d]: duplicate route %q", i, route.Route)
+		routes[route.Route] = true
+
+		errs = append(errs, route.validate(fmt.Sprintf("rate_limit.routes[%d]", i))...)
+	}
+
+	check(c.Cache.Backend == cache.BackendRedis || c.Cache.Backend == cache.BackendMemory,
+		"cache.backend must be %s or %s", cache.BackendRedis, cache.BackendMemory)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		check(c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+	default:
+		check(false, "tracing.exporter must be %s, %s or %s", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	names := make(map[string]bool, len(c.OIDC.Providers))
+	for i, provider := range c.OIDC.Providers {
+		check(provider.Name != "", "oidc.providers[%d].name is required", i)
+		check(!names[provider.Name], "oidc.providers[%d]: duplicate provider %q", i, provider.Name)
+		names[provider.Name] = true
+
+		check(provider.Issuer != "" && provider.ClientID != "" && provider.RedirectURL != "",
+			"oidc.providers[%d]: issuer, client_id and redirect_url are required", i)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p RateLimitPolicy) validate(path string) []error {
+	var errs []error
+
+	rules := make(map[string]*RateLimitRule, len(p.Roles)+2)
+	rules[path+".anonymous"] = p.Anonymous
+	rules[path+".authenticated"] = p.Authenticated
+
+	for role, rule := range p.Roles {
+		rules[path+".roles."+role] = &rule
+	}
+
+	for name, rule := range rules {
+		if rule != nil && !ratelimit.Limit(*rule).Valid() {
+			errs = append(errs, fmt.Errorf("%s: burst must be positive and period at least 1ms", name))
+		}
+	}
+
+	return errs
+}
+
+func validProxy(proxy string) bool {
+	if _, _, err := net.ParseCIDR(proxy); err == nil {
+		return true
+	}
+
+	return net.ParseIP(proxy) != nil
+}
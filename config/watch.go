@@ -0,0 +1,73 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"nevermore/pkg/logger"
+)
+
+// Watch следит за файлом конфигурации и передаёт в apply новую версию, если она
+// разобралась и прошла проверку. Иначе изменение отклоняется с записью в лог, а
+// сервер продолжает работать со старой конфигурацией. На лету применяются только
+// уровень логгера, rate_limit и features, об остальных изменениях лог предупреждает
+func Watch(current Config, apply func(Config)) {
+	if viper.ConfigFileUsed() == "" {
+		return
+	}
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log := logger.Named("config")
+
+		// viper молча оставляет старые значения, если файл не разобрался,
+		// поэтому разбираем его ещё раз сами
+		probe := viper.New()
+		probe.SetConfigFile(viper.ConfigFileUsed())
+
+		if err := probe.ReadInConfig(); err != nil {
+			log.Error().Err(err).Str("file", e.Name).Msg("config reload rejected")
+			return
+		}
+
+		// Редактор может сначала обрезать файл, а потом записать его заново;
+		// пустой файл применил бы одни значения по умолчанию
+		if len(probe.AllKeys()) == 0 {
+			log.Error().Str("file", e.Name).Msg("config reload rejected: file is empty")
+			return
+		}
+
+		var next Config
+		if err := viper.Unmarshal(&next); err != nil {
+			log.Error().Err(err).Str("file", e.Name).Msg("config reload rejected")
+			return
+		}
+
+		if err := next.Validate(); err != nil {
+			log.Error().Err(err).Str("file", e.Name).Msg("config reload rejected")
+			return
+		}
+
+		if !reflect.DeepEqual(static(current), static(next)) {
+			log.Warn().Msg("config changes outside logger.level, rate_limit and features require a restart")
+		}
+
+		apply(next)
+		current = next
+
+		log.Info().Interface("config", Redacted()).Msg("config reloaded")
+	})
+
+	viper.WatchConfig()
+}
+
+// static оставляет только то, что применяется при перезапуске
+func static(c Config) Config {
+	c.Logger.Level = ""
+	c.RateLimit.Default = RateLimitPolicy{}
+	c.RateLimit.Routes = nil
+	c.Features = nil
+
+	return c
+}
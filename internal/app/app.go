@@ -2,85 +2,158 @@ package app
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"nevermore/internal/transport/handler"
 	"nevermore/pkg/logger"
-
-	"github.com/gammazero/workerpool"
+	"time"
 
 	"nevermore/config"
 	"nevermore/internal/service"
 	"nevermore/internal/storage"
+	"nevermore/pkg/auth"
+	exit "nevermore/pkg/context"
+	"nevermore/pkg/flags"
 	"nevermore/pkg/hash"
+	"nevermore/pkg/health"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/ratelimit"
+	"nevermore/pkg/tracing"
 )
 
+// readinessTimeout — сколько ждём ответа каждой зависимости в /readyz
+const readinessTimeout = 2 * time.Second
+
 type App struct {
-	server *http.Server
-	wp     *workerpool.WorkerPool
+	server   *http.Server
+	srv      service.Service
+	jobs     *jobs.Queue
+	shutdown *exit.Shutdown
 }
 
 func New() (*App, error) {
 	cfg, err := config.Init()
+	if err != nil {
+		return nil, err
+	}
 
 	if err := logger.Init(cfg.NewLogger()); err != nil {
 		panic(err)
 	}
 
-	db, err := storage.New(cfg.Psql())
+	log := logger.Named("config")
+	log.Info().Interface("config", config.Redacted()).Msg("config loaded")
+
+	// Трассировка поднимается до хранилища, чтобы SQL-спаны шли в настроенный экспортёр
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.NewTracing())
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := storage.New(cfg.Psql(), cfg.NewRedis(), cfg.Cache.Backend)
 	if err != nil {
 		return nil, err
 	}
 
-	hasher := hash.NewSHA1Hasher("aboba")
+	// Старые пароли посчитаны SHA1 с этой солью и пересчитываются в bcrypt при входе
+	hasher := hash.NewBcryptHasher(0, hash.NewSHA1Hasher("aboba"))
 
 	if err != nil {
 		return nil, err
 	}
 
-	wp := workerpool.New(100)
+	metrics.RegisterDB(db.DB().Stats)
+
+	m, err := mailer.New(cfg.NewMailer())
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := auth.NewManager(cfg.Auth.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	toggles := flags.NewToggles(cfg.Features)
+
+	// Типы задач регистрируются здесь же, до запуска очереди в Run
+	jobQueue := jobs.NewQueue(db.DB().Job(), cfg.NewJobs())
+
+	srv := service.New(db, hasher, m, mailer.NewQueue(m, jobQueue, cfg.NewMailer()), jobQueue, tokens, cfg.NewAuth(), cfg.NewBookText(), cfg.NewLoginGuard(), cfg.NewOIDC(), toggles)
+
+	var limiter ratelimit.Limiter = ratelimit.NewFallback(ratelimit.NewRedis(db.Redis().Client()), ratelimit.NewMemory())
+	if cfg.RateLimit.Backend == config.RateLimitBackendMemory {
+		limiter = ratelimit.NewMemory()
+	}
+
+	policies := ratelimit.NewPolicyStore(cfg.NewRateLimit())
+
+	checker := health.NewChecker(readinessTimeout)
+	checker.Add("postgres", db.DB().Ping)
+	// Без Redis работают лимитер в памяти и чтение без кэша, поэтому его отказ не снимает трафик
+	checker.AddOptional("redis", db.Redis().Ping)
+	if url := cfg.ObjectStorageHealthURL(); url != "" {
+		checker.Add("object_storage", health.HTTP(http.DefaultClient, url))
+	}
+
+	config.Watch(cfg, func(next config.Config) {
+		logger.SetLevel(next.Logger.Level)
+		policies.Replace(next.NewRateLimit())
+		toggles.Replace(next.Features)
+	})
+
+	router, err := handler.New(srv, limiter, policies, toggles, checker, cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
-	srv := service.New(db, hasher, wp)
+	server := &http.Server{
+		Addr:    cfg.Srv(),
+		Handler: router,
+	}
 
 	result := &App{
-		server: &http.Server{
-			Addr:    cfg.Srv(),
-			Handler: handler.New(srv),
-		},
-		wp: wp,
+		server:   server,
+		srv:      srv,
+		jobs:     jobQueue,
+		shutdown: newShutdown(cfg, server, checker, jobQueue, shutdownTracing, db),
 	}
 
 	return result, nil
 }
 
+// Run обслуживает запросы до отмены ctx и возвращается только после всех фаз остановки:
+// следом main завершает процесс, и недоделанное пропало бы
 func (a *App) Run(ctx context.Context) error {
 	log := logger.Get()
-	go func() {
-		select {
-		case <-ctx.Done():
-			fmt.Println("Shutting down the server...")
-
-			err := a.server.Shutdown(context.Background())
-			if err != nil {
-				fmt.Println(err)
-			}
-
-			a.wp.StopWait()
 
-			fmt.Println("Server shutting down successfully")
+	go a.srv.Follow().RunNotifier(ctx)
+	go a.srv.Flag().RunRefresher(ctx)
+	go a.jobs.Run(ctx)
 
-			return
-		}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.server.ListenAndServe()
 	}()
 
 	log.Info().Msg("Server started")
 
-	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Shutting down the server...")
+
+	// Журнал закрывается последней фазой, поэтому дальше только stdout
+	if err := a.shutdown.Run(context.Background()); err != nil {
 		return err
 	}
 
-	log.Info().Msg("Server stopped")
+	fmt.Println("Server shutting down successfully")
 
 	return nil
 }
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"nevermore/config"
+	"nevermore/internal/storage"
+	exit "nevermore/pkg/context"
+	"nevermore/pkg/health"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/logger"
+)
+
+// drainSlack — запас к drain_delay, чтобы фаза ожидания не считалась просроченной
+const drainSlack = time.Second
+
+// newShutdown собирает фазы остановки в порядке зависимостей: сначала перестаём
+// получать трафик, потом доделываем начатое, в конце закрываем хранилище и журнал
+func newShutdown(cfg config.Config, server *http.Server, checker *health.Checker, jobQueue *jobs.Queue,
+	flushTracing func(context.Context) error, st storage.Storage) *exit.Shutdown {
+	result := exit.NewShutdown(reportPhase)
+
+	// /readyz отвечает 503, ждём, пока балансировщик заметит и снимет трафик
+	result.Add("drain", cfg.Shutdown.DrainDelay+drainSlack, func(ctx context.Context) error {
+		checker.Drain()
+
+		select {
+		case <-time.After(cfg.Shutdown.DrainDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	// Новые соединения не принимаются, начатые запросы доходят до конца
+	result.Add("http", cfg.Shutdown.HTTPTimeout, server.Shutdown)
+
+	// Начатые задачи доделываются; прерванные по таймауту вернутся в очередь, когда истечёт аренда
+	result.Add("jobs", cfg.Shutdown.JobsTimeout, jobQueue.Wait)
+
+	result.Add("tracing", cfg.Shutdown.FlushTimeout, flushTracing)
+
+	result.Add("storage", cfg.Shutdown.StorageTimeout, func(context.Context) error {
+		return st.Close()
+	})
+
+	result.Add("logs", cfg.Shutdown.FlushTimeout, func(context.Context) error {
+		return logger.Close()
+	})
+
+	return result
+}
+
+func reportPhase(result exit.PhaseResult) {
+	log := logger.Named("shutdown")
+
+	switch {
+	case result.TimedOut:
+		log.Warn().Str("phase", result.Name).Dur("duration", result.Duration).Msg("shutdown phase timed out")
+	case result.Err != nil:
+		log.Error().Err(result.Err).Str("phase", result.Name).Dur("duration", result.Duration).Msg("shutdown phase failed")
+	default:
+		log.Info().Str("phase", result.Name).Dur("duration", result.Duration).Msg("shutdown phase done")
+	}
+}
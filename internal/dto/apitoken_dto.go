@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"nevermore/internal/model/apitoken"
+)
+
+type CreateAPITokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresInDays — срок действия в днях, 0 — срок по умолчанию
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// CreateAPITokenResponse содержит сам токен — больше его получить нельзя
+type CreateAPITokenResponse struct {
+	apitoken.Token
+	Secret string `json:"token"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+type AuthorRequest struct {
+	Name      string  `json:"name"`
+	Biography *string `json:"biography"`
+	PhotoUrl  *string `json:"photo_url"`
+	BirthDate *string `json:"birth_date" example:"1828-09-09"`
+	DeathDate *string `json:"death_date" example:"1910-11-20"`
+}
+
+type AuthorAliasRequest struct {
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"`
+	Locale *string `json:"locale"`
+}
+
+type MergeAuthorsRequest struct {
+	SourceId int `json:"source_id"`
+}
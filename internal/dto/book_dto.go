@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"nevermore/internal/model/book"
+)
+
+type BookListFilter struct {
+	GenreId  *int
+	Tag      string
+	SeriesId *int
+	AuthorId *int
+	Limit    int
+	Offset   int
+}
+
+type BookListItem struct {
+	Id            int                `db:"id" json:"id"`
+	Title         string             `db:"title" json:"title"`
+	CoverImageUrl *string            `db:"cover_image_url" json:"cover_image_url"`
+	SeriesId      *int               `db:"series_id" json:"series_id"`
+	SeriesVolume  *int               `db:"series_volume" json:"series_volume"`
+	Contributors  []book.Contributor `db:"-" json:"contributors"`
+}
+
+type BookListResponse struct {
+	Total int            `json:"total"`
+	Books []BookListItem `json:"books"`
+}
+
+type SetBookGenresRequest struct {
+	GenreIds []int `json:"genre_ids"`
+}
+
+type AddBookTagRequest struct {
+	Name string `json:"name"`
+}
+
+type SetBookSeriesRequest struct {
+	SeriesId *int `json:"series_id"`
+	Volume   *int `json:"volume"`
+}
+
+type BookContributorRequest struct {
+	AuthorId int    `json:"author_id"`
+	Role     string `json:"role"`
+}
+
+type SetBookContributorsRequest struct {
+	Contributors []BookContributorRequest `json:"contributors"`
+}
+
+// BookRating — сводка личных оценок читателей с полок (от 1 до 10)
+type BookRating struct {
+	Count        int                `json:"count"`
+	Average      *float64           `json:"average"`
+	Distribution []BookRatingBucket `json:"distribution"`
+}
+
+type BookRatingBucket struct {
+	Rating int `db:"rating" json:"rating"`
+	Count  int `db:"count" json:"count"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+type BookTextSearchRequest struct {
+	BookId int
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// BookTextHit — фрагмент текста книги. Snippet — HTML: текст экранирован, совпадения обёрнуты в <mark>
+type BookTextHit struct {
+	FragmentId   int64   `db:"fragment_id" json:"fragment_id"`
+	Position     int     `db:"position" json:"position"`
+	ChapterIndex *int    `db:"chapter_index" json:"chapter_index,omitempty"`
+	Cfi          *string `db:"cfi" json:"cfi,omitempty"`
+	Page         *int    `db:"page" json:"page,omitempty"`
+	Snippet      string  `db:"snippet" json:"snippet"`
+	Rank         float64 `db:"rank" json:"rank"`
+}
+
+type BookTextSearchResponse struct {
+	Total int           `json:"total"`
+	Hits  []BookTextHit `json:"hits"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type FlagRequest struct {
+	Description *string  `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Roles       []string `json:"roles"`
+	UserIds     []int    `json:"user_ids"`
+	Percentage  int      `json:"percentage"`
+}
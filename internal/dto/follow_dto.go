@@ -0,0 +1,13 @@
+package dto
+
+import (
+	"time"
+)
+
+type FollowedAuthor struct {
+	Id         int       `db:"id" json:"id"`
+	Name       string    `db:"name" json:"name"`
+	PhotoUrl   *string   `db:"photo_url" json:"photo_url"`
+	BookCount  int       `db:"book_count" json:"book_count"`
+	FollowedAt time.Time `db:"followed_at" json:"followed_at"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type OIDCLinkResponse struct {
+	URL string `json:"url"`
+}
+
+// OIDCCallbackResponse содержит результат входа или, для привязки провайдера к профилю, флаг Linked
+type OIDCCallbackResponse struct {
+	AuthResponse
+	Linked   bool   `json:"linked,omitempty"`
+	Provider string `json:"provider"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"nevermore/pkg/jobs"
+)
+
+type JobListRequest struct {
+	Status string
+	Queue  string
+	Type   string
+	Limit  int
+	Offset int
+}
+
+type JobListResponse struct {
+	Total int        `json:"total"`
+	Jobs  []jobs.Job `json:"jobs"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"nevermore/internal/model/notification"
+)
+
+type NotificationListRequest struct {
+	UserId     int
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+type NotificationListResponse struct {
+	Total         int                         `json:"total"`
+	Notifications []notification.Notification `json:"notifications"`
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+type UpdatePreferencesRequest struct {
+	Preferences []notification.Preference `json:"preferences"`
+}
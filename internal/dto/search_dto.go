@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"nevermore/internal/model/book"
+)
+
+type SearchRequest struct {
+	Query    string
+	AuthorId *int
+	GenreId  *int
+	Limit    int
+	Offset   int
+}
+
+// SearchHit — найденная книга. TitleHighlight и Snippet — HTML: исходный текст
+// экранирован, совпадения обёрнуты в <mark>
+type SearchHit struct {
+	BookId         int                `db:"book_id" json:"book_id"`
+	Title          string             `db:"title" json:"title"`
+	TitleHighlight string             `db:"title_highlight" json:"title_highlight"`
+	Snippet        string             `db:"snippet" json:"snippet"`
+	CoverImageUrl  *string            `db:"cover_image_url" json:"cover_image_url"`
+	Rank           float64            `db:"rank" json:"rank"`
+	Contributors   []book.Contributor `db:"-" json:"contributors"`
+}
+
+type SearchFacet struct {
+	Id    int    `db:"id" json:"id"`
+	Name  string `db:"name" json:"name"`
+	Count int    `db:"count" json:"count"`
+}
+
+type SearchFacets struct {
+	Authors []SearchFacet `json:"authors"`
+	Genres  []SearchFacet `json:"genres"`
+}
+
+type SearchResponse struct {
+	Total  int          `json:"total"`
+	Hits   []SearchHit  `json:"hits"`
+	Facets SearchFacets `json:"facets"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"nevermore/internal/model/shelf"
+)
+
+type ShelfListRequest struct {
+	UserId   int
+	StatusId *int
+	Limit    int
+	Offset   int
+}
+
+type ShelfListResponse struct {
+	Total int          `json:"total"`
+	Items []shelf.Item `json:"items"`
+}
+
+// ShelfItemRequest меняет только переданные поля, остальные остаются прежними
+type ShelfItemRequest struct {
+	StatusId       *int    `json:"status_id"`
+	Favorite       *bool   `json:"favorite"`
+	PersonalRating *int    `json:"personal_rating"`
+	PersonalNotes  *string `json:"personal_notes"`
+	CurrentPage    *int    `json:"current_page"`
+}
+
+type ShelfImportItem struct {
+	BookId int `json:"book_id" binding:"required"`
+	ShelfItemRequest
+}
+
+type ShelfImportRequest struct {
+	Items []ShelfImportItem `json:"items" binding:"required"`
+}
+
+type ShelfImportSkipped struct {
+	BookId int    `json:"book_id"`
+	Reason string `json:"reason"`
+}
+
+type ShelfImportResponse struct {
+	Imported int                  `json:"imported"`
+	Skipped  []ShelfImportSkipped `json:"skipped"`
+}
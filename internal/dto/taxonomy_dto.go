@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"nevermore/internal/model/series"
+)
+
+type CreateGenreRequest struct {
+	ParentId *int   `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+}
+
+type ModerateTagRequest struct {
+	Status string `json:"status"`
+}
+
+type CreateSeriesRequest struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+}
+
+type SeriesResponse struct {
+	series.Series
+	Books []BookListItem `json:"books"`
+}
@@ -1,9 +1,26 @@
 package dto
 
+import (
+	"time"
+)
+
 type UserGetResponse struct {
 	Name        string  `db:"name" json:"name"`
 	PhoneNumber string  `db:"phone_number" json:"phone_number"`
 	Email       string  `db:"email" json:"email"`
 	Role        string  `db:"role" json:"role"`
 	Photo       *string `db:"photo" json:"photo"`
+	Locale      string  `db:"locale" json:"locale"`
+
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+}
+
+// UserUpdateRequest — поля профиля, которые пользователь меняет сам. Почта, роль
+// и пароль меняются только через свои сценарии с проверками
+type UserUpdateRequest struct {
+	Name        string  `json:"name"`
+	PhoneNumber string  `json:"phone_number"`
+	Photo       *string `json:"photo"`
+	// Locale — язык уведомлений на почту; без него остаётся прежний
+	Locale *string `json:"locale"`
 }
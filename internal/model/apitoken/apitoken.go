@@ -0,0 +1,43 @@
+package apitoken
+
+import (
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Prefix отличает персональные токены от JWT в заголовке Authorization
+const Prefix = "nvm_"
+
+const (
+	ScopeReadShelf  = "read:shelf"
+	ScopeWriteShelf = "write:shelf"
+	// ScopeAdmin открывает модерацию и администрирование, если это позволяет роль владельца
+	ScopeAdmin = "admin:*"
+)
+
+// Scopes перечисляет все области доступа, которые можно выдать токену
+var Scopes = []string{
+	ScopeReadShelf,
+	ScopeWriteShelf,
+	ScopeAdmin,
+}
+
+// Token — персональный токен доступа. Хранится только хэш, сам токен показывается один раз при создании
+type Token struct {
+	Id          int64          `db:"id" json:"id"`
+	UserId      int            `db:"user_id" json:"-"`
+	Name        string         `db:"name" json:"name"`
+	TokenPrefix string         `db:"token_prefix" json:"token_prefix"`
+	TokenHash   string         `db:"token_hash" json:"-"`
+	Scopes      pq.StringArray `db:"scopes" json:"scopes" swaggertype:"array,string"`
+	ExpiresAt   *time.Time     `db:"expires_at" json:"expires_at"`
+	LastUsedAt  *time.Time     `db:"last_used_at" json:"last_used_at"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+}
+
+// HasScope проверяет, выдана ли токену область доступа
+func (t Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
@@ -0,0 +1,33 @@
+package author
+
+import (
+	"time"
+)
+
+const (
+	AliasAlternate       = "alternate"
+	AliasTransliteration = "transliteration"
+	AliasPseudonym       = "pseudonym"
+)
+
+type Author struct {
+	Id        int        `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	Biography *string    `db:"biography" json:"biography"`
+	PhotoUrl  *string    `db:"photo_url" json:"photo_url"`
+	BirthDate *time.Time `db:"birth_date" json:"birth_date"`
+	DeathDate *time.Time `db:"death_date" json:"death_date"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	Aliases   []Alias    `db:"-" json:"aliases"`
+}
+
+// Alias — альтернативное написание имени автора (Лев Толстой / Leo Tolstoy)
+type Alias struct {
+	Id        int       `db:"id" json:"id"`
+	AuthorId  int       `db:"author_id" json:"author_id"`
+	Name      string    `db:"name" json:"name"`
+	Kind      string    `db:"kind" json:"kind"`
+	Locale    *string   `db:"locale" json:"locale"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package book
+
+import (
+	"time"
+)
+
+type Book struct {
+	Id            int           `db:"id" json:"id"`
+	Title         string        `db:"title" json:"title"`
+	Description   *string       `db:"description" json:"description"`
+	CoverImageUrl *string       `db:"cover_image_url" json:"cover_image_url"`
+	FileUrl       string        `db:"file_url" json:"file_url"`
+	UploadedBy    int           `db:"uploaded_by" json:"uploaded_by"`
+	SeriesId      *int          `db:"series_id" json:"series_id"`
+	SeriesVolume  *int          `db:"series_volume" json:"series_volume"`
+	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `db:"updated_at" json:"updated_at"`
+	Contributors  []Contributor `db:"-" json:"contributors"`
+}
@@ -0,0 +1,17 @@
+package book
+
+const (
+	RoleAuthor      = "author"
+	RoleTranslator  = "translator"
+	RoleEditor      = "editor"
+	RoleIllustrator = "illustrator"
+)
+
+// Contributor — участие автора в книге в определённой роли
+type Contributor struct {
+	BookId   int    `db:"book_id" json:"-"`
+	AuthorId int    `db:"author_id" json:"author_id"`
+	Name     string `db:"name" json:"name"`
+	Role     string `db:"role" json:"role"`
+	Position int    `db:"position" json:"position"`
+}
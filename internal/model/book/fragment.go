@@ -0,0 +1,12 @@
+package book
+
+// Fragment — кусок извлечённого текста книги вместе с его положением в файле
+type Fragment struct {
+	Id           int64   `db:"id" json:"id"`
+	BookId       int     `db:"book_id" json:"book_id"`
+	Position     int     `db:"position" json:"position"`
+	ChapterIndex *int    `db:"chapter_index" json:"chapter_index"`
+	Cfi          *string `db:"cfi" json:"cfi"`
+	Page         *int    `db:"page" json:"page"`
+	Content      string  `db:"content" json:"content"`
+}
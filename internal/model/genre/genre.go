@@ -0,0 +1,14 @@
+package genre
+
+import (
+	"time"
+)
+
+type Genre struct {
+	Id        int       `db:"id" json:"id"`
+	ParentId  *int      `db:"parent_id" json:"parent_id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Children  []Genre   `db:"-" json:"children,omitempty"`
+}
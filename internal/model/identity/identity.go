@@ -0,0 +1,26 @@
+package identity
+
+import (
+	"time"
+)
+
+// Identity связывает пользователя с аккаунтом у внешнего OIDC-провайдера
+type Identity struct {
+	Id          int64      `db:"id" json:"id"`
+	UserId      int        `db:"user_id" json:"-"`
+	Provider    string     `db:"provider" json:"provider"`
+	Subject     string     `db:"subject" json:"-"`
+	Email       *string    `db:"email" json:"email"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	LastLoginAt *time.Time `db:"last_login_at" json:"last_login_at"`
+}
+
+// State хранит параметры начатого входа до возврата пользователя от провайдера
+type State struct {
+	StateHash    string    `db:"state_hash"`
+	Provider     string    `db:"provider"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	LinkUserId   *int      `db:"link_user_id"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
@@ -0,0 +1,23 @@
+package loginattempt
+
+import (
+	"time"
+)
+
+// Subject — то, по чему считаются неудачные попытки входа: аккаунт или IP-адрес
+type Subject string
+
+func Account(email string) Subject {
+	return Subject("account:" + email)
+}
+
+func IP(ip string) Subject {
+	return Subject("ip:" + ip)
+}
+
+// State — счётчик неудач за окно и оставшееся время блокировки и паузы перед следующей попыткой
+type State struct {
+	Failures  int
+	LockedFor time.Duration
+	DelayFor  time.Duration
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TypeClubInvite    = "club_invite"
+	TypeReply         = "reply"
+	TypeAuthorNewBook = "author_new_book"
+	TypeGoalMilestone = "goal_milestone"
+)
+
+// Types перечисляет все типы уведомлений, для которых хранятся настройки доставки
+var Types = []string{
+	TypeClubInvite,
+	TypeReply,
+	TypeAuthorNewBook,
+	TypeGoalMilestone,
+}
+
+type Notification struct {
+	Id        int64           `db:"id" json:"id"`
+	UserId    int             `db:"user_id" json:"user_id"`
+	Type      string          `db:"type" json:"type"`
+	Payload   json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	ReadAt    *time.Time      `db:"read_at" json:"read_at"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Preference определяет, куда доставлять уведомления одного типа
+type Preference struct {
+	UserId int    `db:"user_id" json:"-"`
+	Type   string `db:"type" json:"type"`
+	InApp  bool   `db:"in_app" json:"in_app"`
+	Email  bool   `db:"email" json:"email"`
+}
+
+// DefaultPreference — настройки для типа, который пользователь не менял
+func DefaultPreference(userId int, notificationType string) Preference {
+	return Preference{
+		UserId: userId,
+		Type:   notificationType,
+		InApp:  true,
+		Email:  notificationType == TypeClubInvite,
+	}
+}
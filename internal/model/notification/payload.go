@@ -0,0 +1,43 @@
+package notification
+
+// Payload — типизированное содержимое уведомления
+type Payload interface {
+	Type() string
+}
+
+type ClubInvitePayload struct {
+	ClubId      int    `json:"club_id"`
+	ClubName    string `json:"club_name"`
+	InvitedById int    `json:"invited_by_id"`
+	InvitedBy   string `json:"invited_by"`
+}
+
+func (ClubInvitePayload) Type() string { return TypeClubInvite }
+
+type ReplyPayload struct {
+	ThreadId  int    `json:"thread_id"`
+	CommentId int    `json:"comment_id"`
+	AuthorId  int    `json:"author_id"`
+	Author    string `json:"author"`
+	Excerpt   string `json:"excerpt"`
+}
+
+func (ReplyPayload) Type() string { return TypeReply }
+
+type AuthorNewBookPayload struct {
+	BookId     int    `json:"book_id"`
+	Title      string `json:"title"`
+	AuthorId   int    `json:"author_id"`
+	AuthorName string `json:"author_name"`
+}
+
+func (AuthorNewBookPayload) Type() string { return TypeAuthorNewBook }
+
+type GoalMilestonePayload struct {
+	GoalId    int    `json:"goal_id"`
+	Milestone string `json:"milestone"`
+	Progress  int    `json:"progress"`
+	Target    int    `json:"target"`
+}
+
+func (GoalMilestonePayload) Type() string { return TypeGoalMilestone }
@@ -0,0 +1,13 @@
+package series
+
+import (
+	"time"
+)
+
+type Series struct {
+	Id          int       `db:"id" json:"id"`
+	Title       string    `db:"title" json:"title"`
+	Description *string   `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package shelf
+
+import (
+	"time"
+)
+
+// DefaultStatusId — статус "В планах", который получает книга, добавленная без статуса
+const DefaultStatusId = 2
+
+// Item — книга на полке пользователя вместе с его личными отметками
+type Item struct {
+	BookId         int       `db:"book_id" json:"book_id"`
+	Title          string    `db:"title" json:"title"`
+	CoverImageUrl  *string   `db:"cover_image_url" json:"cover_image_url"`
+	StatusId       int       `db:"status_id" json:"status_id"`
+	Status         string    `db:"status" json:"status"`
+	Favorite       bool      `db:"favorite" json:"favorite"`
+	PersonalRating *int      `db:"personal_rating" json:"personal_rating"`
+	PersonalNotes  *string   `db:"personal_notes" json:"personal_notes"`
+	CurrentPage    int       `db:"current_page" json:"current_page"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
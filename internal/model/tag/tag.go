@@ -0,0 +1,21 @@
+package tag
+
+import (
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+type Tag struct {
+	Id          int        `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	Status      string     `db:"status" json:"status"`
+	CreatedBy   *int       `db:"created_by" json:"created_by"`
+	ModeratedBy *int       `db:"moderated_by" json:"moderated_by"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	ModeratedAt *time.Time `db:"moderated_at" json:"moderated_at"`
+}
@@ -0,0 +1,13 @@
+package twofactor
+
+import (
+	"time"
+)
+
+// Policy определяет, обязана ли роль включить двухфакторную аутентификацию
+type Policy struct {
+	Role      string     `db:"role" json:"role"`
+	Required  bool       `db:"required" json:"required"`
+	UpdatedBy *int       `db:"updated_by" json:"updated_by"`
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -4,14 +4,28 @@ import (
 	"time"
 )
 
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+)
+
 type User struct {
-	Id          int        `db:"id" json:"id"`
-	Name        string     `db:"name" json:"name"`
-	PhoneNumber string     `db:"phone_number" json:"phone_number"`
-	Email       string     `db:"email" json:"email"`
-	Role        string     `db:"role" json:"role"`
-	Password    string     `db:"password" json:"password"`
-	Photo       *string    `db:"photo" json:"photo"`
-	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
-	DeletedAt   *time.Time `db:"deleted_at" json:"deleted_at"`
+	Id          int     `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	PhoneNumber string  `db:"phone_number" json:"phone_number"`
+	Email       string  `db:"email" json:"email"`
+	Role        string  `db:"role" json:"role"`
+	Password    string  `db:"password" json:"password"`
+	Photo       *string `db:"photo" json:"photo"`
+	// Locale — язык писем, которые уходят без запроса пользователя
+	Locale string `db:"locale" json:"locale"`
+	// EmailVerifiedAt пуст, пока пользователь не подтвердил почту
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	// TotpSecret задаётся при подключении 2FA, а TotpEnabledAt — после подтверждения кодом
+	TotpSecret    *string    `db:"totp_secret" json:"-"`
+	TotpEnabledAt *time.Time `db:"totp_enabled_at" json:"totp_enabled_at"`
+	TotpLastStep  *int64     `db:"totp_last_step" json:"-"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	DeletedAt     *time.Time `db:"deleted_at" json:"deleted_at"`
 }
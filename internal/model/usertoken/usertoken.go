@@ -0,0 +1,23 @@
+package usertoken
+
+import (
+	"time"
+)
+
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+	PurposeTwoFactor     = "two_factor"
+	PurposeUnlockAccount = "unlock_account"
+)
+
+// Token — одноразовый токен из письма. Хранится только хэш, сам токен уходит пользователю
+type Token struct {
+	Id        int64      `db:"id" json:"id"`
+	UserId    int        `db:"user_id" json:"user_id"`
+	Purpose   string     `db:"purpose" json:"purpose"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
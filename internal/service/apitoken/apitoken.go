@@ -0,0 +1,189 @@
+package apitoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/apitoken"
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/storage"
+	"nevermore/pkg/auth"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	maxNameLength   = 100
+	maxTokensByUser = 20
+
+	defaultExpiresInDays = 90
+	maxExpiresInDays     = 365
+
+	// prefixLength — сколько первых символов токена показываем в списке
+	prefixLength = len(model.Prefix) + 8
+)
+
+var (
+	ErrInvalidToken   = errors.New("name is required and scopes must be read:shelf, write:shelf or admin:*")
+	ErrInvalidExpiry  = errors.New("token must expire within 365 days")
+	ErrScopeForbidden = errors.New("only moderators and admins can create admin:* tokens")
+	ErrTooManyTokens  = errors.New("token limit reached, revoke unused tokens first")
+	ErrTokenNotFound  = errors.New("token not found")
+	ErrUnauthorized   = errors.New("token is invalid or expired")
+)
+
+type Service interface {
+	List(ctx context.Context, userId int) ([]model.Token, error)
+	Create(ctx context.Context, userId int, req dto.CreateAPITokenRequest) (dto.CreateAPITokenResponse, error)
+	Delete(ctx context.Context, userId int, id int64) error
+	// Authenticate находит действующий токен по его значению и отмечает использование
+	Authenticate(ctx context.Context, secret string) (model.Token, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context, userId int) ([]model.Token, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.List")
+	defer span.End()
+
+	tokens, err := s.st.DB().APIToken().List(ctx, userId)
+	if err != nil {
+		return tokens, fmt.Errorf("APITokenService:List err -> %s", err.Error())
+	}
+
+	return tokens, nil
+}
+
+func (s *service) Create(ctx context.Context, userId int, req dto.CreateAPITokenRequest) (dto.CreateAPITokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.Create")
+	defer span.End()
+
+	var result dto.CreateAPITokenResponse
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxNameLength || len(req.Scopes) == 0 {
+		return result, ErrInvalidToken
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.Scopes, scope) {
+			return result, ErrInvalidToken
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxExpiresInDays {
+		return result, ErrInvalidExpiry
+	}
+
+	expiresInDays := req.ExpiresInDays
+	if expiresInDays == 0 {
+		expiresInDays = defaultExpiresInDays
+	}
+
+	// Токен не должен давать больше, чем роль владельца
+	if slices.Contains(scopes, model.ScopeAdmin) {
+		user, err := s.st.DB().User().Get(ctx, userId)
+		if err != nil {
+			return result, fmt.Errorf("APITokenService:Create err -> %s", err.Error())
+		}
+
+		if user.Role != userModel.RoleModerator && user.Role != userModel.RoleAdmin {
+			return result, ErrScopeForbidden
+		}
+	}
+
+	count, err := s.st.DB().APIToken().Count(ctx, userId)
+	if err != nil {
+		return result, fmt.Errorf("APITokenService:Create err -> %s", err.Error())
+	}
+
+	if count >= maxTokensByUser {
+		return result, ErrTooManyTokens
+	}
+
+	random, err := auth.NewOpaqueToken()
+	if err != nil {
+		return result, fmt.Errorf("APITokenService:Create err -> %s", err.Error())
+	}
+
+	secret := model.Prefix + random
+	expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+
+	token := model.Token{
+		UserId:      userId,
+		Name:        name,
+		TokenPrefix: secret[:prefixLength],
+		TokenHash:   auth.HashToken(secret),
+		Scopes:      scopes,
+		ExpiresAt:   &expiresAt,
+	}
+
+	if err := s.st.DB().APIToken().Create(ctx, &token); err != nil {
+		return result, fmt.Errorf("APITokenService:Create err -> %s", err.Error())
+	}
+
+	result.Token = token
+	result.Secret = secret
+
+	return result, nil
+}
+
+func (s *service) Delete(ctx context.Context, userId int, id int64) error {
+	ctx, span := tracing.Start(ctx, "APITokenService.Delete")
+	defer span.End()
+
+	err := s.st.DB().APIToken().Delete(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTokenNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("APITokenService:Delete err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) Authenticate(ctx context.Context, secret string) (model.Token, error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(secret, model.Prefix) {
+		return model.Token{}, ErrUnauthorized
+	}
+
+	token, err := s.st.DB().APIToken().GetActive(ctx, auth.HashToken(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return token, ErrUnauthorized
+	}
+
+	if err != nil {
+		return token, fmt.Errorf("APITokenService:Authenticate err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().APIToken().Touch(ctx, token.Id); err != nil {
+		return token, fmt.Errorf("APITokenService:Authenticate err -> %s", err.Error())
+	}
+
+	return token, nil
+}
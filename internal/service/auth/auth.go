@@ -0,0 +1,669 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"nevermore/internal/dto"
+	userModel "nevermore/internal/model/user"
+	model "nevermore/internal/model/usertoken"
+	"nevermore/internal/service/loginguard"
+	mailService "nevermore/internal/service/mail"
+	"nevermore/internal/service/twofactor"
+	userService "nevermore/internal/service/user"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/auth"
+	"nevermore/pkg/hash"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	minPasswordLength = 8
+
+	defaultAccessTokenTTL   = 24 * time.Hour
+	defaultVerifyEmailTTL   = 48 * time.Hour
+	defaultResetPasswordTTL = time.Hour
+	defaultResendCooldown   = time.Minute
+	defaultUnlockAccountTTL = time.Hour
+
+	// twoFactorChallengeTTL — сколько ждём код из приложения после ввода пароля
+	twoFactorChallengeTTL = 5 * time.Minute
+)
+
+// LinkJob — задача письма со ссылкой подтверждения, сброса или разблокировки. В очереди
+// лежит только ссылка на пользователя: одноразовый токен выпускается при отправке,
+// поэтому в таблицу jobs и в админку он не попадает
+const LinkJob = "auth.link"
+
+type linkPayload struct {
+	UserId  int    `json:"user_id"`
+	Purpose string `json:"purpose"`
+	Locale  string `json:"locale"`
+}
+
+var (
+	ErrInvalidSignUp      = errors.New("name is required and email must be valid")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters and at most 72 bytes long")
+	ErrUserExists         = errors.New("user with this name or email already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("token is invalid, expired or already used")
+	ErrAlreadyVerified    = errors.New("email is already verified")
+	ErrTooManyRequests    = errors.New("too many requests, try again later")
+)
+
+type Config struct {
+	// AppURL — адрес фронтенда, на который ведут ссылки из писем
+	AppURL           string
+	AccessTokenTTL   time.Duration
+	VerifyEmailTTL   time.Duration
+	ResetPasswordTTL time.Duration
+	UnlockAccountTTL time.Duration
+	// ResendCooldown — минимальный интервал между письмами одного назначения одному пользователю
+	ResendCooldown time.Duration
+	// TOTPIssuer — название сервиса в приложении-аутентификаторе
+	TOTPIssuer string
+}
+
+type Service interface {
+	SignUp(ctx context.Context, req dto.SignUpRequest, locale string) (dto.AuthResponse, error)
+	// SignIn при включённой 2FA вместо токена доступа возвращает токен второго шага.
+	// Неудачи считаются по аккаунту и по IP, при блокировке владельцу уходит письмо для разблокировки
+	SignIn(ctx context.Context, req dto.SignInRequest, ip, locale string) (dto.AuthResponse, error)
+	SignInTwoFactor(ctx context.Context, req dto.SignInTwoFactorRequest, ip, locale string) (dto.AuthResponse, error)
+	UnlockAccount(ctx context.Context, token string) error
+	// CompleteSignIn завершает вход пользователя, уже подтверждённого другим способом, например через OIDC
+	CompleteSignIn(ctx context.Context, userId int) (dto.AuthResponse, error)
+	// ParseToken проверяет токен доступа и возвращает id пользователя
+	ParseToken(token string) (string, error)
+
+	SendVerification(ctx context.Context, userId int, locale string) error
+	VerifyEmail(ctx context.Context, token string) error
+	IsEmailVerified(ctx context.Context, userId int) (bool, error)
+
+	ForgotPassword(ctx context.Context, email, locale string) error
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+type service struct {
+	st        storage.Storage
+	hasher    hash.PasswordHasher
+	tokens    auth.TokenManager
+	mail      mailService.Service
+	twoFactor twofactor.Service
+	guard     loginguard.Service
+	links     jobs.Type[linkPayload]
+	cfg       Config
+}
+
+func New(st storage.Storage,
+	hasher hash.PasswordHasher,
+	tokens auth.TokenManager,
+	mail mailService.Service,
+	twoFactor twofactor.Service,
+	guard loginguard.Service,
+	queue *jobs.Queue,
+	cfg Config) Service {
+
+	if cfg.AccessTokenTTL == 0 {
+		cfg.AccessTokenTTL = defaultAccessTokenTTL
+	}
+
+	if cfg.VerifyEmailTTL == 0 {
+		cfg.VerifyEmailTTL = defaultVerifyEmailTTL
+	}
+
+	if cfg.ResetPasswordTTL == 0 {
+		cfg.ResetPasswordTTL = defaultResetPasswordTTL
+	}
+
+	if cfg.UnlockAccountTTL == 0 {
+		cfg.UnlockAccountTTL = defaultUnlockAccountTTL
+	}
+
+	if cfg.ResendCooldown == 0 {
+		cfg.ResendCooldown = defaultResendCooldown
+	}
+
+	result := &service{
+		st:        st,
+		hasher:    hasher,
+		tokens:    tokens,
+		mail:      mail,
+		twoFactor: twoFactor,
+		guard:     guard,
+		cfg:       cfg,
+	}
+
+	result.links = jobs.Register(queue, jobs.Definition{
+		Type:  LinkJob,
+		Queue: mailer.JobQueue,
+	}, result.sendLink)
+
+	return result
+}
+
+func (s *service) SignUp(ctx context.Context, req dto.SignUpRequest, locale string) (dto.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SignUp")
+	defer span.End()
+
+	var result dto.AuthResponse
+
+	name := strings.TrimSpace(req.Name)
+	address, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if name == "" || err != nil {
+		return result, ErrInvalidSignUp
+	}
+
+	if len(req.Password) < minPasswordLength || len(req.Password) > hash.MaxPasswordLength {
+		return result, ErrWeakPassword
+	}
+
+	password, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignUp err -> %s", err.Error())
+	}
+
+	user := userModel.User{
+		Name:      name,
+		Email:     strings.ToLower(address.Address),
+		Password:  password,
+		Role:      userModel.RoleUser,
+		Locale:    mailService.NormalizeLocale(locale),
+		CreatedAt: time.Now(),
+	}
+
+	err = s.st.DB().User().Create(ctx, &user)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return result, ErrUserExists
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignUp err -> %s", err.Error())
+	}
+
+	if err := s.enqueueLink(ctx, user.Id, model.PurposeVerifyEmail, locale); err != nil {
+		return result, fmt.Errorf("AuthService:SignUp err -> %s", err.Error())
+	}
+
+	metrics.SignUps.WithLabelValues(metrics.MethodPassword).Inc()
+
+	return s.accessToken(user.Id, metrics.MethodPassword)
+}
+
+func (s *service) SignIn(ctx context.Context, req dto.SignInRequest, ip, locale string) (dto.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SignIn")
+	defer span.End()
+
+	var result dto.AuthResponse
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	if err := s.guard.Check(ctx, email, ip); err != nil {
+		return result, err
+	}
+
+	// Неудача по несуществующей почте считается так же, чтобы блокировка не выдавала, есть ли аккаунт
+	user, err := s.st.DB().User().GetByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.guard.Fail(ctx, email, ip)
+		return result, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignIn err -> %s", err.Error())
+	}
+
+	// Пустой пароль у аккаунтов, созданных через внешнего провайдера
+	ok, rehash := s.hasher.Verify(req.Password, user.Password)
+	if user.Password == "" || !ok {
+		if err := s.fail(ctx, user, ip, locale); err != nil {
+			return result, fmt.Errorf("AuthService:SignIn err -> %s", err.Error())
+		}
+
+		return result, ErrInvalidCredentials
+	}
+
+	s.guard.Succeed(ctx, email, ip)
+
+	if rehash {
+		s.rehash(ctx, user.Id, req.Password)
+	}
+
+	return s.completeSignIn(ctx, user, metrics.MethodPassword)
+}
+
+func (s *service) CompleteSignIn(ctx context.Context, userId int) (dto.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CompleteSignIn")
+	defer span.End()
+
+	// Провайдер мог остаться привязан к удалённому аккаунту: как и токен такого
+	// пользователя, вход отклоняется с 401, а не 500
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dto.AuthResponse{}, userService.ErrUserNotFound
+	}
+
+	if err != nil {
+		return dto.AuthResponse{}, fmt.Errorf("AuthService:CompleteSignIn err -> %s", err.Error())
+	}
+
+	return s.completeSignIn(ctx, user, metrics.MethodOIDC)
+}
+
+// completeSignIn выдаёт токен доступа или, если включена 2FA, токен второго шага.
+// method — способ входа для метрик
+func (s *service) completeSignIn(ctx context.Context, user userModel.User, method string) (dto.AuthResponse, error) {
+	var result dto.AuthResponse
+
+	if user.TotpEnabledAt == nil {
+		return s.accessToken(user.Id, method)
+	}
+
+	challenge, err := auth.NewOpaqueToken()
+	if err != nil {
+		return result, fmt.Errorf("AuthService:completeSignIn err -> %s", err.Error())
+	}
+
+	token := model.Token{
+		UserId:    user.Id,
+		Purpose:   model.PurposeTwoFactor,
+		TokenHash: auth.HashToken(challenge),
+		ExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	}
+
+	if err := s.st.DB().UserToken().Create(ctx, &token); err != nil {
+		return result, fmt.Errorf("AuthService:completeSignIn err -> %s", err.Error())
+	}
+
+	result.TwoFactorRequired = true
+	result.ChallengeToken = challenge
+
+	return result, nil
+}
+
+// SignInTwoFactor завершает вход кодом из приложения или кодом восстановления.
+// При неверном коде транзакция откатывается и токен второго шага остаётся действительным,
+// поэтому неверные коды считаются неудачами входа так же, как неверные пароли
+func (s *service) SignInTwoFactor(ctx context.Context, req dto.SignInTwoFactorRequest, ip, locale string) (dto.AuthResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.SignInTwoFactor")
+	defer span.End()
+
+	var result dto.AuthResponse
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignInTwoFactor err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	userId, err := s.st.DB().UserToken().ConsumeTx(ctx, tx, auth.HashToken(req.ChallengeToken), model.PurposeTwoFactor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, ErrInvalidToken
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignInTwoFactor err -> %s", err.Error())
+	}
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return result, fmt.Errorf("AuthService:SignInTwoFactor err -> %s", err.Error())
+	}
+
+	if err := s.guard.Check(ctx, user.Email, ip); err != nil {
+		return result, err
+	}
+
+	err = s.twoFactor.Check(ctx, userId, req.Code)
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		if err := s.fail(ctx, user, ip, locale); err != nil {
+			return result, fmt.Errorf("AuthService:SignInTwoFactor err -> %s", err.Error())
+		}
+
+		return result, err
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("AuthService:SignInTwoFactor err -> %s", err.Error())
+	}
+
+	s.guard.Succeed(ctx, user.Email, ip)
+
+	return s.accessToken(userId, metrics.MethodTwoFactor)
+}
+
+// UnlockAccount снимает блокировку входа по ссылке из письма
+func (s *service) UnlockAccount(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.UnlockAccount")
+	defer span.End()
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("AuthService:UnlockAccount err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	userId, err := s.st.DB().UserToken().ConsumeTx(ctx, tx, auth.HashToken(token), model.PurposeUnlockAccount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthService:UnlockAccount err -> %s", err.Error())
+	}
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("AuthService:UnlockAccount err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().UserToken().RevokeTx(ctx, tx, userId, model.PurposeUnlockAccount); err != nil {
+		return fmt.Errorf("AuthService:UnlockAccount err -> %s", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AuthService:UnlockAccount err -> %s", err.Error())
+	}
+
+	s.guard.Unlock(ctx, user.Email)
+
+	return nil
+}
+
+// fail учитывает неудачный вход и, если аккаунт только что заблокирован, отправляет письмо для разблокировки
+func (s *service) fail(ctx context.Context, user userModel.User, ip, locale string) error {
+	if !s.guard.Fail(ctx, user.Email, ip) {
+		return nil
+	}
+
+	return s.enqueueLink(ctx, user.Id, model.PurposeUnlockAccount, locale)
+}
+
+func (s *service) ParseToken(token string) (string, error) {
+	return s.tokens.Parse(token)
+}
+
+func (s *service) SendVerification(ctx context.Context, userId int, locale string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.SendVerification")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("AuthService:SendVerification err -> %s", err.Error())
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	allowed, err := s.cooledDown(ctx, userId, model.PurposeVerifyEmail)
+	if err != nil {
+		return fmt.Errorf("AuthService:SendVerification err -> %s", err.Error())
+	}
+
+	if !allowed {
+		return ErrTooManyRequests
+	}
+
+	if err := s.enqueueLink(ctx, user.Id, model.PurposeVerifyEmail, locale); err != nil {
+		return fmt.Errorf("AuthService:SendVerification err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyEmail")
+	defer span.End()
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("AuthService:VerifyEmail err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	userId, err := s.st.DB().UserToken().ConsumeTx(ctx, tx, auth.HashToken(token), model.PurposeVerifyEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthService:VerifyEmail err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().User().MarkEmailVerifiedTx(ctx, tx, userId); err != nil {
+		return fmt.Errorf("AuthService:VerifyEmail err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().UserToken().RevokeTx(ctx, tx, userId, model.PurposeVerifyEmail); err != nil {
+		return fmt.Errorf("AuthService:VerifyEmail err -> %s", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AuthService:VerifyEmail err -> %s", err.Error())
+	}
+
+	cache.Invalidate(ctx, s.st.Cache(), cache.UserProfile(userId))
+
+	return nil
+}
+
+func (s *service) IsEmailVerified(ctx context.Context, userId int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IsEmailVerified")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("AuthService:IsEmailVerified err -> %s", err.Error())
+	}
+
+	return user.EmailVerifiedAt != nil, nil
+}
+
+// ForgotPassword не сообщает, есть ли аккаунт с такой почтой,
+// чтобы по ответу нельзя было перебирать адреса пользователей
+func (s *service) ForgotPassword(ctx context.Context, email, locale string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ForgotPassword")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthService:ForgotPassword err -> %s", err.Error())
+	}
+
+	allowed, err := s.cooledDown(ctx, user.Id, model.PurposeResetPassword)
+	if err != nil {
+		return fmt.Errorf("AuthService:ForgotPassword err -> %s", err.Error())
+	}
+
+	if !allowed {
+		return nil
+	}
+
+	if err := s.enqueueLink(ctx, user.Id, model.PurposeResetPassword, locale); err != nil {
+		return fmt.Errorf("AuthService:ForgotPassword err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, token, password string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	if len(password) < minPasswordLength || len(password) > hash.MaxPasswordLength {
+		return ErrWeakPassword
+	}
+
+	hashed, err := s.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	userId, err := s.st.DB().UserToken().ConsumeTx(ctx, tx, auth.HashToken(token), model.PurposeResetPassword)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidToken
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().User().UpdatePasswordTx(ctx, tx, userId, hashed); err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	// Ссылка из письма подтверждает владение почтой
+	if err := s.st.DB().User().MarkEmailVerifiedTx(ctx, tx, userId); err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().UserToken().RevokeTx(ctx, tx, userId, model.PurposeResetPassword); err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AuthService:ResetPassword err -> %s", err.Error())
+	}
+
+	cache.Invalidate(ctx, s.st.Cache(), cache.UserProfile(userId))
+
+	// Сброс по ссылке из письма доказывает владение аккаунтом, так что блокировка входа снимается
+	s.guard.Unlock(ctx, user.Email)
+
+	return nil
+}
+
+// rehash пересчитывает пароль, сохранённый устаревшим способом. Ошибка не мешает входу:
+// пароль пересчитается при следующем
+func (s *service) rehash(ctx context.Context, userId int, password string) {
+	log := logger.NamedFromContext(ctx, "auth")
+
+	hashed, err := s.hasher.Hash(password)
+	if err == nil {
+		err = s.st.DB().User().UpdatePassword(ctx, userId, hashed)
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Int("user_id", userId).Msg("failed to rehash password")
+	}
+}
+
+func (s *service) enqueueLink(ctx context.Context, userId int, purpose, locale string) error {
+	_, err := s.links.Enqueue(ctx, linkPayload{UserId: userId, Purpose: purpose, Locale: locale})
+
+	return err
+}
+
+// sendLink выполняет задачу LinkJob. Каждая попытка выпускает новый токен: невыданные
+// остаются только хэшем в базе и истекают сами
+func (s *service) sendLink(ctx context.Context, payload linkPayload) error {
+	user, err := s.st.DB().User().GetById(ctx, payload.UserId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if payload.Purpose == model.PurposeVerifyEmail && user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.issue(ctx, user, payload.Purpose, payload.Locale)
+}
+
+// issue сохраняет хэш нового токена и отправляет ссылку с ним на почту пользователя
+func (s *service) issue(ctx context.Context, user userModel.User, purpose, locale string) error {
+	raw, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := s.cfg.VerifyEmailTTL
+	template := mailService.TemplateVerifyEmail
+	path := "verify-email"
+
+	switch purpose {
+	case model.PurposeResetPassword:
+		ttl = s.cfg.ResetPasswordTTL
+		template = mailService.TemplateResetPassword
+		path = "reset-password"
+	case model.PurposeUnlockAccount:
+		ttl = s.cfg.UnlockAccountTTL
+		template = mailService.TemplateUnlockAccount
+		path = "unlock-account"
+	}
+
+	token := model.Token{
+		UserId:    user.Id,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.st.DB().UserToken().Create(ctx, &token); err != nil {
+		return err
+	}
+
+	data := mailService.LinkData{
+		Name:         user.Name,
+		Link:         fmt.Sprintf("%s/%s?token=%s", strings.TrimRight(s.cfg.AppURL, "/"), path, raw),
+		ExpiresHours: int(max(ttl.Hours(), 1)),
+	}
+
+	return s.mail.Deliver(ctx, user.Email, locale, template, data)
+}
+
+// cooledDown отмечает запрос письма и сообщает, прошёл ли ResendCooldown с прошлого.
+// Отметка ставится до постановки в очередь: токен выпустит воркер, и пока он не дошёл
+// до задачи, по токенам повторный запрос не отличить от первого
+func (s *service) cooledDown(ctx context.Context, userId int, purpose string) (bool, error) {
+	return s.st.DB().UserToken().Reserve(ctx, userId, purpose, s.cfg.ResendCooldown)
+}
+
+func (s *service) accessToken(userId int, method string) (dto.AuthResponse, error) {
+	token, err := s.tokens.NewJWT(strconv.Itoa(userId), s.cfg.AccessTokenTTL)
+	if err != nil {
+		return dto.AuthResponse{}, fmt.Errorf("AuthService:accessToken err -> %s", err.Error())
+	}
+
+	metrics.SessionsStarted.WithLabelValues(method).Inc()
+
+	return dto.AuthResponse{Token: token}, nil
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	userModel "nevermore/internal/model/user"
+	model "nevermore/internal/model/usertoken"
+	userService "nevermore/internal/service/user"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/postgres"
+	userRepo "nevermore/internal/storage/postgres/user"
+	userTokenRepo "nevermore/internal/storage/postgres/usertoken"
+	"nevermore/pkg/jobs"
+)
+
+// Фейки встраивают интерфейсы и переопределяют только то, что нужно запросу письма
+
+type fakeStorage struct {
+	storage.Storage
+	db *fakeDB
+}
+
+func (s *fakeStorage) DB() postgres.Repo { return s.db }
+
+type fakeDB struct {
+	postgres.Repo
+	users      *fakeUsers
+	userTokens *fakeUserTokens
+}
+
+func (d *fakeDB) User() userRepo.Repo           { return d.users }
+func (d *fakeDB) UserToken() userTokenRepo.Repo { return d.userTokens }
+
+// fakeUsers отдаёт err вместо пользователя, если он задан, например sql.ErrNoRows для удалённого
+type fakeUsers struct {
+	userRepo.Repo
+	user userModel.User
+	err  error
+}
+
+func (u *fakeUsers) GetById(context.Context, int) (userModel.User, error) {
+	return u.user, u.err
+}
+
+func (u *fakeUsers) GetByEmail(context.Context, string) (userModel.User, error) {
+	return u.user, nil
+}
+
+// fakeUserTokens повторяет условие upsert из Reserve
+type fakeUserTokens struct {
+	userTokenRepo.Repo
+	requested map[string]time.Time
+}
+
+func (f *fakeUserTokens) Reserve(_ context.Context, _ int, purpose string, cooldown time.Duration) (bool, error) {
+	now := time.Now()
+	if last, ok := f.requested[purpose]; ok && now.Sub(last) < cooldown {
+		return false, nil
+	}
+
+	f.requested[purpose] = now
+
+	return true, nil
+}
+
+// fakeJobs только копит задачи: воркер в тестах не запускается, токены не выпускаются
+type fakeJobs struct {
+	inserted []jobs.Job
+}
+
+func (f *fakeJobs) Insert(_ context.Context, job jobs.Job) (jobs.Job, error) {
+	f.inserted = append(f.inserted, job)
+	return job, nil
+}
+
+func (f *fakeJobs) Claim(context.Context, string, int, time.Duration) ([]jobs.Job, error) {
+	return nil, nil
+}
+
+func (f *fakeJobs) Complete(context.Context, jobs.Job) error { return nil }
+
+func (f *fakeJobs) Reschedule(context.Context, jobs.Job, time.Time, string) error { return nil }
+
+func (f *fakeJobs) Bury(context.Context, jobs.Job, string) error { return nil }
+
+func (f *fakeJobs) Counts(context.Context) ([]jobs.Count, error) { return nil, nil }
+
+func newTestService(user userModel.User) (Service, *fakeJobs) {
+	st := &fakeStorage{db: &fakeDB{
+		users:      &fakeUsers{user: user},
+		userTokens: &fakeUserTokens{requested: make(map[string]time.Time)},
+	}}
+
+	store := &fakeJobs{}
+	s := New(st, nil, nil, nil, nil, nil, jobs.NewQueue(store, jobs.Config{}), Config{ResendCooldown: time.Minute})
+
+	return s, store
+}
+
+func TestSendVerificationCooldownBeforeWorkerRuns(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(userModel.User{Id: 1, Email: "reader@example.com"})
+
+	if err := s.SendVerification(ctx, 1, "en"); err != nil {
+		t.Fatalf("first SendVerification = %v, want nil", err)
+	}
+
+	if err := s.SendVerification(ctx, 1, "en"); !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("second SendVerification = %v, want ErrTooManyRequests", err)
+	}
+
+	if len(store.inserted) != 1 {
+		t.Fatalf("enqueued %d link jobs, want 1", len(store.inserted))
+	}
+}
+
+func TestForgotPasswordCooldownBeforeWorkerRuns(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s, store := newTestService(userModel.User{Id: 1, Email: "reader@example.com", EmailVerifiedAt: &now})
+
+	// Ответ одинаковый, чтобы по нему нельзя было судить об аккаунте, но письмо уходит одно
+	for i := 0; i < 2; i++ {
+		if err := s.ForgotPassword(ctx, "reader@example.com", "en"); err != nil {
+			t.Fatalf("ForgotPassword #%d = %v, want nil", i+1, err)
+		}
+	}
+
+	if len(store.inserted) != 1 {
+		t.Fatalf("enqueued %d link jobs, want 1", len(store.inserted))
+	}
+}
+
+// Подтверждение почты и сброс пароля ограничиваются независимо
+func TestCooldownIsPerPurpose(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestService(userModel.User{Id: 1, Email: "reader@example.com"})
+
+	if err := s.SendVerification(ctx, 1, "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ForgotPassword(ctx, "reader@example.com", "en"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.inserted) != 2 {
+		t.Fatalf("enqueued %d link jobs, want 2", len(store.inserted))
+	}
+
+	purposes := []string{model.PurposeVerifyEmail, model.PurposeResetPassword}
+	for i, job := range store.inserted {
+		if job.Type != LinkJob {
+			t.Errorf("job %d type = %s, want %s", i, job.Type, LinkJob)
+		}
+
+		if want := `"purpose":"` + purposes[i] + `"`; !strings.Contains(string(job.Payload), want) {
+			t.Errorf("job %d payload = %s, want %s", i, job.Payload, want)
+		}
+	}
+}
+
+// Вход через провайдера, привязанного к удалённому аккаунту, — это 401, а не ошибка сервера
+func TestCompleteSignInDeletedUser(t *testing.T) {
+	st := &fakeStorage{db: &fakeDB{users: &fakeUsers{err: sql.ErrNoRows}}}
+	s := New(st, nil, nil, nil, nil, nil, jobs.NewQueue(&fakeJobs{}, jobs.Config{}), Config{})
+
+	if _, err := s.CompleteSignIn(context.Background(), 1); !errors.Is(err, userService.ErrUserNotFound) {
+		t.Fatalf("CompleteSignIn err = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,371 @@
+package author
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/author"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/tracing"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	dateLayout   = time.DateOnly
+)
+
+var (
+	ErrInvalidAuthor  = errors.New("author name is required and dates must be in YYYY-MM-DD format")
+	ErrInvalidAlias   = errors.New("alias name is required and kind must be alternate, transliteration or pseudonym")
+	ErrAuthorNotFound = errors.New("author not found")
+	ErrAliasNotFound  = errors.New("alias not found")
+	ErrAuthorHasBooks = errors.New("author has books, merge it into another author instead")
+	ErrSelfMerge      = errors.New("cannot merge author into itself")
+)
+
+type Service interface {
+	List(ctx context.Context, limit, offset int) ([]model.Author, error)
+	Get(ctx context.Context, id int) (model.Author, error)
+	Create(ctx context.Context, req dto.AuthorRequest) (model.Author, error)
+	Update(ctx context.Context, id int, req dto.AuthorRequest) (model.Author, error)
+	Delete(ctx context.Context, id int) error
+	AddAlias(ctx context.Context, authorId int, req dto.AuthorAliasRequest) (model.Alias, error)
+	DeleteAlias(ctx context.Context, authorId, aliasId int) error
+	Merge(ctx context.Context, targetId, sourceId int) (model.Author, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context, limit, offset int) ([]model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.List")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	authors, err := s.st.DB().Author().List(ctx, limit, offset)
+	if err != nil {
+		return authors, fmt.Errorf("AuthorService:List err -> %s", err.Error())
+	}
+
+	return authors, nil
+}
+
+func (s *service) Get(ctx context.Context, id int) (model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.Get")
+	defer span.End()
+
+	author, err := s.st.DB().Author().Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return author, ErrAuthorNotFound
+	}
+
+	if err != nil {
+		return author, fmt.Errorf("AuthorService:Get err -> %s", err.Error())
+	}
+
+	author.Aliases, err = s.st.DB().Author().Aliases(ctx, id)
+	if err != nil {
+		return author, fmt.Errorf("AuthorService:Get err -> %s", err.Error())
+	}
+
+	return author, nil
+}
+
+func (s *service) Create(ctx context.Context, req dto.AuthorRequest) (model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.Create")
+	defer span.End()
+
+	author, err := authorFromRequest(req)
+	if err != nil {
+		return author, err
+	}
+
+	if err := s.st.DB().Author().Create(ctx, &author); err != nil {
+		return author, fmt.Errorf("AuthorService:Create err -> %s", err.Error())
+	}
+
+	author.Aliases = make([]model.Alias, 0)
+
+	return author, nil
+}
+
+func (s *service) Update(ctx context.Context, id int, req dto.AuthorRequest) (model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.Update")
+	defer span.End()
+
+	author, err := authorFromRequest(req)
+	if err != nil {
+		return author, err
+	}
+
+	author.Id = id
+
+	err = s.st.DB().Author().Update(ctx, author)
+	if errors.Is(err, sql.ErrNoRows) {
+		return author, ErrAuthorNotFound
+	}
+
+	if err != nil {
+		return author, fmt.Errorf("AuthorService:Update err -> %s", err.Error())
+	}
+
+	s.invalidateBooks(ctx, id)
+
+	return s.Get(ctx, id)
+}
+
+// Delete удаляет только авторов без книг, иначе книги потеряли бы участников
+func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "AuthorService.Delete")
+	defer span.End()
+
+	hasBooks, err := s.st.DB().Author().HasBooks(ctx, id)
+	if err != nil {
+		return fmt.Errorf("AuthorService:Delete err -> %s", err.Error())
+	}
+
+	if hasBooks {
+		return ErrAuthorHasBooks
+	}
+
+	err = s.st.DB().Author().Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAuthorNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthorService:Delete err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) AddAlias(ctx context.Context, authorId int, req dto.AuthorAliasRequest) (model.Alias, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.AddAlias")
+	defer span.End()
+
+	alias := model.Alias{
+		AuthorId: authorId,
+		Name:     strings.TrimSpace(req.Name),
+		Kind:     req.Kind,
+		Locale:   req.Locale,
+	}
+
+	if alias.Kind == "" {
+		alias.Kind = model.AliasAlternate
+	}
+
+	validKind := alias.Kind == model.AliasAlternate ||
+		alias.Kind == model.AliasTransliteration ||
+		alias.Kind == model.AliasPseudonym
+
+	if alias.Name == "" || !validKind {
+		return alias, ErrInvalidAlias
+	}
+
+	if _, err := s.Get(ctx, authorId); err != nil {
+		return alias, err
+	}
+
+	if err := s.st.DB().Author().AddAlias(ctx, &alias); err != nil {
+		return alias, fmt.Errorf("AuthorService:AddAlias err -> %s", err.Error())
+	}
+
+	return alias, nil
+}
+
+func (s *service) DeleteAlias(ctx context.Context, authorId, aliasId int) error {
+	ctx, span := tracing.Start(ctx, "AuthorService.DeleteAlias")
+	defer span.End()
+
+	err := s.st.DB().Author().DeleteAlias(ctx, authorId, aliasId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAliasNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("AuthorService:DeleteAlias err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+// Merge сливает дубликат sourceId в targetId: книги, подписчики и псевдонимы
+// переходят к целевому автору, а имя дубликата сохраняется как псевдоним
+func (s *service) Merge(ctx context.Context, targetId, sourceId int) (model.Author, error) {
+	ctx, span := tracing.Start(ctx, "AuthorService.Merge")
+	defer span.End()
+
+	if targetId == sourceId {
+		return model.Author{}, ErrSelfMerge
+	}
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	repo := s.st.DB().Author()
+
+	// Блокируем обоих авторов в порядке id, чтобы встречные слияния не упирались в дедлок
+	lockOrder := []int{targetId, sourceId}
+	if sourceId < targetId {
+		lockOrder = []int{sourceId, targetId}
+	}
+
+	locked := make(map[int]model.Author, len(lockOrder))
+	for _, id := range lockOrder {
+		author, err := repo.LockTx(ctx, tx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Author{}, ErrAuthorNotFound
+		}
+
+		if err != nil {
+			return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+		}
+
+		locked[id] = author
+	}
+
+	birthDate, deathDate := lifeDates(locked[targetId], locked[sourceId])
+
+	if err := repo.FillMissingTx(ctx, tx, targetId, sourceId, birthDate, deathDate); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	if err := repo.ReassignBooksTx(ctx, tx, sourceId, targetId); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	if err := repo.ReassignFollowersTx(ctx, tx, sourceId, targetId); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	if err := repo.ReassignAliasesTx(ctx, tx, sourceId, targetId); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	if !strings.EqualFold(locked[sourceId].Name, locked[targetId].Name) {
+		alias := model.Alias{
+			AuthorId: targetId,
+			Name:     locked[sourceId].Name,
+			Kind:     model.AliasAlternate,
+		}
+
+		if err := repo.AddAliasTx(ctx, tx, alias); err != nil {
+			return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+		}
+	}
+
+	if err := repo.DeleteTx(ctx, tx, sourceId); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Author{}, fmt.Errorf("AuthorService:Merge err -> %s", err.Error())
+	}
+
+	// Книги дубликата теперь принадлежат целевому автору, поэтому сбрасываем все его карточки
+	s.invalidateBooks(ctx, targetId)
+
+	return s.Get(ctx, targetId)
+}
+
+// lifeDates выбирает даты жизни для объединённого автора целой парой: у целевого, если у него
+// есть хоть одна дата, иначе у дубликата. Даты разных авторов вперемешку могли бы дать
+// смерть раньше рождения и нарушить authors_life_dates_check
+func lifeDates(target, source model.Author) (*time.Time, *time.Time) {
+	if target.BirthDate != nil || target.DeathDate != nil {
+		return target.BirthDate, target.DeathDate
+	}
+
+	return source.BirthDate, source.DeathDate
+}
+
+// invalidateBooks сбрасывает кэш карточек книг, где автор указан среди участников
+func (s *service) invalidateBooks(ctx context.Context, authorId int) {
+	bookIds, err := s.st.DB().Contributor().BookIds(ctx, authorId)
+	if err != nil {
+		log := logger.NamedFromContext(ctx, "cache")
+		log.Error().Err(err).Int("author_id", authorId).Msg("failed to load author books for invalidation")
+		return
+	}
+
+	keys := make([]string, 0, len(bookIds))
+	for _, bookId := range bookIds {
+		keys = append(keys, cache.Book(bookId))
+	}
+
+	if len(keys) > 0 {
+		cache.Invalidate(ctx, s.st.Cache(), keys...)
+	}
+}
+
+func authorFromRequest(req dto.AuthorRequest) (model.Author, error) {
+	author := model.Author{
+		Name:      strings.TrimSpace(req.Name),
+		Biography: req.Biography,
+		PhotoUrl:  req.PhotoUrl,
+	}
+
+	if author.Name == "" {
+		return author, ErrInvalidAuthor
+	}
+
+	var err error
+
+	if author.BirthDate, err = parseDate(req.BirthDate); err != nil {
+		return author, ErrInvalidAuthor
+	}
+
+	if author.DeathDate, err = parseDate(req.DeathDate); err != nil {
+		return author, ErrInvalidAuthor
+	}
+
+	if author.BirthDate != nil && author.DeathDate != nil && author.DeathDate.Before(*author.BirthDate) {
+		return author, ErrInvalidAuthor
+	}
+
+	return author, nil
+}
+
+func parseDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(dateLayout, *value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
@@ -0,0 +1,72 @@
+package author
+
+import (
+	"testing"
+	"time"
+
+	model "nevermore/internal/model/author"
+)
+
+func date(year int) *time.Time {
+	t := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestLifeDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    model.Author
+		source    model.Author
+		wantBirth *time.Time
+		wantDeath *time.Time
+	}{
+		{
+			name:      "target without dates takes the source pair",
+			source:    model.Author{BirthDate: date(1828), DeathDate: date(1910)},
+			wantBirth: date(1828),
+			wantDeath: date(1910),
+		},
+		{
+			name:      "target pair wins",
+			target:    model.Author{BirthDate: date(1828), DeathDate: date(1910)},
+			source:    model.Author{BirthDate: date(1821), DeathDate: date(1881)},
+			wantBirth: date(1828),
+			wantDeath: date(1910),
+		},
+		{
+			// Смешав даты, получили бы рождение в 1900 и смерть в 1860
+			name:      "conflicting dates are not mixed",
+			target:    model.Author{BirthDate: date(1900)},
+			source:    model.Author{BirthDate: date(1800), DeathDate: date(1860)},
+			wantBirth: date(1900),
+		},
+		{
+			name:      "target death date alone keeps target pair",
+			target:    model.Author{DeathDate: date(1800)},
+			source:    model.Author{BirthDate: date(1850)},
+			wantDeath: date(1800),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			birth, death := lifeDates(tt.target, tt.source)
+
+			if !sameDate(birth, tt.wantBirth) || !sameDate(death, tt.wantDeath) {
+				t.Fatalf("lifeDates = %v, %v, want %v, %v", birth, death, tt.wantBirth, tt.wantDeath)
+			}
+
+			if birth != nil && death != nil && death.Before(*birth) {
+				t.Fatalf("death %v is before birth %v", death, birth)
+			}
+		})
+	}
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
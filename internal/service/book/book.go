@@ -0,0 +1,194 @@
+package book
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/book"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/internal/storage/postgres/contributor"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	// Карточка книги и рейтинг инвалидируются при изменениях, TTL лишь страхует от пропущенной инвалидации
+	bookTTL   = 10 * time.Minute
+	ratingTTL = 10 * time.Minute
+)
+
+var (
+	ErrBookNotFound       = errors.New("book not found")
+	ErrInvalidContributor = errors.New("contributor role must be author, translator, editor or illustrator")
+	ErrNoAuthor           = errors.New("book must have at least one author")
+	ErrUnknownAuthor      = errors.New("contributor refers to an author that does not exist")
+)
+
+type Service interface {
+	Get(ctx context.Context, id int) (model.Book, error)
+	Rating(ctx context.Context, id int) (dto.BookRating, error)
+	List(ctx context.Context, filter dto.BookListFilter) (dto.BookListResponse, error)
+	SetContributors(ctx context.Context, bookId int, req dto.SetBookContributorsRequest) ([]model.Contributor, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) Get(ctx context.Context, id int) (model.Book, error) {
+	ctx, span := tracing.Start(ctx, "BookService.Get")
+	defer span.End()
+
+	book, err := cache.Fetch(ctx, s.st.Cache(), cache.Book(id), bookTTL, func(ctx context.Context) (model.Book, error) {
+		return s.load(ctx, id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return book, ErrBookNotFound
+	}
+
+	if err != nil {
+		return book, fmt.Errorf("BookService:Get err -> %s", err.Error())
+	}
+
+	return book, nil
+}
+
+func (s *service) load(ctx context.Context, id int) (model.Book, error) {
+	book, err := s.st.DB().Book().Get(ctx, id)
+	if err != nil {
+		return book, err
+	}
+
+	book.Contributors, err = s.st.DB().Contributor().ByBook(ctx, id)
+
+	return book, err
+}
+
+func (s *service) Rating(ctx context.Context, id int) (dto.BookRating, error) {
+	ctx, span := tracing.Start(ctx, "BookService.Rating")
+	defer span.End()
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return dto.BookRating{}, err
+	}
+
+	rating, err := cache.Fetch(ctx, s.st.Cache(), cache.BookRating(id), ratingTTL, func(ctx context.Context) (dto.BookRating, error) {
+		return s.st.DB().Book().Rating(ctx, id)
+	})
+	if err != nil {
+		return rating, fmt.Errorf("BookService:Rating err -> %s", err.Error())
+	}
+
+	return rating, nil
+}
+
+func (s *service) List(ctx context.Context, filter dto.BookListFilter) (dto.BookListResponse, error) {
+	ctx, span := tracing.Start(ctx, "BookService.List")
+	defer span.End()
+
+	filter.Tag = strings.TrimSpace(filter.Tag)
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+
+	if filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	result, err := s.st.DB().Book().List(ctx, filter)
+	if err != nil {
+		return result, fmt.Errorf("BookService:List err -> %s", err.Error())
+	}
+
+	err = contributor.Attach(ctx, s.st.DB().Contributor(), result.Books, func(book *dto.BookListItem) (int, *[]model.Contributor) {
+		return book.Id, &book.Contributors
+	})
+	if err != nil {
+		return result, fmt.Errorf("BookService:List err -> %s", err.Error())
+	}
+
+	return result, nil
+}
+
+// SetContributors заменяет список участников книги; порядок в запросе становится порядком в титрах
+func (s *service) SetContributors(ctx context.Context, bookId int, req dto.SetBookContributorsRequest) ([]model.Contributor, error) {
+	ctx, span := tracing.Start(ctx, "BookService.SetContributors")
+	defer span.End()
+
+	contributors := make([]model.Contributor, 0, len(req.Contributors))
+	hasAuthor := false
+
+	for i, item := range req.Contributors {
+		role := item.Role
+		if role == "" {
+			role = model.RoleAuthor
+		}
+
+		switch role {
+		case model.RoleAuthor:
+			hasAuthor = true
+		case model.RoleTranslator, model.RoleEditor, model.RoleIllustrator:
+		default:
+			return nil, ErrInvalidContributor
+		}
+
+		contributors = append(contributors, model.Contributor{
+			BookId:   bookId,
+			AuthorId: item.AuthorId,
+			Role:     role,
+			Position: i,
+		})
+	}
+
+	if !hasAuthor {
+		return nil, ErrNoAuthor
+	}
+
+	if _, err := s.Get(ctx, bookId); err != nil {
+		return nil, err
+	}
+
+	err := s.st.DB().Contributor().Set(ctx, bookId, contributors)
+
+	// Книгу уже проверили, значит внешний ключ нарушен несуществующим автором
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return nil, ErrUnknownAuthor
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("BookService:SetContributors err -> %s", err.Error())
+	}
+
+	cache.Invalidate(ctx, s.st.Cache(), cache.Book(bookId))
+
+	result, err := s.st.DB().Contributor().ByBook(ctx, bookId)
+	if err != nil {
+		return nil, fmt.Errorf("BookService:SetContributors err -> %s", err.Error())
+	}
+
+	return result, nil
+}
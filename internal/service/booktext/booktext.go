@@ -0,0 +1,276 @@
+package booktext
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/book"
+	"nevermore/internal/storage"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/textextract"
+	"nevermore/pkg/tracing"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	// IndexJob — задача извлечения текста книги из её файла и пересборки индекса
+	IndexJob = "booktext.index"
+
+	// maxFileSize — файлы больше не скачиваются, чтобы одна книга не съела память экземпляра
+	maxFileSize  = 200 << 20
+	indexTimeout = 10 * time.Minute
+	// downloadTimeout ограничивает скачивание вместе с чтением тела, чтобы зависшее
+	// соединение не держало воркер до конца indexTimeout
+	downloadTimeout = 5 * time.Minute
+)
+
+var (
+	ErrBookNotFound = errors.New("book not found")
+	ErrFileTooLarge = errors.New("book file is too large to index")
+	ErrForeignFile  = errors.New("book file is not in object storage")
+)
+
+type Config struct {
+	// FileHost — host[:port] хранилища объектов; файлы с других хостов не скачиваются.
+	// Пустой — хранилище не настроено, и индексировать из файлов нечего
+	FileHost string
+}
+
+type Service interface {
+	Index(ctx context.Context, bookId int, fragments []model.Fragment) error
+	// Reindex ставит книгу в очередь на извлечение текста из file_url
+	Reindex(ctx context.Context, bookId int) error
+	// Backfill ставит в очередь все книги без индекса и возвращает их число
+	Backfill(ctx context.Context) (int, error)
+	Search(ctx context.Context, req dto.BookTextSearchRequest) (dto.BookTextSearchResponse, error)
+}
+
+type indexPayload struct {
+	BookId int `json:"book_id"`
+}
+
+type service struct {
+	st     storage.Storage
+	client *http.Client
+	cfg    Config
+	index  jobs.Type[indexPayload]
+}
+
+func New(st storage.Storage, queue *jobs.Queue, cfg Config) Service {
+	result := &service{
+		st:  st,
+		cfg: cfg,
+	}
+
+	result.client = &http.Client{
+		Timeout: downloadTimeout,
+		// Редирект не должен увести за пределы хранилища
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !result.allowed(req.URL) {
+				return ErrForeignFile
+			}
+
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+
+			return nil
+		},
+	}
+
+	result.index = jobs.Register(queue, jobs.Definition{
+		Type:    IndexJob,
+		Timeout: indexTimeout,
+	}, result.indexFile)
+
+	return result
+}
+
+func (s *service) Index(ctx context.Context, bookId int, fragments []model.Fragment) error {
+	ctx, span := tracing.Start(ctx, "BookTextService.Index")
+	defer span.End()
+
+	indexed := make([]model.Fragment, 0, len(fragments))
+	for _, fragment := range fragments {
+		if strings.TrimSpace(fragment.Content) == "" {
+			continue
+		}
+
+		indexed = append(indexed, fragment)
+	}
+
+	err := s.st.DB().BookText().ReplaceIndex(ctx, bookId, indexed)
+	if err != nil {
+		return fmt.Errorf("BookTextService:Index err -> %s", err.Error())
+	}
+
+	metrics.Uploads.WithLabelValues(metrics.UploadBookText).Inc()
+
+	return nil
+}
+
+func (s *service) Reindex(ctx context.Context, bookId int) error {
+	ctx, span := tracing.Start(ctx, "BookTextService.Reindex")
+	defer span.End()
+
+	_, err := s.st.DB().Book().Get(ctx, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrBookNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("BookTextService:Reindex err -> %s", err.Error())
+	}
+
+	if _, err := s.index.Enqueue(ctx, indexPayload{BookId: bookId}); err != nil {
+		return fmt.Errorf("BookTextService:Reindex err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) Backfill(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "BookTextService.Backfill")
+	defer span.End()
+
+	ids, err := s.st.DB().BookText().Unindexed(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("BookTextService:Backfill err -> %s", err.Error())
+	}
+
+	for i, id := range ids {
+		if _, err := s.index.Enqueue(ctx, indexPayload{BookId: id}); err != nil {
+			return i, fmt.Errorf("BookTextService:Backfill err -> %s", err.Error())
+		}
+	}
+
+	return len(ids), nil
+}
+
+// indexFile выполняет задачу IndexJob. Формат, который не удаётся разобрать, и файл
+// не из хранилища объектов не повторяются: от повтора они не изменятся
+func (s *service) indexFile(ctx context.Context, payload indexPayload) error {
+	book, err := s.st.DB().Book().Get(ctx, payload.BookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	data, err := s.download(ctx, book.FileUrl)
+	if errors.Is(err, ErrForeignFile) {
+		log := logger.NamedFromContext(ctx, "booktext")
+		log.Warn().Int("book_id", book.Id).Str("file_url", book.FileUrl).Msg("book file is outside object storage, skipping text index")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	extracted, err := textextract.Extract(book.FileUrl, data)
+	if errors.Is(err, textextract.ErrUnsupportedFormat) {
+		log := logger.NamedFromContext(ctx, "booktext")
+		log.Warn().Int("book_id", book.Id).Str("file_url", book.FileUrl).Msg("book format is not supported, skipping text index")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fragments := make([]model.Fragment, 0, len(extracted))
+	for i, fragment := range extracted {
+		fragments = append(fragments, model.Fragment{
+			BookId:       book.Id,
+			Position:     i,
+			Cfi:          fragment.Cfi,
+			Page:         fragment.Page,
+			ChapterIndex: fragment.Chapter,
+			Content:      fragment.Text,
+		})
+	}
+
+	return s.Index(ctx, book.Id, fragments)
+}
+
+func (s *service) download(ctx context.Context, fileUrl string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.allowed(req.URL) {
+		return nil, ErrForeignFile
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: unexpected status %d", fileUrl, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return data, nil
+}
+
+// allowed пускает только http(s) на хост хранилища объектов: file_url приходит от пользователя,
+// и без проверки воркер можно было бы отправить во внутреннюю сеть
+func (s *service) allowed(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	return s.cfg.FileHost != "" && strings.EqualFold(u.Host, s.cfg.FileHost)
+}
+
+func (s *service) Search(ctx context.Context, req dto.BookTextSearchRequest) (dto.BookTextSearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "BookTextService.Search")
+	defer span.End()
+
+	req.Query = strings.TrimSpace(req.Query)
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	result, err := s.st.DB().BookText().Search(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("BookTextService:Search err -> %s", err.Error())
+	}
+
+	return result, nil
+}
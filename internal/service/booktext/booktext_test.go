@@ -0,0 +1,61 @@
+package booktext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nevermore/pkg/jobs"
+)
+
+func TestDownloadOnlyFromObjectStorage(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/away" {
+			http.Redirect(w, r, "http://internal.example/secret", http.StatusFound)
+			return
+		}
+
+		w.Write([]byte("book"))
+	}))
+	defer storage.Close()
+
+	host := storage.Listener.Addr().String()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		fileUrl string
+		wantErr error
+	}{
+		{"object storage", Config{FileHost: host}, storage.URL + "/pdfs/book.pdf", nil},
+		{"other host", Config{FileHost: host}, "http://169.254.169.254/latest/meta-data", ErrForeignFile},
+		{"other scheme", Config{FileHost: host}, "file://" + host + "/etc/passwd", ErrForeignFile},
+		{"redirect away", Config{FileHost: host}, storage.URL + "/away", ErrForeignFile},
+		{"storage not configured", Config{}, storage.URL + "/pdfs/book.pdf", ErrForeignFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(nil, jobs.NewQueue(nil, jobs.Config{}), tt.cfg).(*service)
+
+			data, err := s.download(context.Background(), tt.fileUrl)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("download err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && string(data) != "book" {
+				t.Errorf("download = %q, want %q", data, "book")
+			}
+		})
+	}
+}
+
+func TestDownloadHasTimeout(t *testing.T) {
+	s := New(nil, jobs.NewQueue(nil, jobs.Config{}), Config{}).(*service)
+
+	if s.client.Timeout <= 0 {
+		t.Fatalf("client timeout = %v, want a limit", s.client.Timeout)
+	}
+}
@@ -0,0 +1,151 @@
+package flag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"nevermore/internal/dto"
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/storage"
+	"nevermore/pkg/flags"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/tracing"
+)
+
+// refreshInterval — за это время изменения флагов доходят до остальных экземпляров
+const refreshInterval = 30 * time.Second
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+var (
+	ErrInvalidFlag  = errors.New("flag name must be snake_case, roles must be admin, moderator or user and percentage must be between 0 and 100")
+	ErrFlagNotFound = errors.New("flag not found")
+)
+
+type Service interface {
+	List(ctx context.Context) ([]flags.Flag, error)
+	Put(ctx context.Context, userId int, name string, req dto.FlagRequest) (flags.Flag, error)
+	Delete(ctx context.Context, name string) error
+
+	Enabled(name string, subject flags.Subject) bool
+	For(subject flags.Subject) map[string]bool
+	RunRefresher(ctx context.Context)
+}
+
+type service struct {
+	st        storage.Storage
+	evaluator *flags.Evaluator
+}
+
+func New(st storage.Storage, toggles *flags.Toggles) Service {
+	result := &service{
+		st:        st,
+		evaluator: flags.NewEvaluator(st.DB().Flag(), toggles),
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context) ([]flags.Flag, error) {
+	ctx, span := tracing.Start(ctx, "FlagService.List")
+	defer span.End()
+
+	list, err := s.st.DB().Flag().List(ctx)
+	if err != nil {
+		return list, fmt.Errorf("FlagService:List err -> %s", err.Error())
+	}
+
+	return list, nil
+}
+
+func (s *service) Put(ctx context.Context, userId int, name string, req dto.FlagRequest) (flags.Flag, error) {
+	ctx, span := tracing.Start(ctx, "FlagService.Put")
+	defer span.End()
+
+	flag := flags.Flag{
+		Name:        name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Roles:       make([]string, 0, len(req.Roles)),
+		UserIds:     make([]int, 0, len(req.UserIds)),
+		Percentage:  req.Percentage,
+		UpdatedBy:   &userId,
+	}
+
+	if !namePattern.MatchString(flag.Name) || flag.Percentage < 0 || flag.Percentage > 100 {
+		return flag, ErrInvalidFlag
+	}
+
+	for _, role := range req.Roles {
+		if role != userModel.RoleAdmin && role != userModel.RoleModerator && role != userModel.RoleUser {
+			return flag, ErrInvalidFlag
+		}
+
+		if !slices.Contains(flag.Roles, role) {
+			flag.Roles = append(flag.Roles, role)
+		}
+	}
+
+	for _, id := range req.UserIds {
+		if id <= 0 {
+			return flag, ErrInvalidFlag
+		}
+
+		if !slices.Contains(flag.UserIds, id) {
+			flag.UserIds = append(flag.UserIds, id)
+		}
+	}
+
+	flag, err := s.st.DB().Flag().Upsert(ctx, flag)
+	if err != nil {
+		return flag, fmt.Errorf("FlagService:Put err -> %s", err.Error())
+	}
+
+	s.reload(ctx)
+
+	return flag, nil
+}
+
+func (s *service) Delete(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "FlagService.Delete")
+	defer span.End()
+
+	err := s.st.DB().Flag().Delete(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrFlagNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("FlagService:Delete err -> %s", err.Error())
+	}
+
+	s.reload(ctx)
+
+	return nil
+}
+
+func (s *service) Enabled(name string, subject flags.Subject) bool {
+	return s.evaluator.Enabled(name, subject)
+}
+
+func (s *service) For(subject flags.Subject) map[string]bool {
+	return s.evaluator.For(subject)
+}
+
+func (s *service) RunRefresher(ctx context.Context) {
+	s.evaluator.Run(ctx, refreshInterval)
+}
+
+// reload применяет изменение на этом экземпляре сразу, не дожидаясь очередного
+// перечитывания. Ошибка не отменяет записанное: флаги догонят при следующем
+func (s *service) reload(ctx context.Context) {
+	if err := s.evaluator.Reload(ctx); err != nil {
+		log := logger.NamedFromContext(ctx, "flags")
+		log.Error().Err(err).Msg("failed to reload feature flags")
+	}
+}
@@ -0,0 +1,78 @@
+package follow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"nevermore/internal/dto"
+	"nevermore/internal/service/notification"
+	"nevermore/internal/storage"
+	"nevermore/pkg/tracing"
+)
+
+var ErrAuthorNotFound = errors.New("author not found")
+
+type Service interface {
+	Follow(ctx context.Context, userId, authorId int) error
+	Unfollow(ctx context.Context, userId, authorId int) error
+	MyAuthors(ctx context.Context, userId int) ([]dto.FollowedAuthor, error)
+	RunNotifier(ctx context.Context)
+}
+
+type service struct {
+	st           storage.Storage
+	notification notification.Service
+}
+
+func New(st storage.Storage, notification notification.Service) Service {
+	result := &service{
+		st:           st,
+		notification: notification,
+	}
+
+	return result
+}
+
+func (s *service) Follow(ctx context.Context, userId, authorId int) error {
+	ctx, span := tracing.Start(ctx, "FollowService.Follow")
+	defer span.End()
+
+	_, err := s.st.DB().Author().Get(ctx, authorId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAuthorNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("FollowService:Follow err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().Follow().Follow(ctx, userId, authorId); err != nil {
+		return fmt.Errorf("FollowService:Follow err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) Unfollow(ctx context.Context, userId, authorId int) error {
+	ctx, span := tracing.Start(ctx, "FollowService.Unfollow")
+	defer span.End()
+
+	if err := s.st.DB().Follow().Unfollow(ctx, userId, authorId); err != nil {
+		return fmt.Errorf("FollowService:Unfollow err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) MyAuthors(ctx context.Context, userId int) ([]dto.FollowedAuthor, error) {
+	ctx, span := tracing.Start(ctx, "FollowService.MyAuthors")
+	defer span.End()
+
+	authors, err := s.st.DB().Follow().ListAuthors(ctx, userId)
+	if err != nil {
+		return authors, fmt.Errorf("FollowService:MyAuthors err -> %s", err.Error())
+	}
+
+	return authors, nil
+}
@@ -0,0 +1,71 @@
+package follow
+
+import (
+	"context"
+	"time"
+
+	"nevermore/pkg/logger"
+	"nevermore/pkg/requestid"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	notifyInterval = 30 * time.Second
+	notifyTimeout  = 30 * time.Second
+	notifyBatch    = 100
+
+	// notifyGrace даёт время дописать всех участников книги после её создания,
+	// чтобы подписчики соавторов получили уведомление вместе с остальными
+	notifyGrace = time.Minute
+)
+
+// RunNotifier периодически рассылает подписчикам уведомления о новых книгах
+// их авторов. Блокируется до отмены ctx. Проходы идут по очереди в этой горутине,
+// письма уходят через очередь задач
+func (s *service) RunNotifier(ctx context.Context) {
+	ticker := time.NewTicker(notifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// У каждого прохода свой id, чтобы его записи в логах читались вместе
+			s.notifyNewBooks(requestid.Attach(ctx, requestid.New()))
+		}
+	}
+}
+
+func (s *service) notifyNewBooks(ctx context.Context) {
+	ctx, span := tracing.StartJob(ctx, "FollowService.notifyNewBooks")
+	defer span.End()
+
+	log := logger.NamedFromContext(ctx, "follow_notifier")
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	for {
+		count, emails, err := s.st.DB().Follow().NotifyNewBooks(ctx, time.Now().Add(-notifyGrace), notifyBatch)
+		if err != nil {
+			tracing.Fail(span, err)
+			log.Error().Err(err).Msg("failed to notify followers about new books")
+			return
+		}
+
+		for _, item := range emails {
+			if err := s.notification.SendEmail(ctx, item); err != nil {
+				log.Error().Err(err).Int("user_id", item.UserId).Msg("failed to email follower about new book")
+			}
+		}
+
+		if count > 0 {
+			log.Info().Int("books", count).Msg("followers notified about new books")
+		}
+
+		if count < notifyBatch {
+			return
+		}
+	}
+}
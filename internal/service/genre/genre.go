@@ -0,0 +1,123 @@
+package genre
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/genre"
+	"nevermore/internal/storage"
+	"nevermore/pkg/tracing"
+	"strings"
+)
+
+var ErrInvalidGenre = errors.New("genre name and slug are required")
+
+type Service interface {
+	Tree(ctx context.Context) ([]model.Genre, error)
+	Create(ctx context.Context, req dto.CreateGenreRequest) (model.Genre, error)
+	Delete(ctx context.Context, id int) error
+	GetByBook(ctx context.Context, bookId int) ([]model.Genre, error)
+	SetBookGenres(ctx context.Context, bookId int, genreIds []int) error
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+// Tree собирает плоский список жанров в дерево по parent_id
+func (s *service) Tree(ctx context.Context) ([]model.Genre, error) {
+	ctx, span := tracing.Start(ctx, "GenreService.Tree")
+	defer span.End()
+
+	genres, err := s.st.DB().Genre().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GenreService:Tree err -> %s", err.Error())
+	}
+
+	children := make(map[int][]model.Genre)
+	for _, genre := range genres {
+		parentId := 0
+		if genre.ParentId != nil {
+			parentId = *genre.ParentId
+		}
+
+		children[parentId] = append(children[parentId], genre)
+	}
+
+	var build func(parentId int) []model.Genre
+	build = func(parentId int) []model.Genre {
+		result := make([]model.Genre, 0, len(children[parentId]))
+		for _, genre := range children[parentId] {
+			genre.Children = build(genre.Id)
+			result = append(result, genre)
+		}
+
+		return result
+	}
+
+	return build(0), nil
+}
+
+func (s *service) Create(ctx context.Context, req dto.CreateGenreRequest) (model.Genre, error) {
+	ctx, span := tracing.Start(ctx, "GenreService.Create")
+	defer span.End()
+
+	genre := model.Genre{
+		ParentId: req.ParentId,
+		Name:     strings.TrimSpace(req.Name),
+		Slug:     strings.ToLower(strings.TrimSpace(req.Slug)),
+	}
+
+	if genre.Name == "" || genre.Slug == "" {
+		return genre, ErrInvalidGenre
+	}
+
+	if err := s.st.DB().Genre().Create(ctx, &genre); err != nil {
+		return genre, fmt.Errorf("GenreService:Create err -> %s", err.Error())
+	}
+
+	return genre, nil
+}
+
+func (s *service) Delete(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "GenreService.Delete")
+	defer span.End()
+
+	if err := s.st.DB().Genre().Delete(ctx, id); err != nil {
+		return fmt.Errorf("GenreService:Delete err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) GetByBook(ctx context.Context, bookId int) ([]model.Genre, error) {
+	ctx, span := tracing.Start(ctx, "GenreService.GetByBook")
+	defer span.End()
+
+	genres, err := s.st.DB().Genre().GetByBook(ctx, bookId)
+	if err != nil {
+		return genres, fmt.Errorf("GenreService:GetByBook err -> %s", err.Error())
+	}
+
+	return genres, nil
+}
+
+func (s *service) SetBookGenres(ctx context.Context, bookId int, genreIds []int) error {
+	ctx, span := tracing.Start(ctx, "GenreService.SetBookGenres")
+	defer span.End()
+
+	if err := s.st.DB().Genre().SetBookGenres(ctx, bookId, genreIds); err != nil {
+		return fmt.Errorf("GenreService:SetBookGenres err -> %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/oauth2"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/identity"
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/service/auth"
+	"nevermore/internal/service/mail"
+	"nevermore/internal/storage"
+	authToken "nevermore/pkg/auth"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/oidc"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	stateTTL = 10 * time.Minute
+
+	maxNameLength = 40
+	nameAttempts  = 5
+)
+
+var (
+	ErrUnknownProvider     = errors.New("unknown identity provider")
+	ErrInvalidState        = errors.New("login attempt is invalid or expired, start again")
+	ErrEmailNotVerified    = errors.New("provider did not confirm the email address")
+	ErrEmailTaken          = errors.New("email belongs to a deleted account")
+	ErrAccountNotVerified  = errors.New("an account with this email exists but its email is not verified: sign in with the password, verify the email and link the provider in settings")
+	ErrIdentityTaken       = errors.New("this provider account is linked to another user")
+	ErrIdentityNotFound    = errors.New("provider is not linked")
+	ErrLastLoginMethod     = errors.New("set a password before unlinking the last provider")
+	ErrProviderUnavailable = errors.New("identity provider is unavailable")
+)
+
+type Service interface {
+	Providers() []string
+	// LoginURL начинает вход через провайдера и возвращает адрес его страницы и state.
+	// State нужно привязать к браузеру, начавшему вход, и сверить в колбэке.
+	// С linkUserId провайдер привязывается к этому пользователю
+	LoginURL(ctx context.Context, provider string, linkUserId *int) (string, string, error)
+	Callback(ctx context.Context, provider, code, state string) (dto.OIDCCallbackResponse, error)
+	Identities(ctx context.Context, userId int) ([]model.Identity, error)
+	Unlink(ctx context.Context, userId int, provider string) error
+}
+
+type service struct {
+	st        storage.Storage
+	auth      auth.Service
+	providers map[string]*oidc.Provider
+}
+
+func New(st storage.Storage, auth auth.Service, providers []oidc.Config) Service {
+	result := &service{
+		st:        st,
+		auth:      auth,
+		providers: make(map[string]*oidc.Provider, len(providers)),
+	}
+
+	for _, cfg := range providers {
+		result.providers[cfg.Name] = oidc.NewProvider(cfg)
+	}
+
+	return result
+}
+
+func (s *service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (s *service) LoginURL(ctx context.Context, provider string, linkUserId *int) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "IdentityService.LoginURL")
+	defer span.End()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := authToken.NewOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("IdentityService:LoginURL err -> %s", err.Error())
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", fmt.Errorf("IdentityService:LoginURL err -> %s", err.Error())
+	}
+
+	verifier := oauth2.GenerateVerifier()
+
+	url, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", fmt.Errorf("IdentityService:LoginURL err -> %s: %w", err.Error(), ErrProviderUnavailable)
+	}
+
+	record := model.State{
+		StateHash:    authToken.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserId:   linkUserId,
+		ExpiresAt:    time.Now().Add(stateTTL),
+	}
+
+	if err := s.st.DB().Identity().SaveState(ctx, record); err != nil {
+		return "", "", fmt.Errorf("IdentityService:LoginURL err -> %s", err.Error())
+	}
+
+	return url, state, nil
+}
+
+func (s *service) Callback(ctx context.Context, provider, code, state string) (dto.OIDCCallbackResponse, error) {
+	ctx, span := tracing.Start(ctx, "IdentityService.Callback")
+	defer span.End()
+
+	result := dto.OIDCCallbackResponse{Provider: provider}
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return result, ErrUnknownProvider
+	}
+
+	record, err := s.st.DB().Identity().ConsumeState(ctx, authToken.HashToken(state))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && record.Provider != provider) {
+		return result, ErrInvalidState
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("IdentityService:Callback err -> %s", err.Error())
+	}
+
+	claims, err := p.Exchange(ctx, code, record.Nonce, record.CodeVerifier)
+	if err != nil {
+		return result, fmt.Errorf("IdentityService:Callback err -> %s: %w", err.Error(), ErrProviderUnavailable)
+	}
+
+	existing, err := s.st.DB().Identity().Get(ctx, provider, claims.Subject)
+	found := err == nil
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return result, fmt.Errorf("IdentityService:Callback err -> %s", err.Error())
+	}
+
+	if record.LinkUserId != nil {
+		if found && existing.UserId != *record.LinkUserId {
+			return result, ErrIdentityTaken
+		}
+
+		if !found {
+			if err := s.link(ctx, *record.LinkUserId, provider, claims, false); err != nil {
+				return result, err
+			}
+		}
+
+		result.Linked = true
+
+		return result, nil
+	}
+
+	userId := existing.UserId
+
+	if found {
+		if err := s.st.DB().Identity().TouchLogin(ctx, existing.Id); err != nil {
+			return result, fmt.Errorf("IdentityService:Callback err -> %s", err.Error())
+		}
+	} else {
+		userId, err = s.signUpOrLink(ctx, provider, claims)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.AuthResponse, err = s.auth.CompleteSignIn(ctx, userId)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (s *service) Identities(ctx context.Context, userId int) ([]model.Identity, error) {
+	ctx, span := tracing.Start(ctx, "IdentityService.Identities")
+	defer span.End()
+
+	identities, err := s.st.DB().Identity().ListByUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("IdentityService:Identities err -> %s", err.Error())
+	}
+
+	return identities, nil
+}
+
+// Unlink не даёт отвязать последний провайдер у аккаунта без пароля, иначе в него будет не войти
+func (s *service) Unlink(ctx context.Context, userId int, provider string) error {
+	ctx, span := tracing.Start(ctx, "IdentityService.Unlink")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("IdentityService:Unlink err -> %s", err.Error())
+	}
+
+	identities, err := s.st.DB().Identity().ListByUser(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("IdentityService:Unlink err -> %s", err.Error())
+	}
+
+	if user.Password == "" && len(identities) <= 1 {
+		return ErrLastLoginMethod
+	}
+
+	err = s.st.DB().Identity().Delete(ctx, userId, provider)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrIdentityNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("IdentityService:Unlink err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+// signUpOrLink привязывает провайдера к аккаунту с той же подтверждённой почтой
+// или создаёт новый аккаунт без пароля. К аккаунту с неподтверждённой почтой провайдер
+// сам не привязывается: его мог заранее завести кто угодно, зная чужой адрес, и после
+// привязки владелец почты оказался бы в аккаунте с чужим паролем
+func (s *service) signUpOrLink(ctx context.Context, provider string, claims oidc.Claims) (int, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return 0, ErrEmailNotVerified
+	}
+
+	email := strings.ToLower(claims.Email)
+
+	user, err := s.st.DB().User().GetByEmail(ctx, email)
+	if err == nil && user.EmailVerifiedAt == nil {
+		return 0, ErrAccountNotVerified
+	}
+
+	if err == nil {
+		return user.Id, s.link(ctx, user.Id, provider, claims, true)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("IdentityService:signUpOrLink err -> %s", err.Error())
+	}
+
+	base := displayName(claims)
+
+	for attempt := 0; attempt < nameAttempts; attempt++ {
+		name := base
+		if attempt > 0 {
+			suffix, err := randomString()
+			if err != nil {
+				return 0, fmt.Errorf("IdentityService:signUpOrLink err -> %s", err.Error())
+			}
+
+			name = fmt.Sprintf("%s_%s", base, suffix[:6])
+		}
+
+		userId, err := s.createUser(ctx, name, email, provider, claims)
+
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			if pqErr.Constraint == "users_email_key" {
+				return 0, ErrEmailTaken
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("IdentityService:signUpOrLink err -> %s", err.Error())
+		}
+
+		metrics.SignUps.WithLabelValues(metrics.MethodOIDC).Inc()
+
+		return userId, nil
+	}
+
+	return 0, fmt.Errorf("IdentityService:signUpOrLink err -> could not pick a free name for '%s'", base)
+}
+
+func (s *service) createUser(ctx context.Context, name, email, provider string, claims oidc.Claims) (int, error) {
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	user := userModel.User{
+		Name:            name,
+		Email:           email,
+		Role:            userModel.RoleUser,
+		Locale:          mail.NormalizeLocale(claims.Locale),
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+	}
+
+	if err := s.st.DB().User().CreateTx(ctx, tx, &user); err != nil {
+		return 0, err
+	}
+
+	identity := model.Identity{
+		UserId:      user.Id,
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       &email,
+		LastLoginAt: &now,
+	}
+
+	if err := s.st.DB().Identity().CreateTx(ctx, tx, &identity); err != nil {
+		return 0, err
+	}
+
+	return user.Id, tx.Commit()
+}
+
+func (s *service) link(ctx context.Context, userId int, provider string, claims oidc.Claims, signIn bool) error {
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("IdentityService:link err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	identity := model.Identity{
+		UserId:   userId,
+		Provider: provider,
+		Subject:  claims.Subject,
+	}
+
+	if claims.Email != "" {
+		email := strings.ToLower(claims.Email)
+		identity.Email = &email
+	}
+
+	if signIn {
+		now := time.Now()
+		identity.LastLoginAt = &now
+	}
+
+	err = s.st.DB().Identity().CreateTx(ctx, tx, &identity)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrIdentityTaken
+	}
+
+	if err != nil {
+		return fmt.Errorf("IdentityService:link err -> %s", err.Error())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("IdentityService:link err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func displayName(claims oidc.Claims) string {
+	name := strings.TrimSpace(claims.PreferredUsername)
+	if name == "" {
+		name = strings.TrimSpace(claims.Name)
+	}
+
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	runes := []rune(name)
+	if len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+
+	return name
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
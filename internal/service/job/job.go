@@ -0,0 +1,109 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/storage"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+var (
+	ErrInvalidJobFilter = errors.New("status must be pending, running or dead")
+	ErrJobNotFound      = errors.New("job not found")
+	ErrJobNotDead       = errors.New("only dead jobs can be retried")
+)
+
+type Service interface {
+	List(ctx context.Context, req dto.JobListRequest) (dto.JobListResponse, error)
+	Get(ctx context.Context, id int64) (jobs.Job, error)
+	Retry(ctx context.Context, id int64) (jobs.Job, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context, req dto.JobListRequest) (dto.JobListResponse, error) {
+	ctx, span := tracing.Start(ctx, "JobService.List")
+	defer span.End()
+
+	switch req.Status {
+	case "", jobs.StatusPending, jobs.StatusRunning, jobs.StatusDead:
+	default:
+		return dto.JobListResponse{}, ErrInvalidJobFilter
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	result, err := s.st.DB().Job().List(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("JobService:List err -> %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func (s *service) Get(ctx context.Context, id int64) (jobs.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobService.Get")
+	defer span.End()
+
+	job, err := s.st.DB().Job().Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return job, ErrJobNotFound
+	}
+
+	if err != nil {
+		return job, fmt.Errorf("JobService:Get err -> %s", err.Error())
+	}
+
+	return job, nil
+}
+
+func (s *service) Retry(ctx context.Context, id int64) (jobs.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobService.Retry")
+	defer span.End()
+
+	job, err := s.st.DB().Job().Retry(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Задачи нет или она не мёртвая — уточняем, чтобы ответить понятной ошибкой
+		if _, err := s.Get(ctx, id); err != nil {
+			return job, err
+		}
+
+		return job, ErrJobNotDead
+	}
+
+	if err != nil {
+		return job, fmt.Errorf("JobService:Retry err -> %s", err.Error())
+	}
+
+	return job, nil
+}
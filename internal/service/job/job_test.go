@@ -0,0 +1,94 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/postgres"
+	jobRepo "nevermore/internal/storage/postgres/job"
+	"nevermore/pkg/jobs"
+)
+
+type fakeStorage struct {
+	storage.Storage
+	db *fakeDB
+}
+
+func (s *fakeStorage) DB() postgres.Repo { return s.db }
+
+type fakeDB struct {
+	postgres.Repo
+	jobs *fakeJobs
+}
+
+func (d *fakeDB) Job() jobRepo.Repo { return d.jobs }
+
+// fakeJobs повторяет условие Retry из SQL: в очередь возвращается только мёртвая задача
+type fakeJobs struct {
+	jobRepo.Repo
+	stored map[int64]jobs.Job
+}
+
+func (f *fakeJobs) Get(_ context.Context, id int64) (jobs.Job, error) {
+	job, ok := f.stored[id]
+	if !ok {
+		return jobs.Job{}, sql.ErrNoRows
+	}
+
+	return job, nil
+}
+
+func (f *fakeJobs) Retry(_ context.Context, id int64) (jobs.Job, error) {
+	job, ok := f.stored[id]
+	if !ok || job.Status != jobs.StatusDead {
+		return jobs.Job{}, sql.ErrNoRows
+	}
+
+	job.Status = jobs.StatusPending
+	job.Attempts = 0
+	f.stored[id] = job
+
+	return job, nil
+}
+
+func TestRetry(t *testing.T) {
+	s := New(&fakeStorage{db: &fakeDB{jobs: &fakeJobs{stored: map[int64]jobs.Job{
+		1: {Id: 1, Status: jobs.StatusDead, Attempts: 5, MaxAttempts: 5},
+		2: {Id: 2, Status: jobs.StatusRunning, Attempts: 1, MaxAttempts: 5},
+	}}}})
+
+	tests := []struct {
+		name    string
+		id      int64
+		wantErr error
+	}{
+		{"dead job returns to the queue", 1, nil},
+		{"running job cannot be retried", 2, ErrJobNotDead},
+		{"missing job", 3, ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := s.Retry(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Retry = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && (job.Status != jobs.StatusPending || job.Attempts != 0) {
+				t.Fatalf("retried job = %+v, want pending with no attempts", job)
+			}
+		})
+	}
+}
+
+func TestListRejectsUnknownStatus(t *testing.T) {
+	s := New(&fakeStorage{db: &fakeDB{jobs: &fakeJobs{}}})
+
+	if _, err := s.List(context.Background(), dto.JobListRequest{Status: "done"}); !errors.Is(err, ErrInvalidJobFilter) {
+		t.Fatalf("List = %v, want ErrInvalidJobFilter", err)
+	}
+}
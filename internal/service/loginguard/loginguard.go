@@ -0,0 +1,262 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "nevermore/internal/model/loginattempt"
+	"nevermore/internal/storage"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultMaxAccountFailures = 10
+	defaultMaxIPFailures      = 50
+	defaultFreeFailures       = 3
+	defaultBaseDelay          = time.Second
+	defaultMaxDelay           = time.Minute
+	defaultFailureWindow      = time.Hour
+	defaultLockoutDuration    = 30 * time.Minute
+)
+
+// События журнала безопасности
+const (
+	EventLoginLocked        = "login_while_locked"
+	EventAccountLocked      = "account_locked"
+	EventIPLocked           = "ip_locked"
+	EventLoginAfterFailures = "login_after_failures"
+	EventAccountUnlocked    = "account_unlocked"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed sign in attempts, try again later")
+
+// AttemptsError сообщает, через сколько можно повторить вход
+type AttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AttemptsError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *AttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+type Config struct {
+	// MaxAccountFailures — после стольких неудач за окно аккаунт блокируется
+	MaxAccountFailures int
+	// MaxIPFailures — то же для адреса, с которого перебирают разные аккаунты
+	MaxIPFailures int
+	// FreeFailures — сколько неудач прощается без паузы, дальше пауза удваивается с каждой неудачей
+	FreeFailures    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	FailureWindow   time.Duration
+	LockoutDuration time.Duration
+}
+
+// Service считает неудачные попытки входа по аккаунту и по IP. Если Redis недоступен,
+// вход не блокируется: ошибка пишется в лог, а от перебора остаётся ограничение запросов по IP
+type Service interface {
+	// Check возвращает *AttemptsError, если аккаунт или адрес заблокированы или ещё не истекла пауза
+	Check(ctx context.Context, email, ip string) error
+	// Fail учитывает неудачу и возвращает true, если аккаунт только что заблокирован
+	Fail(ctx context.Context, email, ip string) bool
+	Succeed(ctx context.Context, email, ip string)
+	Unlock(ctx context.Context, email string)
+}
+
+type service struct {
+	st  storage.Storage
+	cfg Config
+}
+
+func New(st storage.Storage, cfg Config) Service {
+	if cfg.MaxAccountFailures == 0 {
+		cfg.MaxAccountFailures = defaultMaxAccountFailures
+	}
+
+	if cfg.MaxIPFailures == 0 {
+		cfg.MaxIPFailures = defaultMaxIPFailures
+	}
+
+	if cfg.FreeFailures == 0 {
+		cfg.FreeFailures = defaultFreeFailures
+	}
+
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = defaultBaseDelay
+	}
+
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = defaultMaxDelay
+	}
+
+	if cfg.FailureWindow == 0 {
+		cfg.FailureWindow = defaultFailureWindow
+	}
+
+	if cfg.LockoutDuration == 0 {
+		cfg.LockoutDuration = defaultLockoutDuration
+	}
+
+	result := &service{
+		st:  st,
+		cfg: cfg,
+	}
+
+	return result
+}
+
+func (s *service) Check(ctx context.Context, email, ip string) error {
+	ctx, span := tracing.Start(ctx, "LoginGuardService.Check")
+	defer span.End()
+
+	var retryAfter time.Duration
+
+	for _, subject := range []model.Subject{model.Account(email), model.IP(ip)} {
+		state, err := s.st.Redis().LoginAttempt().State(ctx, subject)
+		if err != nil {
+			s.logError(ctx, "Check", err)
+			return nil
+		}
+
+		if state.LockedFor > 0 {
+			s.security(ctx, EventLoginLocked, email, ip, state.Failures)
+		}
+
+		retryAfter = max(retryAfter, state.LockedFor, state.DelayFor)
+	}
+
+	if retryAfter > 0 {
+		return &AttemptsError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (s *service) Fail(ctx context.Context, email, ip string) bool {
+	ctx, span := tracing.Start(ctx, "LoginGuardService.Fail")
+	defer span.End()
+
+	repo := s.st.Redis().LoginAttempt()
+
+	accountFailures, err := repo.Fail(ctx, model.Account(email), s.cfg.FailureWindow)
+	if err != nil {
+		s.logError(ctx, "Fail", err)
+		return false
+	}
+
+	ipFailures, err := repo.Fail(ctx, model.IP(ip), s.cfg.FailureWindow)
+	if err != nil {
+		s.logError(ctx, "Fail", err)
+		return false
+	}
+
+	locked := s.penalize(ctx, model.Account(email), accountFailures, s.cfg.MaxAccountFailures)
+	if locked {
+		s.security(ctx, EventAccountLocked, email, ip, accountFailures)
+	}
+
+	if s.penalize(ctx, model.IP(ip), ipFailures, s.cfg.MaxIPFailures) {
+		s.security(ctx, EventIPLocked, email, ip, ipFailures)
+	}
+
+	return locked
+}
+
+// Succeed сбрасывает счётчик аккаунта. Счётчик адреса не сбрасывается, иначе одна
+// подобранная пара логин/пароль обнуляла бы перебор остальных аккаунтов с того же адреса
+func (s *service) Succeed(ctx context.Context, email, ip string) {
+	ctx, span := tracing.Start(ctx, "LoginGuardService.Succeed")
+	defer span.End()
+
+	repo := s.st.Redis().LoginAttempt()
+
+	state, err := repo.State(ctx, model.Account(email))
+	if err != nil {
+		s.logError(ctx, "Succeed", err)
+		return
+	}
+
+	if state.Failures == 0 {
+		return
+	}
+
+	if state.Failures > s.cfg.FreeFailures {
+		s.security(ctx, EventLoginAfterFailures, email, ip, state.Failures)
+	}
+
+	if err := repo.Reset(ctx, model.Account(email)); err != nil {
+		s.logError(ctx, "Succeed", err)
+	}
+}
+
+func (s *service) Unlock(ctx context.Context, email string) {
+	ctx, span := tracing.Start(ctx, "LoginGuardService.Unlock")
+	defer span.End()
+
+	if err := s.st.Redis().LoginAttempt().Reset(ctx, model.Account(email)); err != nil {
+		s.logError(ctx, "Unlock", err)
+		return
+	}
+
+	s.security(ctx, EventAccountUnlocked, email, "", 0)
+}
+
+// penalize блокирует субъект при достижении порога или назначает паузу перед следующей попыткой.
+// Пока блокировка действует, Check не допускает до проверки пароля, поэтому блокировка
+// и письмо о ней случаются один раз; после её истечения первая же неудача блокирует снова
+func (s *service) penalize(ctx context.Context, subject model.Subject, failures, maxFailures int) bool {
+	repo := s.st.Redis().LoginAttempt()
+
+	if failures >= maxFailures {
+		if err := repo.Lock(ctx, subject, s.cfg.LockoutDuration); err != nil {
+			s.logError(ctx, "Fail", err)
+			return false
+		}
+
+		return true
+	}
+
+	if delay := s.delay(failures); delay > 0 {
+		if err := repo.Delay(ctx, subject, delay); err != nil {
+			s.logError(ctx, "Fail", err)
+		}
+	}
+
+	return false
+}
+
+// delay — пауза перед следующей попыткой: после бесплатных неудач начинается с BaseDelay
+// и удваивается, пока не упрётся в MaxDelay
+func (s *service) delay(failures int) time.Duration {
+	if failures <= s.cfg.FreeFailures {
+		return 0
+	}
+
+	delay := s.cfg.BaseDelay
+	for i := s.cfg.FreeFailures + 1; i < failures && delay < s.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.cfg.MaxDelay)
+}
+
+func (s *service) security(ctx context.Context, event, email, ip string, failures int) {
+	log := logger.NamedFromContext(ctx, "security")
+	log.Warn().
+		Str("event", event).
+		Str("email", email).
+		Str("ip", ip).
+		Int("failures", failures).
+		Msg("login guard")
+}
+
+func (s *service) logError(ctx context.Context, method string, err error) {
+	log := logger.NamedFromContext(ctx, "loginguard")
+	log.Error().Err(err).Str("method", method).Msg("login attempts storage is unavailable")
+}
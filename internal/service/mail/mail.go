@@ -0,0 +1,191 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
+	"strings"
+	textTemplate "text/template"
+
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+	TemplateUnlockAccount = "unlock_account"
+	TemplateDigest        = "digest"
+	TemplateNotification  = "notification"
+)
+
+const (
+	LocaleRu = "ru"
+	LocaleEn = "en"
+
+	DefaultLocale = LocaleRu
+)
+
+// partialsFile — общие блоки языка, например строка уведомления; доступны всем его шаблонам
+const partialsFile = "partials.tmpl"
+
+var (
+	Templates = []string{TemplateVerifyEmail, TemplateResetPassword, TemplateUnlockAccount, TemplateDigest, TemplateNotification}
+	Locales   = []string{LocaleRu, LocaleEn}
+)
+
+// LinkData — данные писем со ссылкой подтверждения, сброса пароля или разблокировки входа
+type LinkData struct {
+	Name         string
+	Link         string
+	ExpiresHours int
+}
+
+type NotificationData struct {
+	Name    string
+	Type    string
+	Payload map[string]any
+}
+
+type DigestData struct {
+	Name          string
+	Notifications []NotificationData
+}
+
+var ErrUnknownTemplate = errors.New("unknown mail template")
+
+//go:embed templates
+var templatesFS embed.FS
+
+type Service interface {
+	// Send рендерит шаблон на языке получателя и ставит письмо в очередь отправки
+	Send(ctx context.Context, to, locale, template string, data any) error
+	// Deliver рендерит и отправляет письмо сразу, минуя очередь. Для писем с секретами:
+	// их вызывает задача, которая сама повторяется, и текст письма нигде не сохраняется
+	Deliver(ctx context.Context, to, locale, template string, data any) error
+}
+
+// Каждый файл шаблона содержит блоки subject, text и html
+type localized struct {
+	text *textTemplate.Template
+	html *htmlTemplate.Template
+}
+
+type service struct {
+	mailer    mailer.Mailer
+	queue     *mailer.Queue
+	templates map[string]map[string]localized
+}
+
+// New разбирает встроенные шаблоны; ошибка в них — ошибка сборки, поэтому приводит к панике
+func New(m mailer.Mailer, queue *mailer.Queue) Service {
+	result := &service{
+		mailer:    m,
+		queue:     queue,
+		templates: make(map[string]map[string]localized, len(Locales)),
+	}
+
+	for _, locale := range Locales {
+		result.templates[locale] = make(map[string]localized, len(Templates))
+
+		for _, name := range Templates {
+			path := fmt.Sprintf("templates/%s/%s.tmpl", locale, name)
+			partials := fmt.Sprintf("templates/%s/%s", locale, partialsFile)
+
+			result.templates[locale][name] = localized{
+				text: textTemplate.Must(textTemplate.ParseFS(templatesFS, path, partials)),
+				html: htmlTemplate.Must(htmlTemplate.ParseFS(templatesFS, path, partials)),
+			}
+		}
+	}
+
+	return result
+}
+
+func (s *service) Send(ctx context.Context, to, locale, template string, data any) error {
+	ctx, span := tracing.Start(ctx, "MailService.Send")
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := s.render(to, locale, template, data)
+	if err != nil {
+		return err
+	}
+
+	if err := s.queue.Enqueue(ctx, msg); err != nil {
+		return fmt.Errorf("MailService:Send err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) Deliver(ctx context.Context, to, locale, template string, data any) error {
+	ctx, span := tracing.Start(ctx, "MailService.Deliver")
+	defer span.End()
+
+	msg, err := s.render(to, locale, template, data)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("MailService:Deliver err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) render(to, locale, template string, data any) (mailer.Message, error) {
+	var msg mailer.Message
+
+	tmpl, ok := s.templates[NormalizeLocale(locale)][template]
+	if !ok {
+		return msg, ErrUnknownTemplate
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return msg, fmt.Errorf("MailService:render err -> %s", err.Error())
+	}
+
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return msg, fmt.Errorf("MailService:render err -> %s", err.Error())
+	}
+
+	if err := tmpl.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return msg, fmt.Errorf("MailService:render err -> %s", err.Error())
+	}
+
+	msg = mailer.Message{
+		To:      []string{to},
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    strings.TrimSpace(html.String()),
+	}
+
+	return msg, nil
+}
+
+// NormalizeLocale принимает код языка или значение Accept-Language
+// и возвращает поддерживаемую локаль
+func NormalizeLocale(locale string) string {
+	for _, part := range strings.Split(locale, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		tag = strings.SplitN(tag, "-", 2)[0]
+
+		for _, supported := range Locales {
+			if tag == supported {
+				return supported
+			}
+		}
+	}
+
+	return DefaultLocale
+}
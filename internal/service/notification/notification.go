@@ -0,0 +1,239 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/notification"
+	"nevermore/internal/service/mail"
+	"nevermore/internal/storage"
+	"nevermore/pkg/tracing"
+	"slices"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrUnknownType          = errors.New("unknown notification type")
+)
+
+type Service interface {
+	// Notify доставляет уведомление по каналам, включённым в настройках пользователя
+	Notify(ctx context.Context, userId int, payload model.Payload) error
+	// SendEmail отправляет копию уведомления на почту, настройки вызывающий проверяет сам
+	SendEmail(ctx context.Context, notification model.Notification) error
+	List(ctx context.Context, req dto.NotificationListRequest) (dto.NotificationListResponse, error)
+	MarkRead(ctx context.Context, userId int, id int64) error
+	MarkAllRead(ctx context.Context, userId int) (int64, error)
+	UnreadCount(ctx context.Context, userId int) (int, error)
+	Preferences(ctx context.Context, userId int) ([]model.Preference, error)
+	UpdatePreferences(ctx context.Context, userId int, preferences []model.Preference) ([]model.Preference, error)
+}
+
+type service struct {
+	st   storage.Storage
+	mail mail.Service
+}
+
+func New(st storage.Storage, mail mail.Service) Service {
+	result := &service{
+		st:   st,
+		mail: mail,
+	}
+
+	return result
+}
+
+func (s *service) Notify(ctx context.Context, userId int, payload model.Payload) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.Notify")
+	defer span.End()
+
+	preference, err := s.preference(ctx, userId, payload.Type())
+	if err != nil {
+		return fmt.Errorf("NotificationService:Notify err -> %s", err.Error())
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("NotificationService:Notify err -> %s", err.Error())
+	}
+
+	notification := model.Notification{
+		UserId:  userId,
+		Type:    payload.Type(),
+		Payload: data,
+	}
+
+	if preference.InApp {
+		if err := s.st.DB().Notification().Create(ctx, &notification); err != nil {
+			return fmt.Errorf("NotificationService:Notify err -> %s", err.Error())
+		}
+	}
+
+	if preference.Email {
+		if err := s.SendEmail(ctx, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) SendEmail(ctx context.Context, notification model.Notification) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.SendEmail")
+	defer span.End()
+
+	user, err := s.st.DB().User().Get(ctx, notification.UserId)
+	if err != nil {
+		return fmt.Errorf("NotificationService:SendEmail err -> %s", err.Error())
+	}
+
+	data := mail.NotificationData{
+		Name: user.Name,
+		Type: notification.Type,
+	}
+
+	if err := json.Unmarshal(notification.Payload, &data.Payload); err != nil {
+		return fmt.Errorf("NotificationService:SendEmail err -> %s", err.Error())
+	}
+
+	if err := s.mail.Send(ctx, user.Email, user.Locale, mail.TemplateNotification, data); err != nil {
+		return fmt.Errorf("NotificationService:SendEmail err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) List(ctx context.Context, req dto.NotificationListRequest) (dto.NotificationListResponse, error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.List")
+	defer span.End()
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	result, err := s.st.DB().Notification().List(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("NotificationService:List err -> %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func (s *service) MarkRead(ctx context.Context, userId int, id int64) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.MarkRead")
+	defer span.End()
+
+	err := s.st.DB().Notification().MarkRead(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotificationNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("NotificationService:MarkRead err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) MarkAllRead(ctx context.Context, userId int) (int64, error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.MarkAllRead")
+	defer span.End()
+
+	count, err := s.st.DB().Notification().MarkAllRead(ctx, userId)
+	if err != nil {
+		return 0, fmt.Errorf("NotificationService:MarkAllRead err -> %s", err.Error())
+	}
+
+	return count, nil
+}
+
+func (s *service) UnreadCount(ctx context.Context, userId int) (int, error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.UnreadCount")
+	defer span.End()
+
+	count, err := s.st.DB().Notification().UnreadCount(ctx, userId)
+	if err != nil {
+		return 0, fmt.Errorf("NotificationService:UnreadCount err -> %s", err.Error())
+	}
+
+	return count, nil
+}
+
+// Preferences возвращает настройки по всем типам, подставляя значения по умолчанию
+func (s *service) Preferences(ctx context.Context, userId int) ([]model.Preference, error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.Preferences")
+	defer span.End()
+
+	stored, err := s.st.DB().Notification().Preferences(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("NotificationService:Preferences err -> %s", err.Error())
+	}
+
+	result := make([]model.Preference, 0, len(model.Types))
+	for _, notificationType := range model.Types {
+		preference := model.DefaultPreference(userId, notificationType)
+
+		for _, item := range stored {
+			if item.Type == notificationType {
+				preference = item
+				break
+			}
+		}
+
+		result = append(result, preference)
+	}
+
+	return result, nil
+}
+
+func (s *service) UpdatePreferences(ctx context.Context, userId int, preferences []model.Preference) ([]model.Preference, error) {
+	ctx, span := tracing.Start(ctx, "NotificationService.UpdatePreferences")
+	defer span.End()
+
+	for _, preference := range preferences {
+		if !slices.Contains(model.Types, preference.Type) {
+			return nil, ErrUnknownType
+		}
+	}
+
+	for _, preference := range preferences {
+		preference.UserId = userId
+
+		if err := s.st.DB().Notification().UpsertPreference(ctx, preference); err != nil {
+			return nil, fmt.Errorf("NotificationService:UpdatePreferences err -> %s", err.Error())
+		}
+	}
+
+	return s.Preferences(ctx, userId)
+}
+
+func (s *service) preference(ctx context.Context, userId int, notificationType string) (model.Preference, error) {
+	preferences, err := s.st.DB().Notification().Preferences(ctx, userId)
+	if err != nil {
+		return model.Preference{}, err
+	}
+
+	for _, preference := range preferences {
+		if preference.Type == notificationType {
+			return preference, nil
+		}
+	}
+
+	return model.DefaultPreference(userId, notificationType), nil
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/book"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/postgres/contributor"
+	"nevermore/pkg/tracing"
+	"strings"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Service interface {
+	Catalog(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) Catalog(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "SearchService.Catalog")
+	defer span.End()
+
+	req.Query = strings.TrimSpace(req.Query)
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	result, err := s.st.DB().Search().Catalog(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("SearchService:Catalog err -> %s", err.Error())
+	}
+
+	err = contributor.Attach(ctx, s.st.DB().Contributor(), result.Hits, func(hit *dto.SearchHit) (int, *[]model.Contributor) {
+		return hit.BookId, &hit.Contributors
+	})
+	if err != nil {
+		return result, fmt.Errorf("SearchService:Catalog err -> %s", err.Error())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package series
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"nevermore/internal/dto"
+	bookModel "nevermore/internal/model/book"
+	model "nevermore/internal/model/series"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/internal/storage/postgres/contributor"
+	"nevermore/pkg/tracing"
+	"strings"
+)
+
+// maxVolumes ограничивает число томов, отдаваемых вместе с серией
+const maxVolumes = 500
+
+var (
+	ErrInvalidSeries  = errors.New("series title is required")
+	ErrSeriesNotFound = errors.New("series not found")
+	ErrInvalidVolume  = errors.New("volume must be positive and requires series_id")
+)
+
+type Service interface {
+	List(ctx context.Context) ([]model.Series, error)
+	Get(ctx context.Context, id int) (dto.SeriesResponse, error)
+	Create(ctx context.Context, req dto.CreateSeriesRequest) (model.Series, error)
+	SetBookSeries(ctx context.Context, bookId int, req dto.SetBookSeriesRequest) error
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context) ([]model.Series, error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.List")
+	defer span.End()
+
+	series, err := s.st.DB().Series().List(ctx)
+	if err != nil {
+		return series, fmt.Errorf("SeriesService:List err -> %s", err.Error())
+	}
+
+	return series, nil
+}
+
+// Get возвращает серию вместе с томами, упорядоченными по номеру
+func (s *service) Get(ctx context.Context, id int) (dto.SeriesResponse, error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Get")
+	defer span.End()
+
+	var result dto.SeriesResponse
+
+	series, err := s.st.DB().Series().Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, ErrSeriesNotFound
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("SeriesService:Get err -> %s", err.Error())
+	}
+
+	books, err := s.st.DB().Book().List(ctx, dto.BookListFilter{
+		SeriesId: &id,
+		Limit:    maxVolumes,
+	})
+	if err != nil {
+		return result, fmt.Errorf("SeriesService:Get err -> %s", err.Error())
+	}
+
+	err = contributor.Attach(ctx, s.st.DB().Contributor(), books.Books, func(book *dto.BookListItem) (int, *[]bookModel.Contributor) {
+		return book.Id, &book.Contributors
+	})
+	if err != nil {
+		return result, fmt.Errorf("SeriesService:Get err -> %s", err.Error())
+	}
+
+	result.Series = series
+	result.Books = books.Books
+
+	return result, nil
+}
+
+func (s *service) Create(ctx context.Context, req dto.CreateSeriesRequest) (model.Series, error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Create")
+	defer span.End()
+
+	series := model.Series{
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+	}
+
+	if series.Title == "" {
+		return series, ErrInvalidSeries
+	}
+
+	if err := s.st.DB().Series().Create(ctx, &series); err != nil {
+		return series, fmt.Errorf("SeriesService:Create err -> %s", err.Error())
+	}
+
+	return series, nil
+}
+
+// SetBookSeries привязывает книгу к серии; пустой series_id убирает книгу из серии
+func (s *service) SetBookSeries(ctx context.Context, bookId int, req dto.SetBookSeriesRequest) error {
+	ctx, span := tracing.Start(ctx, "SeriesService.SetBookSeries")
+	defer span.End()
+
+	if req.SeriesId == nil {
+		req.Volume = nil
+	}
+
+	if req.Volume != nil && *req.Volume <= 0 {
+		return ErrInvalidVolume
+	}
+
+	if err := s.st.DB().Book().SetSeries(ctx, bookId, req.SeriesId, req.Volume); err != nil {
+		return fmt.Errorf("SeriesService:SetBookSeries err -> %s", err.Error())
+	}
+
+	cache.Invalidate(ctx, s.st.Cache(), cache.Book(bookId))
+
+	return nil
+}
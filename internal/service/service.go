@@ -1,28 +1,114 @@
 package service
 
 import (
+	"nevermore/internal/service/apitoken"
+	"nevermore/internal/service/auth"
+	"nevermore/internal/service/author"
+	"nevermore/internal/service/book"
+	"nevermore/internal/service/booktext"
+	"nevermore/internal/service/flag"
+	"nevermore/internal/service/follow"
+	"nevermore/internal/service/genre"
+	"nevermore/internal/service/identity"
+	"nevermore/internal/service/job"
+	"nevermore/internal/service/loginguard"
+	"nevermore/internal/service/mail"
+	"nevermore/internal/service/notification"
+	"nevermore/internal/service/search"
+	"nevermore/internal/service/series"
+	"nevermore/internal/service/shelf"
+	"nevermore/internal/service/tag"
+	"nevermore/internal/service/twofactor"
 	"nevermore/internal/service/user"
 	"nevermore/internal/storage"
 
-	"github.com/gammazero/workerpool"
-
+	authManager "nevermore/pkg/auth"
+	"nevermore/pkg/flags"
 	"nevermore/pkg/hash"
+	"nevermore/pkg/jobs"
+	"nevermore/pkg/mailer"
+	"nevermore/pkg/oidc"
 )
 
 type Service interface {
 	User() user.Service
+	Search() search.Service
+	BookText() booktext.Service
+	Book() book.Service
+	Genre() genre.Service
+	Tag() tag.Service
+	Series() series.Service
+	Author() author.Service
+	Follow() follow.Service
+	Notification() notification.Service
+	Mail() mail.Service
+	Auth() auth.Service
+	TwoFactor() twofactor.Service
+	Identity() identity.Service
+	APIToken() apitoken.Service
+	Shelf() shelf.Service
+	Flag() flag.Service
+	Job() job.Service
 }
 
 type service struct {
-	user user.Service
+	user         user.Service
+	search       search.Service
+	bookText     booktext.Service
+	book         book.Service
+	genre        genre.Service
+	tag          tag.Service
+	series       series.Service
+	author       author.Service
+	follow       follow.Service
+	notification notification.Service
+	mail         mail.Service
+	auth         auth.Service
+	twoFactor    twofactor.Service
+	identity     identity.Service
+	apiToken     apitoken.Service
+	shelf        shelf.Service
+	flag         flag.Service
+	job          job.Service
 }
 
 func New(st storage.Storage,
 	hash hash.PasswordHasher,
-	wp *workerpool.WorkerPool) Service {
+	m mailer.Mailer,
+	mailQueue *mailer.Queue,
+	jobQueue *jobs.Queue,
+	tokens authManager.TokenManager,
+	authCfg auth.Config,
+	bookTextCfg booktext.Config,
+	guardCfg loginguard.Config,
+	oidcProviders []oidc.Config,
+	toggles *flags.Toggles) Service {
+
+	mailService := mail.New(m, mailQueue)
+	notificationService := notification.New(st, mailService)
+	twoFactorService := twofactor.New(st, authCfg.TOTPIssuer)
+	guardService := loginguard.New(st, guardCfg)
+	authService := auth.New(st, hash, tokens, mailService, twoFactorService, guardService, jobQueue, authCfg)
 
 	result := &service{
-		user: user.New(st),
+		user:         user.New(st),
+		search:       search.New(st),
+		bookText:     booktext.New(st, jobQueue, bookTextCfg),
+		book:         book.New(st),
+		genre:        genre.New(st),
+		tag:          tag.New(st),
+		series:       series.New(st),
+		author:       author.New(st),
+		follow:       follow.New(st, notificationService),
+		notification: notificationService,
+		mail:         mailService,
+		auth:         authService,
+		twoFactor:    twoFactorService,
+		identity:     identity.New(st, authService, oidcProviders),
+		apiToken:     apitoken.New(st),
+		shelf:        shelf.New(st),
+		flag:         flag.New(st, toggles),
+		job:          job.New(st),
 	}
 
 	return result
@@ -31,3 +117,71 @@ func New(st storage.Storage,
 func (s *service) User() user.Service {
 	return s.user
 }
+
+func (s *service) Search() search.Service {
+	return s.search
+}
+
+func (s *service) BookText() booktext.Service {
+	return s.bookText
+}
+
+func (s *service) Book() book.Service {
+	return s.book
+}
+
+func (s *service) Genre() genre.Service {
+	return s.genre
+}
+
+func (s *service) Tag() tag.Service {
+	return s.tag
+}
+
+func (s *service) Series() series.Service {
+	return s.series
+}
+
+func (s *service) Author() author.Service {
+	return s.author
+}
+
+func (s *service) Follow() follow.Service {
+	return s.follow
+}
+
+func (s *service) Notification() notification.Service {
+	return s.notification
+}
+
+func (s *service) Mail() mail.Service {
+	return s.mail
+}
+
+func (s *service) Auth() auth.Service {
+	return s.auth
+}
+
+func (s *service) TwoFactor() twofactor.Service {
+	return s.twoFactor
+}
+
+func (s *service) Identity() identity.Service {
+	return s.identity
+}
+
+func (s *service) APIToken() apitoken.Service {
+	return s.apiToken
+}
+
+func (s *service) Shelf() shelf.Service {
+	return s.shelf
+}
+
+func (s *service) Flag() flag.Service {
+	return s.flag
+}
+
+func (s *service) Job() job.Service {
+	return s.job
+}
@@ -0,0 +1,223 @@
+package shelf
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/shelf"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	// maxImportItems ограничивает размер одного импорта, большие полки загружаются частями
+	maxImportItems = 500
+
+	minRating = 1
+	maxRating = 10
+)
+
+var (
+	ErrInvalidItem   = errors.New("unknown status, rating must be between 1 and 10 and current page must not be negative")
+	ErrBookNotFound  = errors.New("book not found")
+	ErrItemNotFound  = errors.New("book is not on the shelf")
+	ErrInvalidImport = errors.New("import must contain from 1 to 500 items")
+)
+
+type Service interface {
+	List(ctx context.Context, req dto.ShelfListRequest) (dto.ShelfListResponse, error)
+	// Put добавляет книгу на полку или меняет переданные поля, если она уже там
+	Put(ctx context.Context, userId, bookId int, req dto.ShelfItemRequest) (model.Item, error)
+	Delete(ctx context.Context, userId, bookId int) error
+	// Import применяет Put ко всем книгам в одной транзакции. Неизвестные книги
+	// и некорректные записи пропускаются с указанием причины
+	Import(ctx context.Context, userId int, req dto.ShelfImportRequest) (dto.ShelfImportResponse, error)
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) List(ctx context.Context, req dto.ShelfListRequest) (dto.ShelfListResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShelfService.List")
+	defer span.End()
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	result, err := s.st.DB().Shelf().List(ctx, req)
+	if err != nil {
+		return result, fmt.Errorf("ShelfService:List err -> %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func (s *service) Put(ctx context.Context, userId, bookId int, req dto.ShelfItemRequest) (model.Item, error) {
+	ctx, span := tracing.Start(ctx, "ShelfService.Put")
+	defer span.End()
+
+	statusIds, err := s.st.DB().Shelf().StatusIds(ctx)
+	if err != nil {
+		return model.Item{}, fmt.Errorf("ShelfService:Put err -> %s", err.Error())
+	}
+
+	if !validItem(req, statusIds) {
+		return model.Item{}, ErrInvalidItem
+	}
+
+	err = s.st.DB().Shelf().Upsert(ctx, userId, bookId, req)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return model.Item{}, ErrBookNotFound
+	}
+
+	if err != nil {
+		return model.Item{}, fmt.Errorf("ShelfService:Put err -> %s", err.Error())
+	}
+
+	// Без оценки в запросе она остаётся прежней, и сводка по книге не меняется
+	if req.PersonalRating != nil {
+		cache.Invalidate(ctx, s.st.Cache(), cache.BookRating(bookId))
+	}
+
+	item, err := s.st.DB().Shelf().Get(ctx, userId, bookId)
+	if err != nil {
+		return item, fmt.Errorf("ShelfService:Put err -> %s", err.Error())
+	}
+
+	return item, nil
+}
+
+func (s *service) Delete(ctx context.Context, userId, bookId int) error {
+	ctx, span := tracing.Start(ctx, "ShelfService.Delete")
+	defer span.End()
+
+	err := s.st.DB().Shelf().Delete(ctx, userId, bookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrItemNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("ShelfService:Delete err -> %s", err.Error())
+	}
+
+	cache.Invalidate(ctx, s.st.Cache(), cache.BookRating(bookId))
+
+	return nil
+}
+
+func (s *service) Import(ctx context.Context, userId int, req dto.ShelfImportRequest) (dto.ShelfImportResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShelfService.Import")
+	defer span.End()
+
+	result := dto.ShelfImportResponse{
+		Skipped: make([]dto.ShelfImportSkipped, 0),
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > maxImportItems {
+		return result, ErrInvalidImport
+	}
+
+	bookIds := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		bookIds = append(bookIds, item.BookId)
+	}
+
+	existing, err := s.st.DB().Shelf().ExistingBooks(ctx, bookIds)
+	if err != nil {
+		return result, fmt.Errorf("ShelfService:Import err -> %s", err.Error())
+	}
+
+	statusIds, err := s.st.DB().Shelf().StatusIds(ctx)
+	if err != nil {
+		return result, fmt.Errorf("ShelfService:Import err -> %s", err.Error())
+	}
+
+	tx, err := s.st.DB().BeginTx(ctx)
+	if err != nil {
+		return result, fmt.Errorf("ShelfService:Import err -> %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	rated := make([]string, 0)
+
+	for _, item := range req.Items {
+		if !slices.Contains(existing, item.BookId) {
+			result.Skipped = append(result.Skipped, dto.ShelfImportSkipped{BookId: item.BookId, Reason: ErrBookNotFound.Error()})
+			continue
+		}
+
+		if !validItem(item.ShelfItemRequest, statusIds) {
+			result.Skipped = append(result.Skipped, dto.ShelfImportSkipped{BookId: item.BookId, Reason: ErrInvalidItem.Error()})
+			continue
+		}
+
+		if err := s.st.DB().Shelf().UpsertTx(ctx, tx, userId, item.BookId, item.ShelfItemRequest); err != nil {
+			return result, fmt.Errorf("ShelfService:Import err -> %s", err.Error())
+		}
+
+		result.Imported++
+
+		if item.PersonalRating != nil {
+			rated = append(rated, cache.BookRating(item.BookId))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("ShelfService:Import err -> %s", err.Error())
+	}
+
+	if len(rated) > 0 {
+		cache.Invalidate(ctx, s.st.Cache(), rated...)
+	}
+
+	metrics.Uploads.WithLabelValues(metrics.UploadShelfImport).Inc()
+
+	return result, nil
+}
+
+func validItem(req dto.ShelfItemRequest, statusIds []int) bool {
+	if req.StatusId != nil && !slices.Contains(statusIds, *req.StatusId) {
+		return false
+	}
+
+	if req.PersonalRating != nil && (*req.PersonalRating < minRating || *req.PersonalRating > maxRating) {
+		return false
+	}
+
+	if req.CurrentPage != nil && *req.CurrentPage < 0 {
+		return false
+	}
+
+	return true
+}
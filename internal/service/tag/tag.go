@@ -0,0 +1,124 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	model "nevermore/internal/model/tag"
+	"nevermore/internal/storage"
+	"nevermore/pkg/tracing"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxTagLength = 50
+
+var (
+	ErrInvalidTag    = errors.New("tag name must be 1-50 characters long")
+	ErrTagRejected   = errors.New("tag was rejected by moderators")
+	ErrInvalidStatus = errors.New("status must be approved or rejected")
+	ErrTagNotFound   = errors.New("tag not found")
+)
+
+type Service interface {
+	Approved(ctx context.Context) ([]model.Tag, error)
+	Pending(ctx context.Context) ([]model.Tag, error)
+	GetByBook(ctx context.Context, bookId int) ([]model.Tag, error)
+	Suggest(ctx context.Context, bookId, userId int, name string) (model.Tag, error)
+	Moderate(ctx context.Context, id int, status string, moderatorId int) error
+}
+
+type service struct {
+	st storage.Storage
+}
+
+func New(st storage.Storage) Service {
+	result := &service{
+		st: st,
+	}
+
+	return result
+}
+
+func (s *service) Approved(ctx context.Context) ([]model.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.Approved")
+	defer span.End()
+
+	tags, err := s.st.DB().Tag().ListByStatus(ctx, model.StatusApproved)
+	if err != nil {
+		return tags, fmt.Errorf("TagService:Approved err -> %s", err.Error())
+	}
+
+	return tags, nil
+}
+
+func (s *service) Pending(ctx context.Context) ([]model.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.Pending")
+	defer span.End()
+
+	tags, err := s.st.DB().Tag().ListByStatus(ctx, model.StatusPending)
+	if err != nil {
+		return tags, fmt.Errorf("TagService:Pending err -> %s", err.Error())
+	}
+
+	return tags, nil
+}
+
+func (s *service) GetByBook(ctx context.Context, bookId int) ([]model.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetByBook")
+	defer span.End()
+
+	tags, err := s.st.DB().Tag().GetByBook(ctx, bookId)
+	if err != nil {
+		return tags, fmt.Errorf("TagService:GetByBook err -> %s", err.Error())
+	}
+
+	return tags, nil
+}
+
+// Suggest привязывает тег к книге; новый тег виден в каталоге только после одобрения модератором
+func (s *service) Suggest(ctx context.Context, bookId, userId int, name string) (model.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.Suggest")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTagLength {
+		return model.Tag{}, ErrInvalidTag
+	}
+
+	tag, err := s.st.DB().Tag().Upsert(ctx, name, userId)
+	if err != nil {
+		return tag, fmt.Errorf("TagService:Suggest err -> %s", err.Error())
+	}
+
+	if tag.Status == model.StatusRejected {
+		return tag, ErrTagRejected
+	}
+
+	if err := s.st.DB().Tag().AttachToBook(ctx, bookId, tag.Id, userId); err != nil {
+		return tag, fmt.Errorf("TagService:Suggest err -> %s", err.Error())
+	}
+
+	return tag, nil
+}
+
+func (s *service) Moderate(ctx context.Context, id int, status string, moderatorId int) error {
+	ctx, span := tracing.Start(ctx, "TagService.Moderate")
+	defer span.End()
+
+	if status != model.StatusApproved && status != model.StatusRejected {
+		return ErrInvalidStatus
+	}
+
+	err := s.st.DB().Tag().Moderate(ctx, id, status, moderatorId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTagNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("TagService:Moderate err -> %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/twofactor"
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/storage"
+	"nevermore/pkg/auth"
+	"nevermore/pkg/totp"
+	"nevermore/pkg/tracing"
+)
+
+const (
+	defaultIssuer = "Nevermore"
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+var (
+	ErrAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrNotEnrolled       = errors.New("start two-factor enrollment first")
+	ErrNotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode       = errors.New("invalid two-factor code")
+	ErrRequiredByPolicy  = errors.New("two-factor authentication is required for your role")
+	ErrInvalidPolicyRole = errors.New("role must be admin, moderator or user")
+)
+
+var (
+	recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+	policyRoles      = []string{userModel.RoleAdmin, userModel.RoleModerator, userModel.RoleUser}
+)
+
+type Service interface {
+	Status(ctx context.Context, userId int) (dto.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userId int) (dto.TwoFactorEnrollResponse, error)
+	// Confirm включает 2FA по первому коду из приложения и выдаёт коды восстановления
+	Confirm(ctx context.Context, userId int, code string) ([]string, error)
+	Disable(ctx context.Context, userId int, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId int, code string) ([]string, error)
+	// Check принимает код из приложения или одноразовый код восстановления
+	Check(ctx context.Context, userId int, code string) error
+	// Satisfied сообщает, выполнил ли пользователь требование 2FA для своей роли
+	Satisfied(ctx context.Context, userId int) (bool, error)
+
+	Policies(ctx context.Context) ([]model.Policy, error)
+	SetPolicy(ctx context.Context, adminId int, req dto.SetTwoFactorPolicyRequest) ([]model.Policy, error)
+}
+
+type service struct {
+	st     storage.Storage
+	issuer string
+}
+
+func New(st storage.Storage, issuer string) Service {
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+
+	result := &service{
+		st:     st,
+		issuer: issuer,
+	}
+
+	return result
+}
+
+func (s *service) Status(ctx context.Context, userId int) (dto.TwoFactorStatus, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Status")
+	defer span.End()
+
+	var result dto.TwoFactorStatus
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return result, fmt.Errorf("TwoFactorService:Status err -> %s", err.Error())
+	}
+
+	result.Enabled = user.TotpEnabledAt != nil
+
+	result.Required, err = s.st.DB().TwoFactor().IsRequired(ctx, user.Role)
+	if err != nil {
+		return result, fmt.Errorf("TwoFactorService:Status err -> %s", err.Error())
+	}
+
+	result.RecoveryCodesLeft, err = s.st.DB().TwoFactor().RecoveryCodesLeft(ctx, userId)
+	if err != nil {
+		return result, fmt.Errorf("TwoFactorService:Status err -> %s", err.Error())
+	}
+
+	return result, nil
+}
+
+func (s *service) Enroll(ctx context.Context, userId int) (dto.TwoFactorEnrollResponse, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Enroll")
+	defer span.End()
+
+	var result dto.TwoFactorEnrollResponse
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return result, fmt.Errorf("TwoFactorService:Enroll err -> %s", err.Error())
+	}
+
+	if user.TotpEnabledAt != nil {
+		return result, ErrAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return result, fmt.Errorf("TwoFactorService:Enroll err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().TwoFactor().SetPendingSecret(ctx, userId, secret); err != nil {
+		return result, fmt.Errorf("TwoFactorService:Enroll err -> %s", err.Error())
+	}
+
+	result.Secret = secret
+	result.URI = totp.URI(s.issuer, user.Email, secret)
+
+	return result, nil
+}
+
+func (s *service) Confirm(ctx context.Context, userId int, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Confirm")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorService:Confirm err -> %s", err.Error())
+	}
+
+	if user.TotpEnabledAt != nil {
+		return nil, ErrAlreadyEnabled
+	}
+
+	if user.TotpSecret == nil {
+		return nil, ErrNotEnrolled
+	}
+
+	step, ok := totp.Validate(*user.TotpSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorService:Confirm err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().TwoFactor().Enable(ctx, userId, step, hashes); err != nil {
+		return nil, fmt.Errorf("TwoFactorService:Confirm err -> %s", err.Error())
+	}
+
+	return codes, nil
+}
+
+func (s *service) Disable(ctx context.Context, userId int, code string) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("TwoFactorService:Disable err -> %s", err.Error())
+	}
+
+	required, err := s.st.DB().TwoFactor().IsRequired(ctx, user.Role)
+	if err != nil {
+		return fmt.Errorf("TwoFactorService:Disable err -> %s", err.Error())
+	}
+
+	if required {
+		return ErrRequiredByPolicy
+	}
+
+	if err := s.Check(ctx, userId, code); err != nil {
+		return err
+	}
+
+	if err := s.st.DB().TwoFactor().Disable(ctx, userId); err != nil {
+		return fmt.Errorf("TwoFactorService:Disable err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, userId int, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	if err := s.Check(ctx, userId, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorService:RegenerateRecoveryCodes err -> %s", err.Error())
+	}
+
+	if err := s.st.DB().TwoFactor().ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, fmt.Errorf("TwoFactorService:RegenerateRecoveryCodes err -> %s", err.Error())
+	}
+
+	return codes, nil
+}
+
+func (s *service) Check(ctx context.Context, userId int, code string) error {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Check")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("TwoFactorService:Check err -> %s", err.Error())
+	}
+
+	if user.TotpEnabledAt == nil || user.TotpSecret == nil {
+		return ErrNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(*user.TotpSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidCode
+		}
+
+		fresh, err := s.st.DB().TwoFactor().UseStep(ctx, userId, step)
+		if err != nil {
+			return fmt.Errorf("TwoFactorService:Check err -> %s", err.Error())
+		}
+
+		if !fresh {
+			return ErrInvalidCode
+		}
+
+		return nil
+	}
+
+	err = s.st.DB().TwoFactor().UseRecoveryCode(ctx, userId, auth.HashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidCode
+	}
+
+	if err != nil {
+		return fmt.Errorf("TwoFactorService:Check err -> %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) Satisfied(ctx context.Context, userId int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Satisfied")
+	defer span.End()
+
+	user, err := s.st.DB().User().GetById(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("TwoFactorService:Satisfied err -> %s", err.Error())
+	}
+
+	if user.TotpEnabledAt != nil {
+		return true, nil
+	}
+
+	required, err := s.st.DB().TwoFactor().IsRequired(ctx, user.Role)
+	if err != nil {
+		return false, fmt.Errorf("TwoFactorService:Satisfied err -> %s", err.Error())
+	}
+
+	return !required, nil
+}
+
+func (s *service) Policies(ctx context.Context) ([]model.Policy, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Policies")
+	defer span.End()
+
+	stored, err := s.st.DB().TwoFactor().Policies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorService:Policies err -> %s", err.Error())
+	}
+
+	// Роли без записи в таблице показываем с выключенным требованием
+	result := make([]model.Policy, 0, len(policyRoles))
+	for _, role := range policyRoles {
+		policy := model.Policy{Role: role}
+
+		for _, item := range stored {
+			if item.Role == role {
+				policy = item
+				break
+			}
+		}
+
+		result = append(result, policy)
+	}
+
+	return result, nil
+}
+
+func (s *service) SetPolicy(ctx context.Context, adminId int, req dto.SetTwoFactorPolicyRequest) ([]model.Policy, error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.SetPolicy")
+	defer span.End()
+
+	if !slices.Contains(policyRoles, req.Role) {
+		return nil, ErrInvalidPolicyRole
+	}
+
+	policy := model.Policy{
+		Role:      req.Role,
+		Required:  req.Required,
+		UpdatedBy: &adminId,
+	}
+
+	if err := s.st.DB().TwoFactor().SetPolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("TwoFactorService:SetPolicy err -> %s", err.Error())
+	}
+
+	return s.Policies(ctx)
+}
+
+// newRecoveryCodes возвращает коды для показа пользователю и их хэши для хранения
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))[:recoveryCodeLength]
+
+		codes = append(codes, raw[:recoveryCodeLength/2]+"-"+raw[recoveryCodeLength/2:])
+		hashes = append(hashes, auth.HashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+
+	return strings.ReplaceAll(code, " ", "")
+}
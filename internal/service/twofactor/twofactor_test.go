@@ -0,0 +1,229 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/postgres"
+	twoFactorRepo "nevermore/internal/storage/postgres/twofactor"
+	userRepo "nevermore/internal/storage/postgres/user"
+	"nevermore/pkg/totp"
+)
+
+// Фейки встраивают интерфейсы и переопределяют только то, что вызывает Check;
+// остальные методы паникуют на nil, если тест их случайно заденет
+
+type fakeStorage struct {
+	storage.Storage
+	db *fakeDB
+}
+
+func (s *fakeStorage) DB() postgres.Repo { return s.db }
+
+type fakeDB struct {
+	postgres.Repo
+	users     *fakeUsers
+	twoFactor *fakeTwoFactor
+}
+
+func (d *fakeDB) User() userRepo.Repo           { return d.users }
+func (d *fakeDB) TwoFactor() twoFactorRepo.Repo { return d.twoFactor }
+
+type fakeUsers struct {
+	userRepo.Repo
+	user userModel.User
+}
+
+func (u *fakeUsers) GetById(ctx context.Context, id int) (userModel.User, error) {
+	return u.user, nil
+}
+
+// fakeTwoFactor повторяет условия из SQL: шаг принимается, только если он больше последнего,
+// а код восстановления — только пока он не использован
+type fakeTwoFactor struct {
+	twoFactorRepo.Repo
+	lastStep *int64
+	unused   map[string]bool
+}
+
+func (f *fakeTwoFactor) UseStep(ctx context.Context, userId int, step int64) (bool, error) {
+	if f.lastStep != nil && *f.lastStep >= step {
+		return false, nil
+	}
+
+	f.lastStep = &step
+
+	return true, nil
+}
+
+func (f *fakeTwoFactor) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	if !f.unused[codeHash] {
+		return sql.ErrNoRows
+	}
+
+	delete(f.unused, codeHash)
+
+	return nil
+}
+
+func newTestService(t *testing.T, recoveryHashes []string) (*service, string) {
+	t.Helper()
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabledAt := time.Now()
+	unused := make(map[string]bool, len(recoveryHashes))
+	for _, hash := range recoveryHashes {
+		unused[hash] = true
+	}
+
+	st := &fakeStorage{db: &fakeDB{
+		users:     &fakeUsers{user: userModel.User{Id: 1, TotpSecret: &secret, TotpEnabledAt: &enabledAt}},
+		twoFactor: &fakeTwoFactor{unused: unused},
+	}}
+
+	return &service{st: st, issuer: defaultIssuer}, secret
+}
+
+// currentCode считает код приложения независимо от pkg/totp — по RFC 6238
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := recoveryEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(time.Now().Unix()/int64(totp.Period.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestCheckRejectsReplayedStep(t *testing.T) {
+	ctx := context.Background()
+	s, secret := newTestService(t, nil)
+
+	code := currentCode(t, secret)
+
+	if err := s.Check(ctx, 1, code); err != nil {
+		t.Fatalf("first Check = %v, want nil", err)
+	}
+
+	if err := s.Check(ctx, 1, code); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("replayed Check = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestCheckRejectsWrongCode(t *testing.T) {
+	s, secret := newTestService(t, nil)
+
+	code := currentCode(t, secret)
+	// Меняем последнюю цифру
+	wrong := code[:5] + string('0'+(code[5]-'0'+1)%10)
+
+	if err := s.Check(context.Background(), 1, wrong); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Check = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestCheckNotEnabled(t *testing.T) {
+	s, _ := newTestService(t, nil)
+	s.st.(*fakeStorage).db.users.user.TotpEnabledAt = nil
+
+	if err := s.Check(context.Background(), 1, "123456"); !errors.Is(err, ErrNotEnabled) {
+		t.Fatalf("Check = %v, want ErrNotEnabled", err)
+	}
+}
+
+func TestCheckRecoveryCodeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := newTestService(t, hashes)
+
+	// Пользователь может ввести код заглавными, с пробелами и без дефиса
+	typed := " " + strings.ToUpper(strings.Replace(codes[0], "-", " ", 1)) + " "
+
+	if err := s.Check(ctx, 1, typed); err != nil {
+		t.Fatalf("first Check = %v, want nil", err)
+	}
+
+	if err := s.Check(ctx, 1, codes[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("reused Check = %v, want ErrInvalidCode", err)
+	}
+
+	if err := s.Check(ctx, 1, codes[1]); err != nil {
+		t.Fatalf("other code Check = %v, want nil", err)
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Errorf("code %q is not in xxxxx-xxxxx form", code)
+		}
+
+		if len(normalizeRecoveryCode(code)) == totp.Digits {
+			t.Errorf("code %q would be taken for an app code", code)
+		}
+
+		if seen[code] {
+			t.Errorf("code %q repeats", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"abcde-fghij", "abcdefghij"},
+		{"ABCDE-FGHIJ", "abcdefghij"},
+		{"abcde fghij", "abcdefghij"},
+		{"ab-cd e-FG hij", "abcdefghij"},
+		{"abcdefghij", "abcdefghij"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeRecoveryCode(tt.in); got != tt.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -2,15 +2,26 @@ package user
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"nevermore/internal/dto"
-	model "nevermore/internal/model/user"
+	"nevermore/internal/service/mail"
 	"nevermore/internal/storage"
+	"nevermore/internal/storage/cache"
+	"nevermore/pkg/tracing"
+	"time"
 )
 
+// profileTTL — профиль читается на каждом запросе с проверкой роли или лимита,
+// а меняется редко и всегда через инвалидацию
+const profileTTL = 5 * time.Minute
+
+var ErrUserNotFound = errors.New("user not found")
+
 type Service interface {
 	Get(ctx context.Context, userId int) (*dto.UserGetResponse, error)
-	Update(ctx context.Context, user model.User) error
+	Update(ctx context.Context, userId int, req dto.UserUpdateRequest) error
 	Delete(ctx context.Context, userId int) error
 }
 
@@ -27,7 +38,17 @@ func New(st storage.Storage) Service {
 }
 
 func (s *service) Get(ctx context.Context, userId int) (*dto.UserGetResponse, error) {
-	user, err := s.st.DB().User().Get(ctx, userId)
+	ctx, span := tracing.Start(ctx, "UserService.Get")
+	defer span.End()
+
+	user, err := cache.Fetch(ctx, s.st.Cache(), cache.UserProfile(userId), profileTTL,
+		func(ctx context.Context) (*dto.UserGetResponse, error) {
+			return s.st.DB().User().Get(ctx, userId)
+		})
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, ErrUserNotFound
+	}
+
 	if err != nil {
 		return user, fmt.Errorf("UserService:Get err -> %s", err.Error())
 	}
@@ -35,22 +56,37 @@ func (s *service) Get(ctx context.Context, userId int) (*dto.UserGetResponse, er
 	return user, nil
 }
 
-func (s *service) Update(ctx context.Context, user model.User) error {
+func (s *service) Update(ctx context.Context, userId int, req dto.UserUpdateRequest) error {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
 	var err error
 
-	err = s.st.DB().User().Update(ctx, user)
+	if req.Locale != nil {
+		locale := mail.NormalizeLocale(*req.Locale)
+		req.Locale = &locale
+	}
+
+	err = s.st.DB().User().Update(ctx, userId, req)
 	if err != nil {
 		return fmt.Errorf("UserService:Update err -> %s", err.Error())
 	}
 
+	cache.Invalidate(ctx, s.st.Cache(), cache.UserProfile(userId))
+
 	return nil
 }
 
 func (s *service) Delete(ctx context.Context, userId int) error {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
 	err := s.st.DB().User().Delete(ctx, userId)
 	if err != nil {
 		return fmt.Errorf("UserService:Delete err -> %s", err.Error())
 	}
 
+	cache.Invalidate(ctx, s.st.Cache(), cache.UserProfile(userId))
+
 	return nil
 }
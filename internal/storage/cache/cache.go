@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"nevermore/pkg/logger"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// loadTimeout ограничивает общую загрузку: она не должна зависеть от отмены запроса,
+// который её начал, потому что результата ждут и другие запросы
+const loadTimeout = 10 * time.Second
+
+// Cache хранит значения в JSON, поэтому Redis и память ведут себя одинаково:
+// получатель всегда работает с копией
+type Cache interface {
+	// Get заполняет dest и возвращает false, если ключа нет или он истёк
+	Get(ctx context.Context, key string, dest any) (bool, error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+var (
+	// loads объединяет одновременные промахи по одному ключу в одну загрузку
+	loads singleflight.Group
+	// invalidations растёт с каждым Invalidate. Загрузка, во время которой он изменился,
+	// могла прочитать данные до изменения, и её запись в кэш убирается
+	invalidations atomic.Uint64
+)
+
+// Fetch возвращает значение из кэша или загружает его через load и кладёт в кэш.
+// Ошибки кэша не мешают ответу: значение просто загружается из базы
+func Fetch[T any](ctx context.Context, c Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var cached T
+
+	found, err := c.Get(ctx, key, &cached)
+	if err != nil {
+		logError(ctx, "Get", key, err)
+	}
+
+	if found {
+		return cached, nil
+	}
+
+	value, err, _ := loads.Do(key, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		generation := invalidations.Load()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			return value, err
+		}
+
+		if err := c.Set(loadCtx, key, value, ttl); err != nil {
+			logError(loadCtx, "Set", key, err)
+		}
+
+		// Проверка после записи: Invalidate, начавшийся до неё, сюда уже дошёл,
+		// а начавшийся после — сам удалит записанное
+		if invalidations.Load() != generation {
+			if err := c.Delete(loadCtx, key); err != nil {
+				logError(loadCtx, "Delete", key, err)
+			}
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return value.(T), nil
+}
+
+// Invalidate удаляет ключи после изменения данных. Идущая загрузка забывается,
+// чтобы следующие запросы не присоединялись к чтению, начатому до изменения, а её
+// запись в кэш убирает сама Fetch. Загрузку на другом экземпляре отсюда не видно,
+// поэтому ключи удаляются ещё раз, когда любая начатая загрузка уже завершилась
+func Invalidate(ctx context.Context, c Cache, keys ...string) {
+	invalidations.Add(1)
+
+	for _, key := range keys {
+		loads.Forget(key)
+	}
+
+	if err := c.Delete(ctx, keys...); err != nil {
+		logError(ctx, "Delete", "", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	time.AfterFunc(loadTimeout, func() {
+		if err := c.Delete(ctx, keys...); err != nil {
+			logError(ctx, "Delete", "", err)
+		}
+	})
+}
+
+func logError(ctx context.Context, method, key string, err error) {
+	log := logger.NamedFromContext(ctx, "cache")
+	log.Error().Err(err).Str("method", method).Str("key", key).Msg("cache is unavailable")
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCachesLoadedValue(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	loads := 0
+	load := func(context.Context) (string, error) {
+		loads++
+		return "value", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := Fetch(ctx, c, "cached", time.Minute, load)
+		if err != nil || value != "value" {
+			t.Fatalf("Fetch = %q, %v", value, err)
+		}
+	}
+
+	if loads != 1 {
+		t.Fatalf("loaded %d times, want 1", loads)
+	}
+}
+
+// Загрузка прочитала данные до изменения, а Invalidate пришёл, пока она шла:
+// устаревшее значение не должно остаться в кэше
+func TestInvalidateDuringLoadDropsStaleValue(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		value, err := Fetch(ctx, c, "profile", time.Minute, func(context.Context) (string, error) {
+			close(started)
+			<-release
+			return "old", nil
+		})
+		if err != nil || value != "old" {
+			t.Errorf("in-flight Fetch = %q, %v", value, err)
+		}
+	}()
+
+	<-started
+	Invalidate(ctx, c, "profile")
+	close(release)
+	<-done
+
+	value, err := Fetch(ctx, c, "profile", time.Minute, func(context.Context) (string, error) {
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != "new" {
+		t.Fatalf("Fetch after Invalidate = %q, want %q", value, "new")
+	}
+}
+
+func TestInvalidateDeletesCachedValue(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory()
+
+	if err := c.Set(ctx, "book", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	Invalidate(ctx, c, "book")
+
+	var value string
+	if found, _ := c.Get(ctx, "book", &value); found {
+		t.Fatalf("key still cached as %q", value)
+	}
+}
@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"strconv"
+)
+
+func UserProfile(userId int) string {
+	return "user:profile:" + strconv.Itoa(userId)
+}
+
+func Book(bookId int) string {
+	return "book:detail:" + strconv.Itoa(bookId)
+}
+
+func BookRating(bookId int) string {
+	return "book:rating:" + strconv.Itoa(bookId)
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// sweepInterval — как часто выбрасывать истёкшие записи
+const sweepInterval = time.Minute
+
+type entry struct {
+	data    []byte
+	expires time.Time
+}
+
+// memoryCache — кэш в памяти процесса для одного экземпляра и локальной разработки.
+// Между экземплярами он не согласован, инвалидация действует только на свой процесс
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+func NewMemory() Cache {
+	result := &memoryCache{
+		entries:   make(map[string]entry),
+		lastSweep: time.Now(),
+	}
+
+	return result
+}
+
+func (m *memoryCache) Get(_ context.Context, key string, dest any) (bool, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	e, exists := m.entries[key]
+	m.mu.Unlock()
+
+	if !exists || !now.Before(e.expires) {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(e.data, dest); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (m *memoryCache) Set(_ context.Context, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+
+		m.lastSweep = now
+	}
+
+	m.entries[key] = entry{data: data, expires: now.Add(ttl)}
+
+	return nil
+}
+
+func (m *memoryCache) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+
+	return nil
+}
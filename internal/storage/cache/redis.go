@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "cache:"
+
+type redisCache struct {
+	client *goredis.Client
+}
+
+func NewRedis(client *goredis.Client) Cache {
+	result := &redisCache{
+		client: client,
+	}
+
+	return result
+}
+
+func (r *redisCache) Get(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := r.client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, keyPrefix+key, data, ttl).Err()
+}
+
+func (r *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, keyPrefix+key)
+	}
+
+	return r.client.Del(ctx, prefixed...).Err()
+}
@@ -0,0 +1,122 @@
+package apitoken
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/apitoken"
+)
+
+// touchInterval — как часто обновлять время последнего использования,
+// чтобы скрипт с частыми запросами не писал в базу на каждый из них
+const touchInterval = time.Minute
+
+type Repo interface {
+	List(ctx context.Context, userId int) ([]model.Token, error)
+	Count(ctx context.Context, userId int) (int, error)
+	Create(ctx context.Context, token *model.Token) error
+	Delete(ctx context.Context, userId int, id int64) error
+	GetActive(ctx context.Context, tokenHash string) (model.Token, error)
+	Touch(ctx context.Context, id int64) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) List(ctx context.Context, userId int) ([]model.Token, error) {
+	tokens := make([]model.Token, 0)
+
+	query := `select id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at
+			  from api_tokens
+			  where user_id = $1
+			  order by created_at desc, id desc`
+
+	err := r.db.SelectContext(ctx, &tokens, query, userId)
+
+	return tokens, err
+}
+
+func (r *repo) Count(ctx context.Context, userId int) (int, error) {
+	var count int
+
+	err := r.db.GetContext(ctx, &count, "select count(*) from api_tokens where user_id = $1", userId)
+
+	return count, err
+}
+
+func (r *repo) Create(ctx context.Context, token *model.Token) error {
+	query := `insert into api_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+			  values ($1, $2, $3, $4, $5, $6)
+			  returning id, created_at`
+
+	return r.db.QueryRowxContext(
+		ctx,
+		query,
+		token.UserId,
+		token.Name,
+		token.TokenPrefix,
+		token.TokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+	).Scan(&token.Id, &token.CreatedAt)
+}
+
+func (r *repo) Delete(ctx context.Context, userId int, id int64) error {
+	res, err := r.db.ExecContext(ctx, "delete from api_tokens where id = $1 and user_id = $2", id, userId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetActive ищет действующий токен по хэшу. Просроченные токены и токены
+// удалённых пользователей дают sql.ErrNoRows
+func (r *repo) GetActive(ctx context.Context, tokenHash string) (model.Token, error) {
+	var token model.Token
+
+	query := `select t.id, t.user_id, t.name, t.token_prefix, t.token_hash, t.scopes,
+			         t.expires_at, t.last_used_at, t.created_at
+			  from api_tokens t
+			  join users u on u.id = t.user_id
+			  where t.token_hash = $1
+			    and (t.expires_at is null or t.expires_at > $2)
+			    and u.deleted_at is null`
+
+	err := r.db.GetContext(ctx, &token, query, tokenHash, time.Now())
+
+	return token, err
+}
+
+func (r *repo) Touch(ctx context.Context, id int64) error {
+	query := `update api_tokens
+			  set last_used_at = $1
+			  where id = $2 and (last_used_at is null or last_used_at < $3)`
+
+	now := time.Now()
+
+	_, err := r.db.ExecContext(ctx, query, now, id, now.Add(-touchInterval))
+
+	return err
+}
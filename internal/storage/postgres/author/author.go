@@ -0,0 +1,265 @@
+package author
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/author"
+)
+
+type Repo interface {
+	List(ctx context.Context, limit, offset int) ([]model.Author, error)
+	Get(ctx context.Context, id int) (model.Author, error)
+	Create(ctx context.Context, author *model.Author) error
+	Update(ctx context.Context, author model.Author) error
+	Delete(ctx context.Context, id int) error
+	HasBooks(ctx context.Context, id int) (bool, error)
+
+	Aliases(ctx context.Context, authorId int) ([]model.Alias, error)
+	AddAlias(ctx context.Context, alias *model.Alias) error
+	DeleteAlias(ctx context.Context, authorId, aliasId int) error
+
+	// Методы для слияния дубликатов выполняются в транзакции вызывающего
+	LockTx(ctx context.Context, tx *sqlx.Tx, id int) (model.Author, error)
+	FillMissingTx(ctx context.Context, tx *sqlx.Tx, targetId, sourceId int, birthDate, deathDate *time.Time) error
+	ReassignBooksTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error
+	ReassignFollowersTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error
+	ReassignAliasesTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error
+	AddAliasTx(ctx context.Context, tx *sqlx.Tx, alias model.Alias) error
+	DeleteTx(ctx context.Context, tx *sqlx.Tx, id int) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) List(ctx context.Context, limit, offset int) ([]model.Author, error) {
+	authors := make([]model.Author, 0)
+
+	query := `select id, name, biography, photo_url, birth_date, death_date, created_at, updated_at
+			  from authors
+			  order by name, id
+			  limit $1 offset $2`
+
+	err := r.db.SelectContext(ctx, &authors, query, limit, offset)
+
+	return authors, err
+}
+
+func (r *repo) Get(ctx context.Context, id int) (model.Author, error) {
+	var author model.Author
+
+	query := `select id, name, biography, photo_url, birth_date, death_date, created_at, updated_at
+			  from authors
+			  where id = $1`
+
+	err := r.db.GetContext(ctx, &author, query, id)
+
+	return author, err
+}
+
+func (r *repo) Create(ctx context.Context, author *model.Author) error {
+	query := `insert into authors (name, biography, photo_url, birth_date, death_date)
+			  values ($1, $2, $3, $4, $5)
+			  returning id, created_at, updated_at`
+
+	return r.db.QueryRowxContext(
+		ctx,
+		query,
+		author.Name,
+		author.Biography,
+		author.PhotoUrl,
+		author.BirthDate,
+		author.DeathDate,
+	).Scan(&author.Id, &author.CreatedAt, &author.UpdatedAt)
+}
+
+func (r *repo) Update(ctx context.Context, author model.Author) error {
+	query := `update authors
+			  set name = $1,
+			      biography = $2,
+			      photo_url = $3,
+			      birth_date = $4,
+			      death_date = $5,
+			      updated_at = $6
+			  where id = $7`
+
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		author.Name,
+		author.Biography,
+		author.PhotoUrl,
+		author.BirthDate,
+		author.DeathDate,
+		time.Now(),
+		author.Id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *repo) Delete(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "delete from authors where id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *repo) HasBooks(ctx context.Context, id int) (bool, error) {
+	var exists bool
+
+	err := r.db.GetContext(ctx, &exists, "select exists(select 1 from book_contributors where author_id = $1)", id)
+
+	return exists, err
+}
+
+func (r *repo) Aliases(ctx context.Context, authorId int) ([]model.Alias, error) {
+	aliases := make([]model.Alias, 0)
+
+	query := `select id, author_id, name, kind, locale, created_at
+			  from author_aliases
+			  where author_id = $1
+			  order by kind, name`
+
+	err := r.db.SelectContext(ctx, &aliases, query, authorId)
+
+	return aliases, err
+}
+
+func (r *repo) AddAlias(ctx context.Context, alias *model.Alias) error {
+	query := `insert into author_aliases (author_id, name, kind, locale)
+			  values ($1, $2, $3, $4)
+			  returning id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query, alias.AuthorId, alias.Name, alias.Kind, alias.Locale).
+		Scan(&alias.Id, &alias.CreatedAt)
+}
+
+func (r *repo) DeleteAlias(ctx context.Context, authorId, aliasId int) error {
+	res, err := r.db.ExecContext(ctx, "delete from author_aliases where id = $1 and author_id = $2", aliasId, authorId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *repo) LockTx(ctx context.Context, tx *sqlx.Tx, id int) (model.Author, error) {
+	var author model.Author
+
+	query := `select id, name, biography, photo_url, birth_date, death_date, created_at, updated_at
+			  from authors
+			  where id = $1
+			  for update`
+
+	err := tx.GetContext(ctx, &author, query, id)
+
+	return author, err
+}
+
+// FillMissingTx дополняет пустые поля целевого автора данными дубликата. Даты жизни
+// не дополняются по одной, а записываются парой, которую выбрал вызывающий
+func (r *repo) FillMissingTx(ctx context.Context, tx *sqlx.Tx, targetId, sourceId int, birthDate, deathDate *time.Time) error {
+	query := `update authors t
+			  set biography = coalesce(t.biography, s.biography),
+			      photo_url = coalesce(t.photo_url, s.photo_url),
+			      birth_date = $3,
+			      death_date = $4,
+			      updated_at = $5
+			  from authors s
+			  where t.id = $1 and s.id = $2`
+
+	_, err := tx.ExecContext(ctx, query, targetId, sourceId, birthDate, deathDate, time.Now())
+
+	return err
+}
+
+// ReassignBooksTx переносит участие в книгах, не дублируя роли, которые уже есть у целевого автора
+func (r *repo) ReassignBooksTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error {
+	query := `insert into book_contributors (book_id, author_id, role, position)
+			  select book_id, $1, role, position from book_contributors where author_id = $2
+			  on conflict do nothing`
+
+	if _, err := tx.ExecContext(ctx, query, toId, fromId); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "delete from book_contributors where author_id = $1", fromId)
+
+	return err
+}
+
+// ReassignFollowersTx переносит подписки, не дублируя уже подписанных на целевого автора
+func (r *repo) ReassignFollowersTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error {
+	query := `insert into saved_authors (user_id, author_id, created_at)
+			  select user_id, $1, created_at from saved_authors where author_id = $2
+			  on conflict do nothing`
+
+	if _, err := tx.ExecContext(ctx, query, toId, fromId); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "delete from saved_authors where author_id = $1", fromId)
+
+	return err
+}
+
+func (r *repo) ReassignAliasesTx(ctx context.Context, tx *sqlx.Tx, fromId, toId int) error {
+	query := `update author_aliases s
+			  set author_id = $1
+			  where s.author_id = $2
+			    and not exists (
+			        select 1 from author_aliases t
+			        where t.author_id = $1 and lower(t.name) = lower(s.name)
+			    )`
+
+	_, err := tx.ExecContext(ctx, query, toId, fromId)
+
+	return err
+}
+
+func (r *repo) AddAliasTx(ctx context.Context, tx *sqlx.Tx, alias model.Alias) error {
+	query := `insert into author_aliases (author_id, name, kind, locale)
+			  values ($1, $2, $3, $4)
+			  on conflict do nothing`
+
+	_, err := tx.ExecContext(ctx, query, alias.AuthorId, alias.Name, alias.Kind, alias.Locale)
+
+	return err
+}
+
+func (r *repo) DeleteTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	_, err := tx.ExecContext(ctx, "delete from authors where id = $1", id)
+
+	return err
+}
+
+func checkAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/book"
+	"nevermore/internal/storage/postgres/genre"
+)
+
+type Repo interface {
+	Get(ctx context.Context, id int) (model.Book, error)
+	List(ctx context.Context, filter dto.BookListFilter) (dto.BookListResponse, error)
+	SetSeries(ctx context.Context, bookId int, seriesId, volume *int) error
+	Rating(ctx context.Context, bookId int) (dto.BookRating, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) Get(ctx context.Context, id int) (model.Book, error) {
+	var book model.Book
+
+	query := `select id, title, description, cover_image_url, file_url, uploaded_by,
+			         series_id, series_volume, created_at, updated_at
+			  from books
+			  where id = $1`
+
+	err := r.db.GetContext(ctx, &book, query, id)
+
+	return book, err
+}
+
+func (r *repo) List(ctx context.Context, filter dto.BookListFilter) (dto.BookListResponse, error) {
+	var result dto.BookListResponse
+
+	var (
+		conditions []string
+		args       []any
+	)
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.GenreId != nil {
+		// Жанр включает все вложенные поджанры
+		conditions = append(conditions, `exists (
+				select 1 from book_genres bg
+				where bg.book_id = b.id and bg.genre_id in (`+genre.Subtree(arg(*filter.GenreId))+`)
+			)`)
+	}
+
+	if filter.Tag != "" {
+		conditions = append(conditions, `exists (
+				select 1 from book_tags bt
+				join tags t on t.id = bt.tag_id
+				where bt.book_id = b.id and t.status = 'approved' and lower(t.name) = lower(`+arg(filter.Tag)+`)
+			)`)
+	}
+
+	if filter.SeriesId != nil {
+		conditions = append(conditions, "b.series_id = "+arg(*filter.SeriesId))
+	}
+
+	if filter.AuthorId != nil {
+		conditions = append(conditions, `exists (
+				select 1 from book_contributors bc
+				where bc.book_id = b.id and bc.author_id = `+arg(*filter.AuthorId)+`
+			)`)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "where " + strings.Join(conditions, " and ")
+	}
+
+	orderBy := "b.created_at desc, b.id desc"
+	if filter.SeriesId != nil {
+		orderBy = "b.series_volume nulls last, b.id"
+	}
+
+	countQuery := "select count(*) from books b " + where
+
+	if err := r.db.GetContext(ctx, &result.Total, countQuery, args...); err != nil {
+		return result, err
+	}
+
+	query := `select b.id, b.title, b.cover_image_url, b.series_id, b.series_volume
+			  from books b
+			  ` + where + `
+			  order by ` + orderBy + `
+			  limit ` + arg(filter.Limit) + ` offset ` + arg(filter.Offset)
+
+	books := make([]dto.BookListItem, 0)
+	if err := r.db.SelectContext(ctx, &books, query, args...); err != nil {
+		return result, err
+	}
+
+	result.Books = books
+
+	return result, nil
+}
+
+func (r *repo) SetSeries(ctx context.Context, bookId int, seriesId, volume *int) error {
+	query := "update books set series_id = $1, series_volume = $2, updated_at = $3 where id = $4"
+
+	_, err := r.db.ExecContext(ctx, query, seriesId, volume, time.Now(), bookId)
+
+	return err
+}
+
+func (r *repo) Rating(ctx context.Context, bookId int) (dto.BookRating, error) {
+	result := dto.BookRating{
+		Distribution: make([]dto.BookRatingBucket, 0),
+	}
+
+	query := `select personal_rating as rating, count(*) as count
+			  from bookmarks
+			  where book_id = $1 and personal_rating is not null
+			  group by personal_rating
+			  order by personal_rating`
+
+	if err := r.db.SelectContext(ctx, &result.Distribution, query, bookId); err != nil {
+		return result, err
+	}
+
+	sum := 0
+	for _, bucket := range result.Distribution {
+		result.Count += bucket.Count
+		sum += bucket.Rating * bucket.Count
+	}
+
+	if result.Count > 0 {
+		average := float64(sum) / float64(result.Count)
+		result.Average = &average
+	}
+
+	return result, nil
+}
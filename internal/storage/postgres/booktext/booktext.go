@@ -0,0 +1,118 @@
+package booktext
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/book"
+	"nevermore/internal/storage/postgres/search"
+)
+
+type Repo interface {
+	ReplaceIndex(ctx context.Context, bookId int, fragments []model.Fragment) error
+	Search(ctx context.Context, req dto.BookTextSearchRequest) (dto.BookTextSearchResponse, error)
+	// Unindexed возвращает книги, у которых ещё нет ни одного фрагмента текста
+	Unindexed(ctx context.Context) ([]int, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+// ReplaceIndex полностью пересобирает индекс книги в одной транзакции,
+// чтобы поиск не видел наполовину загруженный текст
+func (r *repo) ReplaceIndex(ctx context.Context, bookId int, fragments []model.Fragment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from book_text_fragments where book_id = $1", bookId); err != nil {
+		return err
+	}
+
+	query := `insert into book_text_fragments
+				(book_id, position, chapter_index, cfi, page, content)
+			  values ($1, $2, $3, $4, $5, $6)`
+
+	stmt, err := tx.PreparexContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, fragment := range fragments {
+		_, err := stmt.ExecContext(
+			ctx,
+			bookId,
+			fragment.Position,
+			fragment.ChapterIndex,
+			fragment.Cfi,
+			fragment.Page,
+			fragment.Content,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repo) Search(ctx context.Context, req dto.BookTextSearchRequest) (dto.BookTextSearchResponse, error) {
+	var result dto.BookTextSearchResponse
+
+	query := `with q as (
+				select websearch_to_tsquery('russian', $2) || websearch_to_tsquery('english', $2) as query
+			  )
+			  select f.id as fragment_id, f.position, f.chapter_index, f.cfi, f.page,
+			         ts_rank(f.search_vector, q.query) as rank,
+			         ts_headline('russian', ` + search.EscapeHTML("f.content") + `, q.query,
+			                     'StartSel=<mark>, StopSel=</mark>, MaxFragments=1, MinWords=15, MaxWords=35') as snippet
+			  from book_text_fragments f
+			  cross join q
+			  where f.book_id = $1 and f.search_vector @@ q.query
+			  order by f.position
+			  limit $3 offset $4`
+
+	hits := make([]dto.BookTextHit, 0)
+	if err := r.db.SelectContext(ctx, &hits, query, req.BookId, req.Query, req.Limit, req.Offset); err != nil {
+		return result, err
+	}
+
+	totalQuery := `select count(*)
+				   from book_text_fragments
+				   where book_id = $1
+				     and search_vector @@ (websearch_to_tsquery('russian', $2) || websearch_to_tsquery('english', $2))`
+
+	if err := r.db.GetContext(ctx, &result.Total, totalQuery, req.BookId, req.Query); err != nil {
+		return result, err
+	}
+
+	result.Hits = hits
+
+	return result, nil
+}
+
+func (r *repo) Unindexed(ctx context.Context) ([]int, error) {
+	result := make([]int, 0)
+
+	query := `select b.id from books b
+			  where not exists (select 1 from book_text_fragments f where f.book_id = b.id)
+			  order by b.id`
+
+	err := r.db.SelectContext(ctx, &result, query)
+
+	return result, err
+}
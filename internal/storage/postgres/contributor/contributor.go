@@ -0,0 +1,129 @@
+package contributor
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	model "nevermore/internal/model/book"
+)
+
+type Repo interface {
+	ByBook(ctx context.Context, bookId int) ([]model.Contributor, error)
+	ByBooks(ctx context.Context, bookIds []int) (map[int][]model.Contributor, error)
+	Set(ctx context.Context, bookId int, contributors []model.Contributor) error
+	BookIds(ctx context.Context, authorId int) ([]int, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) ByBook(ctx context.Context, bookId int) ([]model.Contributor, error) {
+	contributors := make([]model.Contributor, 0)
+
+	query := `select bc.book_id, bc.author_id, a.name, bc.role, bc.position
+			  from book_contributors bc
+			  join authors a on a.id = bc.author_id
+			  where bc.book_id = $1
+			  order by bc.position, a.name`
+
+	err := r.db.SelectContext(ctx, &contributors, query, bookId)
+
+	return contributors, err
+}
+
+// ByBooks загружает участников сразу для страницы книг, сгруппировав их по book_id
+func (r *repo) ByBooks(ctx context.Context, bookIds []int) (map[int][]model.Contributor, error) {
+	result := make(map[int][]model.Contributor, len(bookIds))
+	if len(bookIds) == 0 {
+		return result, nil
+	}
+
+	var contributors []model.Contributor
+
+	query := `select bc.book_id, bc.author_id, a.name, bc.role, bc.position
+			  from book_contributors bc
+			  join authors a on a.id = bc.author_id
+			  where bc.book_id = any($1)
+			  order by bc.book_id, bc.position, a.name`
+
+	if err := r.db.SelectContext(ctx, &contributors, query, pq.Array(bookIds)); err != nil {
+		return result, err
+	}
+
+	for _, contributor := range contributors {
+		result[contributor.BookId] = append(result[contributor.BookId], contributor)
+	}
+
+	return result, nil
+}
+
+// Attach подгружает участников для страницы книг одним запросом через ByBooks.
+// field возвращает id книги элемента и поле, куда положить участников; книге без участников
+// достаётся пустой список, чтобы в ответе был [], а не null
+func Attach[T any](ctx context.Context, r Repo, items []T, field func(item *T) (int, *[]model.Contributor)) error {
+	bookIds := make([]int, 0, len(items))
+	for i := range items {
+		bookId, _ := field(&items[i])
+		bookIds = append(bookIds, bookId)
+	}
+
+	contributors, err := r.ByBooks(ctx, bookIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		bookId, dest := field(&items[i])
+
+		*dest = contributors[bookId]
+		if *dest == nil {
+			*dest = make([]model.Contributor, 0)
+		}
+	}
+
+	return nil
+}
+
+func (r *repo) Set(ctx context.Context, bookId int, contributors []model.Contributor) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from book_contributors where book_id = $1", bookId); err != nil {
+		return err
+	}
+
+	query := `insert into book_contributors (book_id, author_id, role, position)
+			  values ($1, $2, $3, $4)
+			  on conflict do nothing`
+
+	for _, contributor := range contributors {
+		_, err := tx.ExecContext(ctx, query, bookId, contributor.AuthorId, contributor.Role, contributor.Position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repo) BookIds(ctx context.Context, authorId int) ([]int, error) {
+	bookIds := make([]int, 0)
+
+	err := r.db.SelectContext(ctx, &bookIds, "select distinct book_id from book_contributors where author_id = $1", authorId)
+
+	return bookIds, err
+}
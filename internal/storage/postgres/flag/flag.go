@@ -0,0 +1,132 @@
+package flag
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"nevermore/pkg/flags"
+)
+
+type Repo interface {
+	List(ctx context.Context) ([]flags.Flag, error)
+	Upsert(ctx context.Context, flag flags.Flag) (flags.Flag, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+// row — строка таблицы: массивы Postgres читаются через pq
+type row struct {
+	Name        string         `db:"name"`
+	Description *string        `db:"description"`
+	Enabled     bool           `db:"enabled"`
+	Roles       pq.StringArray `db:"roles"`
+	UserIds     pq.Int64Array  `db:"user_ids"`
+	Percentage  int            `db:"percentage"`
+	UpdatedBy   *int           `db:"updated_by"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (r row) flag() flags.Flag {
+	result := flags.Flag{
+		Name:        r.Name,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Roles:       []string(r.Roles),
+		UserIds:     make([]int, 0, len(r.UserIds)),
+		Percentage:  r.Percentage,
+		UpdatedBy:   r.UpdatedBy,
+		UpdatedAt:   r.UpdatedAt,
+	}
+
+	if result.Roles == nil {
+		result.Roles = make([]string, 0)
+	}
+
+	for _, id := range r.UserIds {
+		result.UserIds = append(result.UserIds, int(id))
+	}
+
+	return result
+}
+
+const columns = "name, description, enabled, roles, user_ids, percentage, updated_by, updated_at"
+
+func (r *repo) List(ctx context.Context) ([]flags.Flag, error) {
+	var rows []row
+
+	if err := r.db.SelectContext(ctx, &rows, "select "+columns+" from feature_flags order by name"); err != nil {
+		return nil, err
+	}
+
+	result := make([]flags.Flag, 0, len(rows))
+	for _, item := range rows {
+		result = append(result, item.flag())
+	}
+
+	return result, nil
+}
+
+func (r *repo) Upsert(ctx context.Context, flag flags.Flag) (flags.Flag, error) {
+	var result row
+
+	query := `insert into feature_flags (name, description, enabled, roles, user_ids, percentage, updated_by, updated_at)
+			  values ($1, $2, $3, $4, $5, $6, $7, $8)
+			  on conflict (name) do update
+			  set description = excluded.description,
+			      enabled = excluded.enabled,
+			      roles = excluded.roles,
+			      user_ids = excluded.user_ids,
+			      percentage = excluded.percentage,
+			      updated_by = excluded.updated_by,
+			      updated_at = excluded.updated_at
+			  returning ` + columns
+
+	err := r.db.GetContext(
+		ctx,
+		&result,
+		query,
+		flag.Name,
+		flag.Description,
+		flag.Enabled,
+		pq.Array(flag.Roles),
+		pq.Array(flag.UserIds),
+		flag.Percentage,
+		flag.UpdatedBy,
+		time.Now(),
+	)
+
+	return result.flag(), err
+}
+
+func (r *repo) Delete(ctx context.Context, name string) error {
+	res, err := r.db.ExecContext(ctx, "delete from feature_flags where name = $1", name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package follow
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/model/notification"
+)
+
+type Repo interface {
+	Follow(ctx context.Context, userId, authorId int) error
+	Unfollow(ctx context.Context, userId, authorId int) error
+	ListAuthors(ctx context.Context, userId int) ([]dto.FollowedAuthor, error)
+	NotifyNewBooks(ctx context.Context, createdBefore time.Time, limit int) (int, []notification.Notification, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) Follow(ctx context.Context, userId, authorId int) error {
+	query := "insert into saved_authors (user_id, author_id) values ($1, $2) on conflict do nothing"
+
+	_, err := r.db.ExecContext(ctx, query, userId, authorId)
+
+	return err
+}
+
+func (r *repo) Unfollow(ctx context.Context, userId, authorId int) error {
+	query := "delete from saved_authors where user_id = $1 and author_id = $2"
+
+	_, err := r.db.ExecContext(ctx, query, userId, authorId)
+
+	return err
+}
+
+func (r *repo) ListAuthors(ctx context.Context, userId int) ([]dto.FollowedAuthor, error) {
+	authors := make([]dto.FollowedAuthor, 0)
+
+	query := `select a.id, a.name, a.photo_url, sa.created_at as followed_at,
+			         (select count(distinct bc.book_id) from book_contributors bc where bc.author_id = a.id) as book_count
+			  from saved_authors sa
+			  join authors a on a.id = sa.author_id
+			  where sa.user_id = $1
+			  order by sa.created_at desc`
+
+	err := r.db.SelectContext(ctx, &authors, query, userId)
+
+	return authors, err
+}
+
+// NotifyNewBooks создаёт уведомления подписчикам авторов для ещё не разосланных книг
+// и возвращает уведомления, которые подписчики попросили дублировать на почту.
+// Книга без авторов не считается разосланной и ждёт, пока ей назначат автора.
+// Книги блокируются через skip locked, поэтому несколько реплик не разошлют одно и то же дважды
+func (r *repo) NotifyNewBooks(ctx context.Context, createdBefore time.Time, limit int) (int, []notification.Notification, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	var bookIds []int64
+
+	query := `select b.id from books b
+			  where b.followers_notified_at is null and b.created_at <= $1
+			    and exists (select 1 from book_contributors bc where bc.book_id = b.id and bc.role = 'author')
+			  order by b.created_at
+			  limit $2
+			  for update of b skip locked`
+
+	if err := tx.SelectContext(ctx, &bookIds, query, createdBefore, limit); err != nil {
+		return 0, nil, err
+	}
+
+	if len(bookIds) == 0 {
+		return 0, nil, nil
+	}
+
+	// Подписчик нескольких соавторов получает одно уведомление о книге, удалённые
+	// пользователи не получают ничего; каналы доставки берутся из настроек,
+	// по умолчанию только в приложении
+	recipientsQuery := `with recipients as (
+						select distinct on (sa.user_id, b.id)
+						       sa.user_id,
+						       jsonb_build_object('book_id', b.id, 'title', b.title, 'author_id', a.id, 'author_name', a.name) as payload,
+						       coalesce(np.in_app, true) as in_app,
+						       coalesce(np.email, false) as email
+						from books b
+						join book_contributors bc on bc.book_id = b.id and bc.role = 'author'
+						join authors a on a.id = bc.author_id
+						join saved_authors sa on sa.author_id = bc.author_id
+						join users u on u.id = sa.user_id and u.deleted_at is null
+						left join notification_preferences np on np.user_id = sa.user_id and np.type = 'author_new_book'
+						where b.id = any($1) and sa.user_id <> b.uploaded_by
+						order by sa.user_id, b.id, bc.position
+					),
+					inserted as (
+						insert into notifications (user_id, type, payload)
+						select user_id, 'author_new_book', payload from recipients where in_app
+					)
+					select user_id, 'author_new_book' as type, payload from recipients where email`
+
+	emails := make([]notification.Notification, 0)
+	if err := tx.SelectContext(ctx, &emails, recipientsQuery, pq.Array(bookIds)); err != nil {
+		return 0, nil, err
+	}
+
+	updateQuery := "update books set followers_notified_at = $1 where id = any($2)"
+
+	if _, err := tx.ExecContext(ctx, updateQuery, time.Now(), pq.Array(bookIds)); err != nil {
+		return 0, nil, err
+	}
+
+	return len(bookIds), emails, tx.Commit()
+}
@@ -0,0 +1,101 @@
+package genre
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/genre"
+)
+
+type Repo interface {
+	List(ctx context.Context) ([]model.Genre, error)
+	Create(ctx context.Context, genre *model.Genre) error
+	Delete(ctx context.Context, id int) error
+	GetByBook(ctx context.Context, bookId int) ([]model.Genre, error)
+	SetBookGenres(ctx context.Context, bookId int, genreIds []int) error
+}
+
+// Subtree — подзапрос id жанра с плейсхолдером param и всех его поджанров на любой
+// глубине. Фильтры по жанру в каталоге и поиске должны совпадать, поэтому оба берут его
+func Subtree(param string) string {
+	return `with recursive genre_tree as (
+				select id from genres where id = ` + param + `
+				union all
+				select g.id from genres g join genre_tree t on g.parent_id = t.id
+			)
+			select id from genre_tree`
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) List(ctx context.Context) ([]model.Genre, error) {
+	genres := make([]model.Genre, 0)
+
+	query := "select id, parent_id, name, slug, created_at from genres order by name"
+
+	err := r.db.SelectContext(ctx, &genres, query)
+
+	return genres, err
+}
+
+func (r *repo) Create(ctx context.Context, genre *model.Genre) error {
+	query := `insert into genres (parent_id, name, slug)
+			  values ($1, $2, $3)
+			  returning id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query, genre.ParentId, genre.Name, genre.Slug).
+		Scan(&genre.Id, &genre.CreatedAt)
+}
+
+func (r *repo) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "delete from genres where id = $1", id)
+
+	return err
+}
+
+func (r *repo) GetByBook(ctx context.Context, bookId int) ([]model.Genre, error) {
+	genres := make([]model.Genre, 0)
+
+	query := `select g.id, g.parent_id, g.name, g.slug, g.created_at
+			  from genres g
+			  join book_genres bg on bg.genre_id = g.id
+			  where bg.book_id = $1
+			  order by g.name`
+
+	err := r.db.SelectContext(ctx, &genres, query, bookId)
+
+	return genres, err
+}
+
+func (r *repo) SetBookGenres(ctx context.Context, bookId int, genreIds []int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from book_genres where book_id = $1", bookId); err != nil {
+		return err
+	}
+
+	for _, genreId := range genreIds {
+		query := "insert into book_genres (book_id, genre_id) values ($1, $2) on conflict do nothing"
+
+		if _, err := tx.ExecContext(ctx, query, bookId, genreId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
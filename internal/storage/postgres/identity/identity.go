@@ -0,0 +1,135 @@
+package identity
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/identity"
+)
+
+type Repo interface {
+	Get(ctx context.Context, provider, subject string) (model.Identity, error)
+	ListByUser(ctx context.Context, userId int) ([]model.Identity, error)
+	CreateTx(ctx context.Context, tx *sqlx.Tx, identity *model.Identity) error
+	Delete(ctx context.Context, userId int, provider string) error
+	TouchLogin(ctx context.Context, id int64) error
+
+	SaveState(ctx context.Context, state model.State) error
+	// ConsumeState удаляет состояние и возвращает его, если оно ещё не истекло
+	ConsumeState(ctx context.Context, stateHash string) (model.State, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) Get(ctx context.Context, provider, subject string) (model.Identity, error) {
+	var identity model.Identity
+
+	query := `select id, user_id, provider, subject, email, created_at, last_login_at
+			  from user_identities
+			  where provider = $1 and subject = $2`
+
+	err := r.db.GetContext(ctx, &identity, query, provider, subject)
+
+	return identity, err
+}
+
+func (r *repo) ListByUser(ctx context.Context, userId int) ([]model.Identity, error) {
+	identities := make([]model.Identity, 0)
+
+	query := `select id, user_id, provider, subject, email, created_at, last_login_at
+			  from user_identities
+			  where user_id = $1
+			  order by provider`
+
+	err := r.db.SelectContext(ctx, &identities, query, userId)
+
+	return identities, err
+}
+
+func (r *repo) CreateTx(ctx context.Context, tx *sqlx.Tx, identity *model.Identity) error {
+	query := `insert into user_identities (user_id, provider, subject, email, last_login_at)
+			  values ($1, $2, $3, $4, $5)
+			  returning id, created_at`
+
+	return tx.QueryRowxContext(
+		ctx,
+		query,
+		identity.UserId,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.LastLoginAt,
+	).Scan(&identity.Id, &identity.CreatedAt)
+}
+
+func (r *repo) Delete(ctx context.Context, userId int, provider string) error {
+	res, err := r.db.ExecContext(ctx, "delete from user_identities where user_id = $1 and provider = $2", userId, provider)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *repo) TouchLogin(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "update user_identities set last_login_at = $1 where id = $2", time.Now(), id)
+
+	return err
+}
+
+func (r *repo) SaveState(ctx context.Context, state model.State) error {
+	// Заодно чистим брошенные попытки входа
+	if _, err := r.db.ExecContext(ctx, "delete from oidc_states where expires_at < $1", time.Now()); err != nil {
+		return err
+	}
+
+	query := `insert into oidc_states (state_hash, provider, nonce, code_verifier, link_user_id, expires_at)
+			  values ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		state.StateHash,
+		state.Provider,
+		state.Nonce,
+		state.CodeVerifier,
+		state.LinkUserId,
+		state.ExpiresAt,
+	)
+
+	return err
+}
+
+func (r *repo) ConsumeState(ctx context.Context, stateHash string) (model.State, error) {
+	var state model.State
+
+	query := `delete from oidc_states
+			  where state_hash = $1 and expires_at > $2
+			  returning state_hash, provider, nonce, code_verifier, link_user_id, expires_at`
+
+	err := r.db.GetContext(ctx, &state, query, stateHash, time.Now())
+
+	return state, err
+}
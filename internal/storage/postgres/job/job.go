@@ -0,0 +1,155 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"nevermore/internal/dto"
+	"nevermore/pkg/jobs"
+)
+
+// Repo — хранилище очереди задач для pkg/jobs и админки
+type Repo interface {
+	jobs.Store
+
+	List(ctx context.Context, req dto.JobListRequest) (dto.JobListResponse, error)
+	Get(ctx context.Context, id int64) (jobs.Job, error)
+	// Retry возвращает мёртвую задачу в очередь с новым запасом попыток
+	Retry(ctx context.Context, id int64) (jobs.Job, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+const columns = "id, queue, type, payload, status, attempts, max_attempts, run_at, locked_until, last_error, request_id, created_at, updated_at"
+
+func (r *repo) Insert(ctx context.Context, job jobs.Job) (jobs.Job, error) {
+	var result jobs.Job
+
+	query := `insert into jobs (queue, type, payload, status, max_attempts, run_at, request_id)
+			  values ($1, $2, $3, $4, $5, $6, $7)
+			  returning ` + columns
+
+	// []byte lib/pq передаёт как bytea, а jsonb ждёт текст
+	err := r.db.GetContext(ctx, &result, query,
+		job.Queue, job.Type, string(job.Payload), job.Status, job.MaxAttempts, job.RunAt, job.RequestId)
+
+	return result, err
+}
+
+func (r *repo) Claim(ctx context.Context, queue string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	query := `update jobs
+			  set status = 'running',
+			      attempts = attempts + 1,
+			      locked_until = now() + make_interval(secs => $3),
+			      updated_at = now()
+			  where id in (
+			      select id from jobs
+			      where queue = $1
+			        and ((status = 'pending' and run_at <= now())
+			          or (status = 'running' and locked_until < now()))
+			      order by run_at, id
+			      limit $2
+			      for update skip locked
+			  )
+			  returning ` + columns
+
+	result := make([]jobs.Job, 0, limit)
+	err := r.db.SelectContext(ctx, &result, query, queue, limit, lease.Seconds())
+
+	return result, err
+}
+
+// Complete, Reschedule и Bury пишут итог только той попытки, что его получила: если аренда
+// истекла и задачу уже забрал другой воркер, запоздавший результат отбрасывается
+func (r *repo) Complete(ctx context.Context, job jobs.Job) error {
+	query := "delete from jobs where id = $1 and status = 'running' and attempts = $2"
+
+	_, err := r.db.ExecContext(ctx, query, job.Id, job.Attempts)
+
+	return err
+}
+
+func (r *repo) Reschedule(ctx context.Context, job jobs.Job, runAt time.Time, lastError string) error {
+	query := `update jobs
+			  set status = 'pending', run_at = $3, locked_until = null, last_error = $4, updated_at = now()
+			  where id = $1 and status = 'running' and attempts = $2`
+
+	_, err := r.db.ExecContext(ctx, query, job.Id, job.Attempts, runAt, lastError)
+
+	return err
+}
+
+func (r *repo) Bury(ctx context.Context, job jobs.Job, lastError string) error {
+	query := `update jobs
+			  set status = 'dead', locked_until = null, last_error = $3, updated_at = now()
+			  where id = $1 and status = 'running' and attempts = $2`
+
+	_, err := r.db.ExecContext(ctx, query, job.Id, job.Attempts, lastError)
+
+	return err
+}
+
+func (r *repo) Counts(ctx context.Context) ([]jobs.Count, error) {
+	result := make([]jobs.Count, 0)
+
+	query := "select queue, status, count(*) as count from jobs group by queue, status order by queue, status"
+
+	err := r.db.SelectContext(ctx, &result, query)
+
+	return result, err
+}
+
+func (r *repo) List(ctx context.Context, req dto.JobListRequest) (dto.JobListResponse, error) {
+	var result dto.JobListResponse
+
+	where := `where ($1 = '' or status = $1)
+			    and ($2 = '' or queue = $2)
+			    and ($3 = '' or type = $3)`
+
+	if err := r.db.GetContext(ctx, &result.Total, "select count(*) from jobs "+where, req.Status, req.Queue, req.Type); err != nil {
+		return result, err
+	}
+
+	query := "select " + columns + " from jobs " + where + `
+			  order by updated_at desc, id desc
+			  limit $4 offset $5`
+
+	result.Jobs = make([]jobs.Job, 0)
+	err := r.db.SelectContext(ctx, &result.Jobs, query, req.Status, req.Queue, req.Type, req.Limit, req.Offset)
+
+	return result, err
+}
+
+func (r *repo) Get(ctx context.Context, id int64) (jobs.Job, error) {
+	var result jobs.Job
+
+	err := r.db.GetContext(ctx, &result, "select "+columns+" from jobs where id = $1", id)
+
+	return result, err
+}
+
+func (r *repo) Retry(ctx context.Context, id int64) (jobs.Job, error) {
+	var result jobs.Job
+
+	// Ошибку прошлого запуска оставляем для разбора, если повтор снова не удастся
+	query := `update jobs
+			  set status = 'pending', attempts = 0, run_at = now(), updated_at = now()
+			  where id = $1 and status = 'dead'
+			  returning ` + columns
+
+	err := r.db.GetContext(ctx, &result, query, id)
+
+	return result, err
+}
@@ -0,0 +1,139 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/notification"
+)
+
+type Repo interface {
+	Create(ctx context.Context, notification *model.Notification) error
+	List(ctx context.Context, req dto.NotificationListRequest) (dto.NotificationListResponse, error)
+	MarkRead(ctx context.Context, userId int, id int64) error
+	MarkAllRead(ctx context.Context, userId int) (int64, error)
+	UnreadCount(ctx context.Context, userId int) (int, error)
+	Preferences(ctx context.Context, userId int) ([]model.Preference, error)
+	UpsertPreference(ctx context.Context, preference model.Preference) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) Create(ctx context.Context, notification *model.Notification) error {
+	query := `insert into notifications (user_id, type, payload)
+			  values ($1, $2, $3)
+			  returning id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query, notification.UserId, notification.Type, notification.Payload).
+		Scan(&notification.Id, &notification.CreatedAt)
+}
+
+func (r *repo) List(ctx context.Context, req dto.NotificationListRequest) (dto.NotificationListResponse, error) {
+	var result dto.NotificationListResponse
+
+	countQuery := `select count(*) from notifications
+				   where user_id = $1 and (not $2 or read_at is null)`
+
+	if err := r.db.GetContext(ctx, &result.Total, countQuery, req.UserId, req.UnreadOnly); err != nil {
+		return result, err
+	}
+
+	query := `select id, user_id, type, payload, read_at, created_at
+			  from notifications
+			  where user_id = $1 and (not $2 or read_at is null)
+			  order by created_at desc, id desc
+			  limit $3 offset $4`
+
+	notifications := make([]model.Notification, 0)
+	if err := r.db.SelectContext(ctx, &notifications, query, req.UserId, req.UnreadOnly, req.Limit, req.Offset); err != nil {
+		return result, err
+	}
+
+	result.Notifications = notifications
+
+	return result, nil
+}
+
+func (r *repo) MarkRead(ctx context.Context, userId int, id int64) error {
+	query := "update notifications set read_at = coalesce(read_at, $1) where id = $2 and user_id = $3"
+
+	res, err := r.db.ExecContext(ctx, query, time.Now(), id, userId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *repo) MarkAllRead(ctx context.Context, userId int) (int64, error) {
+	query := "update notifications set read_at = $1 where user_id = $2 and read_at is null"
+
+	res, err := r.db.ExecContext(ctx, query, time.Now(), userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *repo) UnreadCount(ctx context.Context, userId int) (int, error) {
+	var count int
+
+	query := "select count(*) from notifications where user_id = $1 and read_at is null"
+
+	err := r.db.GetContext(ctx, &count, query, userId)
+
+	return count, err
+}
+
+func (r *repo) Preferences(ctx context.Context, userId int) ([]model.Preference, error) {
+	preferences := make([]model.Preference, 0)
+
+	query := "select user_id, type, in_app, email from notification_preferences where user_id = $1"
+
+	err := r.db.SelectContext(ctx, &preferences, query, userId)
+
+	return preferences, err
+}
+
+func (r *repo) UpsertPreference(ctx context.Context, preference model.Preference) error {
+	query := `insert into notification_preferences (user_id, type, in_app, email, updated_at)
+			  values ($1, $2, $3, $4, $5)
+			  on conflict (user_id, type) do update
+			  set in_app = excluded.in_app, email = excluded.email, updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		preference.UserId,
+		preference.Type,
+		preference.InApp,
+		preference.Email,
+		time.Now(),
+	)
+
+	return err
+}
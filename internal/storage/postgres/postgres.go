@@ -2,36 +2,137 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"nevermore/internal/storage/postgres/apitoken"
+	"nevermore/internal/storage/postgres/author"
+	"nevermore/internal/storage/postgres/book"
+	"nevermore/internal/storage/postgres/booktext"
+	"nevermore/internal/storage/postgres/contributor"
+	"nevermore/internal/storage/postgres/flag"
+	"nevermore/internal/storage/postgres/follow"
+	"nevermore/internal/storage/postgres/genre"
+	"nevermore/internal/storage/postgres/identity"
+	"nevermore/internal/storage/postgres/job"
+	"nevermore/internal/storage/postgres/notification"
+	"nevermore/internal/storage/postgres/search"
+	"nevermore/internal/storage/postgres/series"
+	"nevermore/internal/storage/postgres/shelf"
+	"nevermore/internal/storage/postgres/tag"
+	"nevermore/internal/storage/postgres/twofactor"
 	"nevermore/internal/storage/postgres/user"
+	"nevermore/internal/storage/postgres/usertoken"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
 )
 
 type Repo interface {
 	BeginTx(ctx context.Context) (*sqlx.Tx, error)
+	Stats() sql.DBStats
+	Ping(ctx context.Context) error
+	Close() error
 	User() user.Repo
+	Search() search.Repo
+	BookText() booktext.Repo
+	Book() book.Repo
+	Genre() genre.Repo
+	Tag() tag.Repo
+	Series() series.Repo
+	Author() author.Repo
+	Contributor() contributor.Repo
+	Follow() follow.Repo
+	Notification() notification.Repo
+	UserToken() usertoken.Repo
+	TwoFactor() twofactor.Repo
+	Identity() identity.Repo
+	APIToken() apitoken.Repo
+	Shelf() shelf.Repo
+	Flag() flag.Repo
+	Job() job.Repo
 }
 
 type repo struct {
-	db   *sqlx.DB
-	user user.Repo
+	db           *sqlx.DB
+	user         user.Repo
+	search       search.Repo
+	bookText     booktext.Repo
+	book         book.Repo
+	genre        genre.Repo
+	tag          tag.Repo
+	series       series.Repo
+	author       author.Repo
+	contributor  contributor.Repo
+	follow       follow.Repo
+	notification notification.Repo
+	userToken    usertoken.Repo
+	twoFactor    twofactor.Repo
+	identity     identity.Repo
+	apiToken     apitoken.Repo
+	shelf        shelf.Repo
+	flag         flag.Repo
+	job          job.Repo
 }
 
 func (r *repo) BeginTx(ctx context.Context) (*sqlx.Tx, error) {
 	return r.db.BeginTxx(ctx, nil)
 }
 
+// Stats возвращает статистику пула соединений для метрик
+func (r *repo) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+func (r *repo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *repo) Close() error {
+	return r.db.Close()
+}
+
 func NewDB(cfg Config) (Repo, error) {
-	db, err := sqlx.Connect(cfg.Driver, cfg.URL)
-	fmt.Println("CONNECTED TO PSQL")
+	// Драйвер обёрнут otelsql: каждый запрос — спан внутри спана запроса или задачи
+	sqlDB, err := otelsql.Open(cfg.Driver, cfg.URL,
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			DisableErrSkip:       true,
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	db := sqlx.NewDb(sqlDB, cfg.Driver)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	fmt.Println("CONNECTED TO PSQL")
+
 	result := &repo{
-		db:   db,
-		user: user.New(db),
+		db:           db,
+		user:         user.New(db),
+		search:       search.New(db),
+		bookText:     booktext.New(db),
+		book:         book.New(db),
+		genre:        genre.New(db),
+		tag:          tag.New(db),
+		series:       series.New(db),
+		author:       author.New(db),
+		contributor:  contributor.New(db),
+		follow:       follow.New(db),
+		notification: notification.New(db),
+		userToken:    usertoken.New(db),
+		twoFactor:    twofactor.New(db),
+		identity:     identity.New(db),
+		apiToken:     apitoken.New(db),
+		shelf:        shelf.New(db),
+		flag:         flag.New(db),
+		job:          job.New(db),
 	}
 	return result, nil
 }
@@ -39,3 +140,71 @@ func NewDB(cfg Config) (Repo, error) {
 func (r *repo) User() user.Repo {
 	return r.user
 }
+
+func (r *repo) Search() search.Repo {
+	return r.search
+}
+
+func (r *repo) BookText() booktext.Repo {
+	return r.bookText
+}
+
+func (r *repo) Book() book.Repo {
+	return r.book
+}
+
+func (r *repo) Genre() genre.Repo {
+	return r.genre
+}
+
+func (r *repo) Tag() tag.Repo {
+	return r.tag
+}
+
+func (r *repo) Series() series.Repo {
+	return r.series
+}
+
+func (r *repo) Author() author.Repo {
+	return r.author
+}
+
+func (r *repo) Contributor() contributor.Repo {
+	return r.contributor
+}
+
+func (r *repo) Follow() follow.Repo {
+	return r.follow
+}
+
+func (r *repo) Notification() notification.Repo {
+	return r.notification
+}
+
+func (r *repo) UserToken() usertoken.Repo {
+	return r.userToken
+}
+
+func (r *repo) TwoFactor() twofactor.Repo {
+	return r.twoFactor
+}
+
+func (r *repo) Identity() identity.Repo {
+	return r.identity
+}
+
+func (r *repo) APIToken() apitoken.Repo {
+	return r.apiToken
+}
+
+func (r *repo) Shelf() shelf.Repo {
+	return r.shelf
+}
+
+func (r *repo) Flag() flag.Repo {
+	return r.flag
+}
+
+func (r *repo) Job() job.Repo {
+	return r.job
+}
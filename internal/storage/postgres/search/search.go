@@ -0,0 +1,148 @@
+package search
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"nevermore/internal/dto"
+	bookModel "nevermore/internal/model/book"
+	"nevermore/internal/storage/postgres/genre"
+)
+
+type Repo interface {
+	Catalog(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+// matchesQuery отбирает книги, у которых запрос совпал с названием, описанием,
+// именем или биографией кого-то из участников, либо название/имя/псевдоним участника
+// похожи на запрос по триграммам
+const matchesQuery = `with q as (
+				select websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) as query
+			),
+			matches as (
+				select b.id, b.title, b.description, b.cover_image_url,
+				       ts_rank(b.search_vector, q.query)
+				           + 0.5 * coalesce(ar.rank, 0)
+				           + greatest(similarity(b.title, $1), coalesce(ar.similarity, 0)) as rank
+				from books b
+				cross join q
+				left join lateral (
+				    select max(ts_rank(a.search_vector, q.query)) as rank,
+				           max(similarity(a.name, $1)) as similarity,
+				           bool_or(
+				               a.search_vector @@ q.query
+				               or a.name % $1
+				               or exists (
+				                   select 1 from author_aliases al where al.author_id = a.id and al.name % $1
+				               )
+				           ) as matched
+				    from book_contributors bc
+				    join authors a on a.id = bc.author_id
+				    where bc.book_id = b.id
+				) ar on true
+				where b.search_vector @@ q.query
+				   or b.title % $1
+				   or ar.matched
+			)`
+
+// EscapeHTML экранирует текстовое выражение expr до ts_headline. ts_headline возвращает
+// исходный текст как есть, добавляя только метки совпадений, а в названиях, описаниях
+// и тексте книг может оказаться разметка. Сущности вроде &lt; парсер считает отдельными
+// токенами, так что совпадения по словам не меняются
+func EscapeHTML(expr string) string {
+	return `replace(replace(replace(replace(replace(` + expr + `,
+			'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// authorRole — роль участника, по которой строится фасет авторов и работает фильтр по нему.
+// Условие общее, иначе счётчики фасета расходились бы с отфильтрованной выдачей
+const authorRole = `'` + bookModel.RoleAuthor + `'`
+
+// filterCondition фильтрует совпадения по выбранным фасетам: $2 — автор, $3 — жанр
+// вместе с поджанрами, как в каталоге
+var filterCondition = `($2::int is null or exists (
+			      select 1 from book_contributors fc
+			      where fc.book_id = m.id and fc.author_id = $2 and fc.role = ` + authorRole + `
+			  ))
+			  and ($3::int is null or exists (
+			      select 1 from book_genres fg where fg.book_id = m.id and fg.genre_id in (` + genre.Subtree("$3") + `)
+			  ))`
+
+func (r *repo) Catalog(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error) {
+	var result dto.SearchResponse
+
+	hitsQuery := matchesQuery + `
+			select m.id as book_id, m.title, m.cover_image_url, m.rank,
+			       ts_headline('russian', ` + EscapeHTML("m.title") + `, q.query,
+			                   'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') as title_highlight,
+			       ts_headline('russian', ` + EscapeHTML("coalesce(m.description, '')") + `, q.query,
+			                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=10, MaxWords=30') as snippet
+			from matches m
+			cross join q
+			where ` + filterCondition + `
+			order by m.rank desc, m.id
+			limit $4 offset $5`
+
+	hits := make([]dto.SearchHit, 0)
+	if err := r.db.SelectContext(ctx, &hits, hitsQuery, req.Query, req.AuthorId, req.GenreId, req.Limit, req.Offset); err != nil {
+		return result, err
+	}
+
+	totalQuery := matchesQuery + `
+			select count(*) from matches m where ` + filterCondition
+
+	if err := r.db.GetContext(ctx, &result.Total, totalQuery, req.Query, req.AuthorId, req.GenreId); err != nil {
+		return result, err
+	}
+
+	// Каждый фасет учитывает фильтры по остальным фасетам, но не по себе,
+	// чтобы можно было переключаться между значениями
+	authorsQuery := matchesQuery + `
+			select a.id, a.name, count(distinct m.id) as count
+			from matches m
+			join book_contributors bc on bc.book_id = m.id and bc.role = ` + authorRole + `
+			join authors a on a.id = bc.author_id
+			where ` + filterCondition + `
+			group by a.id, a.name
+			order by count desc, a.name
+			limit 20`
+
+	authors := make([]dto.SearchFacet, 0)
+	if err := r.db.SelectContext(ctx, &authors, authorsQuery, req.Query, nil, req.GenreId); err != nil {
+		return result, err
+	}
+
+	genresQuery := matchesQuery + `
+			select g.id, g.name, count(*) as count
+			from matches m
+			join book_genres bg on bg.book_id = m.id
+			join genres g on g.id = bg.genre_id
+			where ` + filterCondition + `
+			group by g.id, g.name
+			order by count desc, g.name
+			limit 20`
+
+	genres := make([]dto.SearchFacet, 0)
+	if err := r.db.SelectContext(ctx, &genres, genresQuery, req.Query, req.AuthorId, nil); err != nil {
+		return result, err
+	}
+
+	result.Hits = hits
+	result.Facets.Authors = authors
+	result.Facets.Genres = genres
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package series
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/series"
+)
+
+type Repo interface {
+	List(ctx context.Context) ([]model.Series, error)
+	Get(ctx context.Context, id int) (model.Series, error)
+	Create(ctx context.Context, series *model.Series) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) List(ctx context.Context) ([]model.Series, error) {
+	series := make([]model.Series, 0)
+
+	query := "select id, title, description, created_at, updated_at from series order by title"
+
+	err := r.db.SelectContext(ctx, &series, query)
+
+	return series, err
+}
+
+func (r *repo) Get(ctx context.Context, id int) (model.Series, error) {
+	var series model.Series
+
+	query := "select id, title, description, created_at, updated_at from series where id = $1"
+
+	err := r.db.GetContext(ctx, &series, query, id)
+
+	return series, err
+}
+
+func (r *repo) Create(ctx context.Context, series *model.Series) error {
+	query := `insert into series (title, description)
+			  values ($1, $2)
+			  returning id, created_at, updated_at`
+
+	return r.db.QueryRowxContext(ctx, query, series.Title, series.Description).
+		Scan(&series.Id, &series.CreatedAt, &series.UpdatedAt)
+}
@@ -0,0 +1,153 @@
+package shelf
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/shelf"
+)
+
+type Repo interface {
+	List(ctx context.Context, req dto.ShelfListRequest) (dto.ShelfListResponse, error)
+	Get(ctx context.Context, userId, bookId int) (model.Item, error)
+	Upsert(ctx context.Context, userId, bookId int, req dto.ShelfItemRequest) error
+	Delete(ctx context.Context, userId, bookId int) error
+	ExistingBooks(ctx context.Context, bookIds []int) ([]int, error)
+	StatusIds(ctx context.Context) ([]int, error)
+
+	UpsertTx(ctx context.Context, tx *sqlx.Tx, userId, bookId int, req dto.ShelfItemRequest) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+const selectItems = `select b.id as book_id, b.title, b.cover_image_url,
+			         bm.status_id, rs.name as status,
+			         coalesce(bm.favorite, false) as favorite,
+			         bm.personal_rating, bm.personal_notes,
+			         coalesce(bm.current_page, 0) as current_page,
+			         bm.created_at, bm.updated_at
+			  from bookmarks bm
+			  join books b on b.id = bm.book_id
+			  join reading_statuses rs on rs.id = bm.status_id`
+
+// upsertQuery добавляет книгу на полку или меняет на ней только переданные поля
+const upsertQuery = `insert into bookmarks (user_id, book_id, status_id, favorite, personal_rating, personal_notes, current_page)
+			  values ($1, $2, coalesce($3::int, $8), coalesce($4::boolean, false), $5::smallint, $6::text, coalesce($7::int, 0))
+			  on conflict (user_id, book_id) do update
+			  set status_id = coalesce($3::int, bookmarks.status_id),
+			      favorite = coalesce($4::boolean, bookmarks.favorite),
+			      personal_rating = coalesce($5::smallint, bookmarks.personal_rating),
+			      personal_notes = coalesce($6::text, bookmarks.personal_notes),
+			      current_page = coalesce($7::int, bookmarks.current_page),
+			      updated_at = $9`
+
+func (r *repo) List(ctx context.Context, req dto.ShelfListRequest) (dto.ShelfListResponse, error) {
+	var result dto.ShelfListResponse
+
+	countQuery := `select count(*) from bookmarks
+				   where user_id = $1 and ($2::int is null or status_id = $2)`
+
+	if err := r.db.GetContext(ctx, &result.Total, countQuery, req.UserId, req.StatusId); err != nil {
+		return result, err
+	}
+
+	query := selectItems + `
+			  where bm.user_id = $1 and ($2::int is null or bm.status_id = $2)
+			  order by bm.updated_at desc, bm.id desc
+			  limit $3 offset $4`
+
+	items := make([]model.Item, 0)
+	if err := r.db.SelectContext(ctx, &items, query, req.UserId, req.StatusId, req.Limit, req.Offset); err != nil {
+		return result, err
+	}
+
+	result.Items = items
+
+	return result, nil
+}
+
+func (r *repo) Get(ctx context.Context, userId, bookId int) (model.Item, error) {
+	var item model.Item
+
+	query := selectItems + `
+			  where bm.user_id = $1 and bm.book_id = $2`
+
+	err := r.db.GetContext(ctx, &item, query, userId, bookId)
+
+	return item, err
+}
+
+func (r *repo) Upsert(ctx context.Context, userId, bookId int, req dto.ShelfItemRequest) error {
+	_, err := r.db.ExecContext(ctx, upsertQuery, upsertArgs(userId, bookId, req)...)
+
+	return err
+}
+
+func (r *repo) UpsertTx(ctx context.Context, tx *sqlx.Tx, userId, bookId int, req dto.ShelfItemRequest) error {
+	_, err := tx.ExecContext(ctx, upsertQuery, upsertArgs(userId, bookId, req)...)
+
+	return err
+}
+
+func (r *repo) Delete(ctx context.Context, userId, bookId int) error {
+	res, err := r.db.ExecContext(ctx, "delete from bookmarks where user_id = $1 and book_id = $2", userId, bookId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *repo) ExistingBooks(ctx context.Context, bookIds []int) ([]int, error) {
+	ids := make([]int, 0, len(bookIds))
+
+	err := r.db.SelectContext(ctx, &ids, "select id from books where id = any($1)", pq.Array(bookIds))
+
+	return ids, err
+}
+
+func (r *repo) StatusIds(ctx context.Context) ([]int, error) {
+	ids := make([]int, 0)
+
+	err := r.db.SelectContext(ctx, &ids, "select id from reading_statuses")
+
+	return ids, err
+}
+
+func upsertArgs(userId, bookId int, req dto.ShelfItemRequest) []any {
+	return []any{
+		userId,
+		bookId,
+		req.StatusId,
+		req.Favorite,
+		req.PersonalRating,
+		req.PersonalNotes,
+		req.CurrentPage,
+		model.DefaultStatusId,
+		time.Now(),
+	}
+}
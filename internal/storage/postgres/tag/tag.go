@@ -0,0 +1,102 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/tag"
+)
+
+type Repo interface {
+	ListByStatus(ctx context.Context, status string) ([]model.Tag, error)
+	Upsert(ctx context.Context, name string, userId int) (model.Tag, error)
+	AttachToBook(ctx context.Context, bookId, tagId, userId int) error
+	GetByBook(ctx context.Context, bookId int) ([]model.Tag, error)
+	Moderate(ctx context.Context, id int, status string, moderatorId int) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) ListByStatus(ctx context.Context, status string) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0)
+
+	query := `select id, name, status, created_by, moderated_by, created_at, moderated_at
+			  from tags
+			  where status = $1
+			  order by created_at`
+
+	err := r.db.SelectContext(ctx, &tags, query, status)
+
+	return tags, err
+}
+
+// Upsert возвращает существующий тег без учёта регистра или создаёт новый на модерации
+func (r *repo) Upsert(ctx context.Context, name string, userId int) (model.Tag, error) {
+	var tag model.Tag
+
+	query := `insert into tags (name, created_by)
+			  values ($1, $2)
+			  on conflict ((lower(name))) do update set name = tags.name
+			  returning id, name, status, created_by, moderated_by, created_at, moderated_at`
+
+	err := r.db.GetContext(ctx, &tag, query, name, userId)
+
+	return tag, err
+}
+
+func (r *repo) AttachToBook(ctx context.Context, bookId, tagId, userId int) error {
+	query := `insert into book_tags (book_id, tag_id, added_by)
+			  values ($1, $2, $3)
+			  on conflict do nothing`
+
+	_, err := r.db.ExecContext(ctx, query, bookId, tagId, userId)
+
+	return err
+}
+
+func (r *repo) GetByBook(ctx context.Context, bookId int) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0)
+
+	query := `select t.id, t.name, t.status, t.created_by, t.moderated_by, t.created_at, t.moderated_at
+			  from tags t
+			  join book_tags bt on bt.tag_id = t.id
+			  where bt.book_id = $1 and t.status = 'approved'
+			  order by t.name`
+
+	err := r.db.SelectContext(ctx, &tags, query, bookId)
+
+	return tags, err
+}
+
+func (r *repo) Moderate(ctx context.Context, id int, status string, moderatorId int) error {
+	query := "update tags set status = $1, moderated_by = $2, moderated_at = $3 where id = $4"
+
+	res, err := r.db.ExecContext(ctx, query, status, moderatorId, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
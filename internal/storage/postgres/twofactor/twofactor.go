@@ -0,0 +1,197 @@
+package twofactor
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/twofactor"
+)
+
+type Repo interface {
+	SetPendingSecret(ctx context.Context, userId int, secret string) error
+	Enable(ctx context.Context, userId int, step int64, codeHashes []string) error
+	Disable(ctx context.Context, userId int) error
+	// UseStep запоминает принятый шаг TOTP и возвращает false, если код с этим шагом уже использовали
+	UseStep(ctx context.Context, userId int, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) error
+	RecoveryCodesLeft(ctx context.Context, userId int) (int, error)
+
+	Policies(ctx context.Context) ([]model.Policy, error)
+	SetPolicy(ctx context.Context, policy model.Policy) error
+	IsRequired(ctx context.Context, role string) (bool, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) SetPendingSecret(ctx context.Context, userId int, secret string) error {
+	query := `update users
+			  set totp_secret = $1, totp_enabled_at = null, totp_last_step = null
+			  where id = $2 and deleted_at is null`
+
+	_, err := r.db.ExecContext(ctx, query, secret, userId)
+
+	return err
+}
+
+func (r *repo) Enable(ctx context.Context, userId int, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "update users set totp_enabled_at = $1, totp_last_step = $2 where id = $3 and totp_secret is not null"
+
+	if _, err := tx.ExecContext(ctx, query, time.Now(), step, userId); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repo) Disable(ctx context.Context, userId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "update users set totp_secret = null, totp_enabled_at = null, totp_last_step = null where id = $1"
+
+	if _, err := tx.ExecContext(ctx, query, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "delete from user_recovery_codes where user_id = $1", userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repo) UseStep(ctx context.Context, userId int, step int64) (bool, error) {
+	query := `update users set totp_last_step = $1
+			  where id = $2 and coalesce(totp_last_step, -1) < $1`
+
+	res, err := r.db.ExecContext(ctx, query, step, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected > 0, err
+}
+
+func (r *repo) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repo) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	query := `update user_recovery_codes set used_at = $1
+			  where user_id = $2 and code_hash = $3 and used_at is null`
+
+	res, err := r.db.ExecContext(ctx, query, time.Now(), userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *repo) RecoveryCodesLeft(ctx context.Context, userId int) (int, error) {
+	var count int
+
+	query := "select count(*) from user_recovery_codes where user_id = $1 and used_at is null"
+
+	err := r.db.GetContext(ctx, &count, query, userId)
+
+	return count, err
+}
+
+func (r *repo) Policies(ctx context.Context) ([]model.Policy, error) {
+	policies := make([]model.Policy, 0)
+
+	query := "select role, required, updated_by, updated_at from two_factor_policies order by role"
+
+	err := r.db.SelectContext(ctx, &policies, query)
+
+	return policies, err
+}
+
+func (r *repo) SetPolicy(ctx context.Context, policy model.Policy) error {
+	query := `insert into two_factor_policies (role, required, updated_by, updated_at)
+			  values ($1, $2, $3, $4)
+			  on conflict (role) do update
+			  set required = excluded.required,
+			      updated_by = excluded.updated_by,
+			      updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, query, policy.Role, policy.Required, policy.UpdatedBy, time.Now())
+
+	return err
+}
+
+func (r *repo) IsRequired(ctx context.Context, role string) (bool, error) {
+	var required bool
+
+	query := "select exists(select 1 from two_factor_policies where role = $1 and required)"
+
+	err := r.db.GetContext(ctx, &required, query, role)
+
+	return required, err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userId int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "delete from user_recovery_codes where user_id = $1", userId); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		query := "insert into user_recovery_codes (user_id, code_hash) values ($1, $2)"
+
+		if _, err := tx.ExecContext(ctx, query, userId, codeHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -13,10 +13,15 @@ import (
 
 type Repo interface {
 	Create(ctx context.Context, user *model.User) error
+	CreateTx(ctx context.Context, tx *sqlx.Tx, user *model.User) error
 	Get(ctx context.Context, id int) (*dto.UserGetResponse, error)
-	Update(ctx context.Context, u model.User) error
+	Update(ctx context.Context, id int, req dto.UserUpdateRequest) error
 	Delete(ctx context.Context, id int) error
 	GetByEmail(ctx context.Context, email string) (model.User, error)
+	GetById(ctx context.Context, id int) (model.User, error)
+	MarkEmailVerifiedTx(ctx context.Context, tx *sqlx.Tx, id int) error
+	UpdatePassword(ctx context.Context, id int, password string) error
+	UpdatePasswordTx(ctx context.Context, tx *sqlx.Tx, id int, password string) error
 }
 
 type repo struct {
@@ -31,46 +36,59 @@ func New(db *sqlx.DB) Repo {
 	return result
 }
 
-func (r *repo) Create(ctx context.Context, user *model.User) error {
-	query := `insert into users 
-				(name, phone_number, email, password, role, photo, created_at) 
-			  values ($1, $2, $3, $4, $5, $6, $7)`
+const createQuery = `insert into users 
+				(name, phone_number, email, password, role, photo, email_verified_at, created_at, locale) 
+			  values ($1, $2, $3, $4, $5, $6, $7, $8, coalesce(nullif($9, ''), 'ru'))
+			  returning id`
 
-	_, err := r.db.ExecContext(
+func (r *repo) Create(ctx context.Context, user *model.User) error {
+	return r.db.QueryRowxContext(
 		ctx,
-		query,
+		createQuery,
 		user.Name,
 		user.PhoneNumber,
 		user.Email,
 		user.Password,
 		user.Role,
 		user.Photo,
+		user.EmailVerifiedAt,
 		user.CreatedAt,
-	)
+		user.Locale,
+	).Scan(&user.Id)
+}
 
-	return err
+func (r *repo) CreateTx(ctx context.Context, tx *sqlx.Tx, user *model.User) error {
+	return tx.QueryRowxContext(
+		ctx,
+		createQuery,
+		user.Name,
+		user.PhoneNumber,
+		user.Email,
+		user.Password,
+		user.Role,
+		user.Photo,
+		user.EmailVerifiedAt,
+		user.CreatedAt,
+		user.Locale,
+	).Scan(&user.Id)
 }
 
-func (r *repo) Update(ctx context.Context, user model.User) error {
+func (r *repo) Update(ctx context.Context, id int, req dto.UserUpdateRequest) error {
 	query := `update users
           set name = $1, 
               phone_number = $2, 
-              email = $3, 
-              password = $4,
-              photo = $5,
-              role = $6
-          where id = $7`
+              photo = $3,
+              locale = coalesce($4, locale)
+          where id = $5 and deleted_at is null`
 
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
-		user.Name,
-		user.PhoneNumber,
-		user.Email,
-		user.Password,
-		user.Photo,
-		user.Role,
-		user.Id,
+		req.Name,
+		req.PhoneNumber,
+		req.Photo,
+		req.Locale,
+		id,
 	)
 
 	return err
@@ -89,7 +107,7 @@ func (r *repo) Delete(ctx context.Context, id int) error {
 func (r *repo) Get(ctx context.Context, id int) (*dto.UserGetResponse, error) {
 	var user dto.UserGetResponse
 
-	query := "select name, phone_number, photo, email, role from users where id = $1 and deleted_at is null"
+	query := "select name, phone_number, photo, email, role, locale, email_verified_at from users where id = $1 and deleted_at is null"
 
 	err := r.db.GetContext(ctx, &user, query, id)
 	return &user, err
@@ -98,9 +116,43 @@ func (r *repo) Get(ctx context.Context, id int) (*dto.UserGetResponse, error) {
 func (r *repo) GetByEmail(ctx context.Context, email string) (model.User, error) {
 	var user model.User
 
-	query := "select id, name, phone_number, photo, email, password, role, created_at, deleted_at from users where email = $1 and deleted_at is null"
+	query := "select id, name, phone_number, photo, email, password, role, locale, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, created_at, deleted_at from users where email = $1 and deleted_at is null"
 
 	err := r.db.GetContext(ctx, &user, query, email)
 
 	return user, err
 }
+
+func (r *repo) GetById(ctx context.Context, id int) (model.User, error) {
+	var user model.User
+
+	query := "select id, name, phone_number, photo, email, password, role, locale, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, created_at, deleted_at from users where id = $1 and deleted_at is null"
+
+	err := r.db.GetContext(ctx, &user, query, id)
+
+	return user, err
+}
+
+func (r *repo) MarkEmailVerifiedTx(ctx context.Context, tx *sqlx.Tx, id int) error {
+	query := "update users set email_verified_at = coalesce(email_verified_at, $1) where id = $2"
+
+	_, err := tx.ExecContext(ctx, query, time.Now(), id)
+
+	return err
+}
+
+func (r *repo) UpdatePassword(ctx context.Context, id int, password string) error {
+	query := "update users set password = $1 where id = $2 and deleted_at is null"
+
+	_, err := r.db.ExecContext(ctx, query, password, id)
+
+	return err
+}
+
+func (r *repo) UpdatePasswordTx(ctx context.Context, tx *sqlx.Tx, id int, password string) error {
+	query := "update users set password = $1 where id = $2 and deleted_at is null"
+
+	_, err := tx.ExecContext(ctx, query, password, id)
+
+	return err
+}
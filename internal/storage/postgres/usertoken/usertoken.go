@@ -0,0 +1,82 @@
+package usertoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	model "nevermore/internal/model/usertoken"
+)
+
+type Repo interface {
+	Create(ctx context.Context, token *model.Token) error
+	// Reserve запоминает запрос письма и возвращает false, если прошлый запрос был меньше cooldown назад
+	Reserve(ctx context.Context, userId int, purpose string, cooldown time.Duration) (bool, error)
+	ConsumeTx(ctx context.Context, tx *sqlx.Tx, tokenHash, purpose string) (int, error)
+	RevokeTx(ctx context.Context, tx *sqlx.Tx, userId int, purpose string) error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repo {
+	result := &repo{
+		db: db,
+	}
+
+	return result
+}
+
+func (r *repo) Create(ctx context.Context, token *model.Token) error {
+	query := `insert into user_tokens (user_id, purpose, token_hash, expires_at)
+			  values ($1, $2, $3, $4)
+			  returning id, created_at`
+
+	return r.db.QueryRowxContext(ctx, query, token.UserId, token.Purpose, token.TokenHash, token.ExpiresAt).
+		Scan(&token.Id, &token.CreatedAt)
+}
+
+// Reserve проверяет и сдвигает время запроса одним upsert, поэтому из одновременных
+// запросов проходит только один
+func (r *repo) Reserve(ctx context.Context, userId int, purpose string, cooldown time.Duration) (bool, error) {
+	query := `insert into user_token_requests (user_id, purpose, requested_at)
+			  values ($1, $2, now())
+			  on conflict (user_id, purpose) do update
+			  set requested_at = excluded.requested_at
+			  where user_token_requests.requested_at <= excluded.requested_at - $3 * interval '1 millisecond'`
+
+	res, err := r.db.ExecContext(ctx, query, userId, purpose, cooldown.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+
+	return affected > 0, err
+}
+
+// ConsumeTx помечает действующий токен использованным и возвращает его владельца.
+// Повторное или просроченное использование даёт sql.ErrNoRows
+func (r *repo) ConsumeTx(ctx context.Context, tx *sqlx.Tx, tokenHash, purpose string) (int, error) {
+	var userId int
+
+	query := `update user_tokens
+			  set used_at = $1
+			  where token_hash = $2 and purpose = $3 and used_at is null and expires_at > $1
+			  returning user_id`
+
+	err := tx.GetContext(ctx, &userId, query, time.Now(), tokenHash, purpose)
+
+	return userId, err
+}
+
+// RevokeTx гасит остальные действующие токены пользователя с тем же назначением
+func (r *repo) RevokeTx(ctx context.Context, tx *sqlx.Tx, userId int, purpose string) error {
+	query := "update user_tokens set used_at = $1 where user_id = $2 and purpose = $3 and used_at is null"
+
+	_, err := tx.ExecContext(ctx, query, time.Now(), userId, purpose)
+
+	return err
+}
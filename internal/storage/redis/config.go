@@ -0,0 +1,8 @@
+package redis
+
+type Config struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+}
@@ -0,0 +1,95 @@
+package loginattempt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	model "nevermore/internal/model/loginattempt"
+)
+
+const keyPrefix = "login:"
+
+type Repo interface {
+	State(ctx context.Context, subject model.Subject) (model.State, error)
+	// Fail увеличивает счётчик неудач. Окно отсчитывается от первой неудачи
+	Fail(ctx context.Context, subject model.Subject, window time.Duration) (int, error)
+	Delay(ctx context.Context, subject model.Subject, ttl time.Duration) error
+	Lock(ctx context.Context, subject model.Subject, ttl time.Duration) error
+	Reset(ctx context.Context, subject model.Subject) error
+}
+
+type repo struct {
+	client *goredis.Client
+}
+
+func New(client *goredis.Client) Repo {
+	result := &repo{
+		client: client,
+	}
+
+	return result
+}
+
+func (r *repo) State(ctx context.Context, subject model.Subject) (model.State, error) {
+	var state model.State
+
+	pipe := r.client.Pipeline()
+	failures := pipe.Get(ctx, failuresKey(subject))
+	locked := pipe.PTTL(ctx, lockKey(subject))
+	delay := pipe.PTTL(ctx, delayKey(subject))
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, goredis.Nil) {
+		return state, err
+	}
+
+	count, err := failures.Int()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return state, err
+	}
+
+	state.Failures = count
+	// Для отсутствующего ключа PTTL возвращает отрицательное значение
+	state.LockedFor = max(locked.Val(), 0)
+	state.DelayFor = max(delay.Val(), 0)
+
+	return state, nil
+}
+
+func (r *repo) Fail(ctx context.Context, subject model.Subject, window time.Duration) (int, error) {
+	pipe := r.client.TxPipeline()
+	count := pipe.Incr(ctx, failuresKey(subject))
+	pipe.ExpireNX(ctx, failuresKey(subject), window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return int(count.Val()), nil
+}
+
+func (r *repo) Delay(ctx context.Context, subject model.Subject, ttl time.Duration) error {
+	return r.client.Set(ctx, delayKey(subject), 1, ttl).Err()
+}
+
+func (r *repo) Lock(ctx context.Context, subject model.Subject, ttl time.Duration) error {
+	return r.client.Set(ctx, lockKey(subject), 1, ttl).Err()
+}
+
+func (r *repo) Reset(ctx context.Context, subject model.Subject) error {
+	return r.client.Del(ctx, failuresKey(subject), lockKey(subject), delayKey(subject)).Err()
+}
+
+func failuresKey(subject model.Subject) string {
+	return keyPrefix + "failures:" + string(subject)
+}
+
+func lockKey(subject model.Subject) string {
+	return keyPrefix + "lock:" + string(subject)
+}
+
+func delayKey(subject model.Subject) string {
+	return keyPrefix + "delay:" + string(subject)
+}
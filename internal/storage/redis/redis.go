@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"nevermore/internal/storage/redis/loginattempt"
+	"nevermore/pkg/logger"
+)
+
+const pingTimeout = 5 * time.Second
+
+type Repo interface {
+	Client() *goredis.Client
+	Ping(ctx context.Context) error
+	Close() error
+	LoginAttempt() loginattempt.Repo
+}
+
+type repo struct {
+	client       *goredis.Client
+	loginAttempt loginattempt.Repo
+}
+
+// NewRedis не требует доступного Redis при запуске: клиент подключается при первом запросе,
+// а лимитер, кэш и счётчик неудачных входов переживают его отказ. Проверка при запуске
+// только пишет в лог, чтобы отказ был виден сразу
+func NewRedis(cfg Config) (Repo, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	log := logger.Named("redis")
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Warn().Err(err).Str("addr", cfg.Addr).Msg("redis is unavailable, falling back until it is reachable")
+	} else {
+		log.Info().Str("addr", cfg.Addr).Msg("connected to redis")
+	}
+
+	result := &repo{
+		client:       client,
+		loginAttempt: loginattempt.New(client),
+	}
+	return result, nil
+}
+
+func (r *repo) Client() *goredis.Client {
+	return r.client
+}
+
+func (r *repo) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *repo) Close() error {
+	return r.client.Close()
+}
+
+func (r *repo) LoginAttempt() loginattempt.Repo {
+	return r.loginAttempt
+}
@@ -1,38 +1,65 @@
 package storage
 
 import (
+	"errors"
+
+	"nevermore/internal/storage/cache"
 	"nevermore/internal/storage/postgres"
+	"nevermore/internal/storage/redis"
 )
 
 //go:generate mockery --name=Storage --dir=. --output=./mocks
 type Storage interface {
 	DB() postgres.Repo
+	Redis() redis.Repo
+	Cache() cache.Cache
+	// Close закрывает соединения с Postgres и Redis при остановке
+	Close() error
 }
 
 type repo struct {
-	psql postgres.Repo
+	psql  postgres.Repo
+	redis redis.Repo
+	cache cache.Cache
 }
 
 func (r *repo) DB() postgres.Repo {
 	return r.psql
 }
 
-func New(pcfg postgres.Config) (Storage, error) {
+func (r *repo) Redis() redis.Repo {
+	return r.redis
+}
+
+func (r *repo) Cache() cache.Cache {
+	return r.cache
+}
+
+func (r *repo) Close() error {
+	return errors.Join(r.psql.Close(), r.redis.Close())
+}
+
+func New(pcfg postgres.Config, rcfg redis.Config, cacheBackend string) (Storage, error) {
 	psql, err := postgres.NewDB(pcfg)
 	if err != nil {
 		return nil, err
 	}
 
+	rds, err := redis.NewRedis(rcfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if err != nil {
-		return nil, err
+	// Кэш в памяти годится только для одного экземпляра: инвалидация не доходит до соседей
+	c := cache.NewRedis(rds.Client())
+	if cacheBackend == cache.BackendMemory {
+		c = cache.NewMemory()
 	}
 
 	result := &repo{
-		psql: psql,
+		psql:  psql,
+		redis: rds,
+		cache: c,
 	}
 	return result, nil
 }
@@ -0,0 +1,146 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	apiTokenService "nevermore/internal/service/apitoken"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List personal access tokens
+// @Description Get personal access tokens of the current user. Token values are never returned again
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} apitoken.Token "Tokens"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Personal access tokens are not allowed"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/tokens [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.srv.APIToken().List(ctx, userId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, tokens)
+}
+
+// @Summary Create personal access token
+// @Description Create a named token for scripts and integrations. Scopes: read:shelf, write:shelf, admin:* (moderators and admins only). The token value is shown only once
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param token body dto.CreateAPITokenRequest true "Token name, scopes and lifetime (default 90 days, max 365)"
+// @Success 201 {object} dto.CreateAPITokenResponse "Created token"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Scope is not allowed for your role"
+// @Failure 409 {object} string "Token limit reached"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/tokens [post]
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid token data"})
+		return
+	}
+
+	token, err := h.srv.APIToken().Create(ctx, userId, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, token)
+}
+
+// @Summary Revoke personal access token
+// @Description Delete a personal access token of the current user
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} string "Token revoked successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Token not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/tokens/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid token id"})
+		return
+	}
+
+	if err := h.srv.APIToken().Delete(ctx, userId, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Token revoked successfully"})
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apiTokenService.ErrInvalidToken),
+		errors.Is(err, apiTokenService.ErrInvalidExpiry):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, apiTokenService.ErrScopeForbidden):
+		c.JSON(403, gin.H{"error": err.Error()})
+	case errors.Is(err, apiTokenService.ErrTokenNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, apiTokenService.ErrTooManyTokens):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
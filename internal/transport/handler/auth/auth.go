@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	authService "nevermore/internal/service/auth"
+	"nevermore/internal/service/loginguard"
+	twoFactorService "nevermore/internal/service/twofactor"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary Sign up
+// @Description Create an account and send an email verification link. Language of the email follows Accept-Language
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body dto.SignUpRequest true "Account data"
+// @Success 201 {object} dto.AuthResponse "Access token"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 409 {object} string "User already exists"
+// @Failure 429 {object} string "Rate limit exceeded"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/sign-up [post]
+func (h *Handler) SignUp(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.SignUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid sign up data"})
+		return
+	}
+
+	result, err := h.srv.Auth().SignUp(ctx, req, c.GetHeader("Accept-Language"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, result)
+}
+
+// @Summary Sign in
+// @Description Exchange email and password for an access token. With 2FA enabled a challenge token for the second step is returned instead. Repeated failures slow down and then temporarily block sign in for the account and the client IP, the owner gets an unlock link by email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body dto.SignInRequest true "Credentials"
+// @Success 200 {object} dto.AuthResponse "Access token"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Invalid email or password"
+// @Failure 429 {object} string "Too many attempts, see Retry-After"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/sign-in [post]
+func (h *Handler) SignIn(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.SignInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	result, err := h.srv.Auth().SignIn(ctx, req, c.ClientIP(), c.GetHeader("Accept-Language"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Complete sign in with two-factor code
+// @Description Exchange the challenge token from sign in and a code from the authenticator app (or a recovery code) for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body dto.SignInTwoFactorRequest true "Challenge token and code"
+// @Success 200 {object} dto.AuthResponse "Access token"
+// @Failure 400 {object} string "Invalid code or challenge token"
+// @Failure 429 {object} string "Too many attempts, see Retry-After"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/sign-in/2fa [post]
+func (h *Handler) SignInTwoFactor(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.SignInTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Challenge token and code are required"})
+		return
+	}
+
+	result, err := h.srv.Auth().SignInTwoFactor(ctx, req, c.ClientIP(), c.GetHeader("Accept-Language"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Verify email
+// @Description Confirm the email address with the single-use token from the verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} string "Email verified successfully"
+// @Failure 400 {object} string "Token is invalid, expired or already used"
+// @Failure 429 {object} string "Rate limit exceeded"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/verify-email [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Token is required"})
+		return
+	}
+
+	if err := h.srv.Auth().VerifyEmail(ctx, req.Token); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Email verified successfully"})
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification link to the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} string "Verification email sent"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 409 {object} string "Email is already verified"
+// @Failure 429 {object} string "Too many requests"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/verify-email/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Auth().SendVerification(ctx, userId, c.GetHeader("Accept-Language")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Verification email sent"})
+}
+
+// @Summary Forgot password
+// @Description Send a password reset link if an account with this email exists. The response is the same either way
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param email body dto.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} string "Reset link sent if the account exists"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 429 {object} string "Rate limit exceeded"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/forgot-password [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Email is required"})
+		return
+	}
+
+	if err := h.srv.Auth().ForgotPassword(ctx, req.Email, c.GetHeader("Accept-Language")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "If the account exists, a reset link has been sent"})
+}
+
+// @Summary Reset password
+// @Description Set a new password with the single-use token from the reset link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param reset body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} string "Password reset successfully"
+// @Failure 400 {object} string "Token is invalid or password is too weak"
+// @Failure 429 {object} string "Rate limit exceeded"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/reset-password [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Token and password are required"})
+		return
+	}
+
+	if err := h.srv.Auth().ResetPassword(ctx, req.Token, req.Password); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Password reset successfully"})
+}
+
+// @Summary Unlock sign in
+// @Description Lift the temporary sign in block with the single-use token from the lockout email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dto.UnlockAccountRequest true "Unlock token"
+// @Success 200 {object} string "Sign in unlocked"
+// @Failure 400 {object} string "Token is invalid, expired or already used"
+// @Failure 429 {object} string "Rate limit exceeded"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/unlock-account [post]
+func (h *Handler) UnlockAccount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Token is required"})
+		return
+	}
+
+	if err := h.srv.Auth().UnlockAccount(ctx, req.Token); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Sign in unlocked"})
+}
+
+func respondError(c *gin.Context, err error) {
+	var attemptsErr *loginguard.AttemptsError
+	if errors.As(err, &attemptsErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(attemptsErr.RetryAfter.Seconds()))))
+		c.JSON(429, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch {
+	case errors.Is(err, authService.ErrInvalidSignUp),
+		errors.Is(err, authService.ErrWeakPassword),
+		errors.Is(err, authService.ErrInvalidToken),
+		errors.Is(err, twoFactorService.ErrInvalidCode),
+		errors.Is(err, twoFactorService.ErrNotEnabled):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, authService.ErrInvalidCredentials):
+		c.JSON(401, gin.H{"error": err.Error()})
+	case errors.Is(err, authService.ErrUserExists),
+		errors.Is(err, authService.ErrAlreadyVerified):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, authService.ErrTooManyRequests):
+		c.JSON(429, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,333 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	authorService "nevermore/internal/service/author"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List authors
+// @Description Get authors ordered by name
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {array} author.Author "Authors"
+// @Failure 400 {object} string "Bad request - invalid paging"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	limit, err := params.QueryInt(c, "limit")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	offset, err := params.QueryInt(c, "offset")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	authors, err := h.srv.Author().List(ctx, limit, offset)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, authors)
+}
+
+// @Summary Get author
+// @Description Get author profile with alternate names and transliterations
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Success 200 {object} author.Author "Author profile"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 404 {object} string "Author not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	author, err := h.srv.Author().Get(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, author)
+}
+
+// @Summary Create author
+// @Description Create an author profile (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param author body dto.AuthorRequest true "Author data"
+// @Success 201 {object} author.Author "Created author"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors [post]
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.AuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author data"})
+		return
+	}
+
+	author, err := h.srv.Author().Create(ctx, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, author)
+}
+
+// @Summary Update author
+// @Description Update an author profile (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Param author body dto.AuthorRequest true "Author data"
+// @Success 200 {object} author.Author "Updated author"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Author not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	var req dto.AuthorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author data"})
+		return
+	}
+
+	author, err := h.srv.Author().Update(ctx, id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, author)
+}
+
+// @Summary Delete author
+// @Description Delete an author without books (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Success 200 {object} string "Author deleted successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Author not found"
+// @Failure 409 {object} string "Author has books"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	if err := h.srv.Author().Delete(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Author deleted successfully"})
+}
+
+// @Summary Add author alias
+// @Description Add an alternate name, transliteration or pseudonym (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Param alias body dto.AuthorAliasRequest true "Alias data"
+// @Success 201 {object} author.Alias "Created alias"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Author not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id}/aliases [post]
+func (h *Handler) AddAlias(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	var req dto.AuthorAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid alias data"})
+		return
+	}
+
+	alias, err := h.srv.Author().AddAlias(ctx, id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(201, alias)
+}
+
+// @Summary Delete author alias
+// @Description Delete an alternate name of an author (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Param aliasId path int true "Alias ID"
+// @Success 200 {object} string "Alias deleted successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Alias not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id}/aliases/{aliasId} [delete]
+func (h *Handler) DeleteAlias(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	aliasId, err := strconv.Atoi(c.Param("aliasId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid alias id"})
+		return
+	}
+
+	if err := h.srv.Author().DeleteAlias(ctx, id, aliasId); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Alias deleted successfully"})
+}
+
+// @Summary Merge duplicate authors
+// @Description Merge the source author into this one: books and followers are reassigned, the source name is kept as an alias and the source is deleted (moderators only)
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Target author ID"
+// @Param merge body dto.MergeAuthorsRequest true "Duplicate author to merge"
+// @Success 200 {object} author.Author "Merged author"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Author not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id}/merge [post]
+func (h *Handler) Merge(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	var req dto.MergeAuthorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid merge data"})
+		return
+	}
+
+	author, err := h.srv.Author().Merge(ctx, id, req.SourceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, author)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, authorService.ErrInvalidAuthor),
+		errors.Is(err, authorService.ErrInvalidAlias),
+		errors.Is(err, authorService.ErrSelfMerge):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, authorService.ErrAuthorNotFound),
+		errors.Is(err, authorService.ErrAliasNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, authorService.ErrAuthorHasBooks):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
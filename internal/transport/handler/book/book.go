@@ -0,0 +1,335 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	bookService "nevermore/internal/service/book"
+	bookTextService "nevermore/internal/service/booktext"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary Get book
+// @Description Get book details with the full list of contributors
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} book.Book "Book"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 404 {object} string "Book not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	book, err := h.srv.Book().Get(ctx, id)
+	if errors.Is(err, bookService.ErrBookNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, book)
+}
+
+// @Summary Get book rating
+// @Description Get the number of reader ratings, the average and the distribution by score (1-10)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} dto.BookRating "Rating summary"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 404 {object} string "Book not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/rating [get]
+func (h *Handler) Rating(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	rating, err := h.srv.Book().Rating(ctx, id)
+	if errors.Is(err, bookService.ErrBookNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, rating)
+}
+
+// @Summary Set book contributors
+// @Description Replace book contributors (authors, translators, editors, illustrators) in credit order (moderators only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Param contributors body dto.SetBookContributorsRequest true "Contributors in credit order"
+// @Success 200 {array} book.Contributor "Book contributors"
+// @Failure 400 {object} string "Bad request - invalid data or unknown author"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Book not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/contributors [put]
+func (h *Handler) SetContributors(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	var req dto.SetBookContributorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid contributors data"})
+		return
+	}
+
+	contributors, err := h.srv.Book().SetContributors(ctx, id, req)
+	switch {
+	case errors.Is(err, bookService.ErrInvalidContributor), errors.Is(err, bookService.ErrNoAuthor),
+		errors.Is(err, bookService.ErrUnknownAuthor):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, bookService.ErrBookNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, contributors)
+}
+
+// @Summary List books
+// @Description List catalog books filtered by genre (including subgenres), approved tag, series or contributor
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param genre_id query int false "Genre ID"
+// @Param tag query string false "Tag name"
+// @Param series_id query int false "Series ID"
+// @Param author_id query int false "Contributor author ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.BookListResponse "Books"
+// @Failure 400 {object} string "Bad request - invalid filter"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	filter := dto.BookListFilter{
+		Tag: c.Query("tag"),
+	}
+
+	var err error
+
+	if filter.GenreId, err = queryOptionalInt(c, "genre_id"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid genre_id"})
+		return
+	}
+
+	if filter.SeriesId, err = queryOptionalInt(c, "series_id"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid series_id"})
+		return
+	}
+
+	if filter.AuthorId, err = queryOptionalInt(c, "author_id"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author_id"})
+		return
+	}
+
+	if filter.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if filter.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.Book().List(ctx, filter)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Search inside a book
+// @Description Full-text search over the extracted text of a single book with context snippets and locations
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.BookTextSearchResponse "Search hits"
+// @Failure 400 {object} string "Bad request - invalid query"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	req := dto.BookTextSearchRequest{
+		BookId: bookId,
+		Query:  c.Query("q"),
+	}
+
+	if req.Query == "" {
+		c.JSON(400, gin.H{"error": "Query is required"})
+		return
+	}
+
+	if req.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if req.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.BookText().Search(ctx, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Reindex book text
+// @Description Queue extraction of the book text from its file for in-book search. EPUB, FB2 and plain text are supported (admins only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Success 202 {object} string "Book queued for indexing"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Book not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/books/{id}/text-index [post]
+func (h *Handler) Reindex(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	err = h.srv.BookText().Reindex(ctx, bookId)
+	if errors.Is(err, bookTextService.ErrBookNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(202, gin.H{"message": "Book queued for indexing"})
+}
+
+// @Summary Back-fill book text index
+// @Description Queue text extraction for every book that has no text index yet (admins only)
+// @Tags books
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202 {object} map[string]int "Number of queued books"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/books/text-index [post]
+func (h *Handler) Backfill(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	queued, err := h.srv.BookText().Backfill(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(202, gin.H{"queued": queued})
+}
+
+func queryOptionalInt(c *gin.Context, key string) (*int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,144 @@
+package flag
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	flagService "nevermore/internal/service/flag"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary My feature flags
+// @Description Get every feature flag with whether it is on for the current user
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]bool "Flag values"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/flags [get]
+func (h *Handler) Mine(c *gin.Context) {
+	subject, ok := middleware.FlagSubject(c, h.srv)
+	if !ok {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(200, h.srv.Flag().For(subject))
+}
+
+// @Summary List feature flags
+// @Description Get all feature flags with their targeting rules (admins only)
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} flags.Flag "Feature flags"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/flags [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	list, err := h.srv.Flag().List(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, list)
+}
+
+// @Summary Create or update feature flag
+// @Description Set a flag: a disabled flag is off for everyone; an enabled one is on for the listed roles and users and for the given percentage of other users, sticky per user (admins only)
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Flag name (snake_case)"
+// @Param flag body dto.FlagRequest true "Flag rules"
+// @Success 200 {object} flags.Flag "Saved flag"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/flags/{name} [put]
+func (h *Handler) Put(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.FlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid flag data"})
+		return
+	}
+
+	flag, err := h.srv.Flag().Put(ctx, userId, c.Param("name"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, flag)
+}
+
+// @Summary Delete feature flag
+// @Description Delete a flag; code checking it sees it as off (admins only)
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "Flag name"
+// @Success 200 {object} string "Flag deleted successfully"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Flag not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/flags/{name} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	if err := h.srv.Flag().Delete(ctx, c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Flag deleted successfully"})
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, flagService.ErrInvalidFlag):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, flagService.ErrFlagNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
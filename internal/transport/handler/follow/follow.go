@@ -0,0 +1,131 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/service"
+	followService "nevermore/internal/service/follow"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary Follow author
+// @Description Follow an author to get notified about their new books
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Success 200 {object} string "Author followed successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Author not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id}/follow [post]
+func (h *Handler) Follow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	authorId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	err = h.srv.Follow().Follow(ctx, userId, authorId)
+	if errors.Is(err, followService.ErrAuthorNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Author followed successfully"})
+}
+
+// @Summary Unfollow author
+// @Description Stop following an author
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Author ID"
+// @Success 200 {object} string "Author unfollowed successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /authors/{id}/follow [delete]
+func (h *Handler) Unfollow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	authorId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid author id"})
+		return
+	}
+
+	if err := h.srv.Follow().Unfollow(ctx, userId, authorId); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Author unfollowed successfully"})
+}
+
+// @Summary My authors
+// @Description Get authors followed by the current user
+// @Tags follow
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} dto.FollowedAuthor "Followed authors"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/authors [get]
+func (h *Handler) MyAuthors(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	authors, err := h.srv.Follow().MyAuthors(ctx, userId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, authors)
+}
@@ -0,0 +1,182 @@
+package genre
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	genreService "nevermore/internal/service/genre"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List genres
+// @Description Get the curated genre hierarchy as a tree
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Success 200 {array} genre.Genre "Genre tree"
+// @Failure 500 {object} string "Internal server error"
+// @Router /genres [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	genres, err := h.srv.Genre().Tree(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, genres)
+}
+
+// @Summary Create genre
+// @Description Create a curated genre, optionally nested under a parent genre (moderators only)
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param genre body dto.CreateGenreRequest true "Genre data"
+// @Success 201 {object} genre.Genre "Created genre"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /genres [post]
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.CreateGenreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid genre data"})
+		return
+	}
+
+	genre, err := h.srv.Genre().Create(ctx, req)
+	if errors.Is(err, genreService.ErrInvalidGenre) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, genre)
+}
+
+// @Summary Delete genre
+// @Description Delete a genre together with its subgenres (moderators only)
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Genre ID"
+// @Success 200 {object} string "Genre deleted successfully"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /genres/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid genre id"})
+		return
+	}
+
+	if err := h.srv.Genre().Delete(ctx, id); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Genre deleted successfully"})
+}
+
+// @Summary Get book genres
+// @Description Get genres assigned to a book
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} genre.Genre "Book genres"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/genres [get]
+func (h *Handler) GetByBook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	genres, err := h.srv.Genre().GetByBook(ctx, bookId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, genres)
+}
+
+// @Summary Set book genres
+// @Description Replace the genres assigned to a book (moderators only)
+// @Tags genres
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Param genres body dto.SetBookGenresRequest true "Genre IDs"
+// @Success 200 {object} string "Book genres updated successfully"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/genres [put]
+func (h *Handler) SetBookGenres(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	var req dto.SetBookGenresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid genres data"})
+		return
+	}
+
+	if err := h.srv.Genre().SetBookGenres(ctx, bookId, req.GenreIds); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Book genres updated successfully"})
+}
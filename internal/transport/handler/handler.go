@@ -1,10 +1,31 @@
 package handler
 
 import (
+	apiTokenModel "nevermore/internal/model/apitoken"
+	userModel "nevermore/internal/model/user"
 	"nevermore/internal/service"
+	"nevermore/internal/transport/handler/apitoken"
+	"nevermore/internal/transport/handler/auth"
+	"nevermore/internal/transport/handler/author"
+	"nevermore/internal/transport/handler/book"
+	"nevermore/internal/transport/handler/flag"
+	"nevermore/internal/transport/handler/follow"
+	"nevermore/internal/transport/handler/genre"
+	"nevermore/internal/transport/handler/health"
+	"nevermore/internal/transport/handler/identity"
+	"nevermore/internal/transport/handler/job"
+	"nevermore/internal/transport/handler/notification"
+	"nevermore/internal/transport/handler/search"
+	"nevermore/internal/transport/handler/series"
+	"nevermore/internal/transport/handler/shelf"
+	"nevermore/internal/transport/handler/tag"
+	"nevermore/internal/transport/handler/twofactor"
 	"nevermore/internal/transport/handler/user"
 	middleware2 "nevermore/internal/transport/middleware"
-	"time"
+	"nevermore/pkg/flags"
+	healthCheck "nevermore/pkg/health"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -16,24 +37,216 @@ type Handler struct {
 	router *gin.Engine
 }
 
-func New(serv service.Service) *gin.Engine {
+// Выключатели возможностей из секции features конфигурации
+const (
+	featureOIDCLogin   = "oidc_login"
+	featureShelfImport = "shelf_import"
+)
+
+func New(serv service.Service, limiter ratelimit.Limiter, policies *ratelimit.PolicyStore, toggles *flags.Toggles, checker *healthCheck.Checker, trustedProxies []string) (*gin.Engine, error) {
+	router, err := newRouter(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	handler := &Handler{
 		serv:   serv,
-		router: gin.New(),
+		router: router,
 	}
 
+	// Пробы ставятся до общих middleware: балансировщик дёргает их каждые несколько секунд,
+	// и в журнале доступа, трассах и метриках они были бы шумом
+	healthHandler := health.New(checker)
+	handler.router.GET("/healthz", healthHandler.Live)
+	handler.router.GET("/readyz", healthHandler.Ready)
+
+	handler.router.Use(middleware2.RequestID(), middleware2.Tracing(), middleware2.AccessLog(), middleware2.Metrics())
+
+	// Метрики для prometheus. Наружу маршрут закрывается на прокси
+	handler.router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	//добавление СВАГИ
 	handler.router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	authHandler := auth.New(serv)
 	userHandler := user.New(serv)
+	searchHandler := search.New(serv)
+	bookHandler := book.New(serv)
+	genreHandler := genre.New(serv)
+	tagHandler := tag.New(serv)
+	seriesHandler := series.New(serv)
+	authorHandler := author.New(serv)
+	followHandler := follow.New(serv)
+	notificationHandler := notification.New(serv)
+	twoFactorHandler := twofactor.New(serv)
+	identityHandler := identity.New(serv)
+	apiTokenHandler := apitoken.New(serv)
+	shelfHandler := shelf.New(serv)
+	flagHandler := flag.New(serv)
+	jobHandler := job.New(serv)
+
+	rateLimiter := middleware2.RateLimiter(serv, limiter, policies)
+	oidcLogin := middleware2.Feature(toggles, featureOIDCLogin)
+
+	// Для публичных маршрутов аутентификации в конфиге заданы строгие лимиты по IP
+	// от перебора и рассылки писем
+	authGroup := handler.router.Group("/auth")
+	authGroup.Use(rateLimiter)
+	{
+		authGroup.POST("/sign-up", authHandler.SignUp)
+		authGroup.POST("/sign-in", authHandler.SignIn)
+		authGroup.POST("/sign-in/2fa", authHandler.SignInTwoFactor)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+		authGroup.POST("/reset-password", authHandler.ResetPassword)
+		authGroup.POST("/unlock-account", authHandler.UnlockAccount)
+
+		authGroup.GET("/oidc/providers", oidcLogin, identityHandler.Providers)
+		authGroup.GET("/oidc/:provider/login", oidcLogin, identityHandler.Login)
+		authGroup.GET("/oidc/:provider/callback", oidcLogin, identityHandler.Callback)
+	}
+
+	public := handler.router.Group("/")
+	public.Use(rateLimiter)
+	{
+		public.GET("/search", searchHandler.Search)
+
+		public.GET("/books", bookHandler.List)
+		public.GET("/books/:id", bookHandler.Get)
+		public.GET("/books/:id/rating", bookHandler.Rating)
+		public.GET("/books/:id/genres", genreHandler.GetByBook)
+		public.GET("/books/:id/tags", tagHandler.GetByBook)
+		public.GET("/genres", genreHandler.List)
+		public.GET("/tags", tagHandler.List)
+		public.GET("/series", seriesHandler.List)
+		public.GET("/series/:id", seriesHandler.Get)
+		public.GET("/authors", authorHandler.List)
+		public.GET("/authors/:id", authorHandler.Get)
+	}
 
 	protected := handler.router.Group("/")
-	protected.Use(middleware2.RateLimiter(1 * time.Second))
+	protected.Use(middleware2.Authenticate(serv), rateLimiter)
+
+	// Персональные токены пускаем только туда, где объявлена их область доступа
+	account := protected.Group("/")
+	account.Use(middleware2.RequireSession())
+	{
+		account.POST("/auth/verify-email/resend", authHandler.ResendVerification)
+
+		account.GET("/user/get", userHandler.Get)
+		account.POST("/user/update", userHandler.Update)
+		account.DELETE("/user/delete", userHandler.Delete)
+
+		account.GET("/books/:id/search", bookHandler.Search)
+
+		account.GET("/user/authors", followHandler.MyAuthors)
+		account.POST("/authors/:id/follow", followHandler.Follow)
+		account.DELETE("/authors/:id/follow", followHandler.Unfollow)
+
+		account.GET("/notifications", notificationHandler.List)
+		account.GET("/notifications/unread-count", notificationHandler.UnreadCount)
+		account.POST("/notifications/:id/read", notificationHandler.MarkRead)
+		account.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+		account.GET("/notifications/preferences", notificationHandler.Preferences)
+		account.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		account.GET("/user/2fa", twoFactorHandler.Status)
+		account.POST("/user/2fa/enroll", twoFactorHandler.Enroll)
+		account.POST("/user/2fa/confirm", twoFactorHandler.Confirm)
+		account.POST("/user/2fa/disable", twoFactorHandler.Disable)
+		account.POST("/user/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+		account.GET("/user/flags", flagHandler.Mine)
+
+		account.GET("/user/identities", identityHandler.List)
+		account.POST("/user/identities/:provider/link", oidcLogin, identityHandler.Link)
+		account.DELETE("/user/identities/:provider", identityHandler.Unlink)
+
+		account.GET("/user/tokens", apiTokenHandler.List)
+		account.POST("/user/tokens", apiTokenHandler.Create)
+		account.DELETE("/user/tokens/:id", apiTokenHandler.Delete)
+	}
+
+	// Создавать контент могут только пользователи с подтверждённой почтой
+	verified := account.Group("/")
+	verified.Use(middleware2.RequireVerifiedEmail(serv))
 	{
-		protected.GET("/user/get", userHandler.Get)
-		protected.POST("/user/update", userHandler.Update)
-		protected.DELETE("/user/delete", userHandler.Delete)
+		verified.POST("/books/:id/tags", tagHandler.AddToBook)
+	}
+
+	shelfGroup := protected.Group("/shelf")
+	{
+		shelfGroup.GET("", middleware2.RequireScope(apiTokenModel.ScopeReadShelf), shelfHandler.List)
+		shelfGroup.PUT("/:bookId", middleware2.RequireScope(apiTokenModel.ScopeWriteShelf), shelfHandler.Put)
+		shelfGroup.DELETE("/:bookId", middleware2.RequireScope(apiTokenModel.ScopeWriteShelf), shelfHandler.Delete)
+		shelfGroup.POST("/import", middleware2.Feature(toggles, featureShelfImport),
+			middleware2.RequireScope(apiTokenModel.ScopeWriteShelf), shelfHandler.Import)
+	}
+
+	moderation := protected.Group("/")
+	moderation.Use(
+		middleware2.RequireScope(apiTokenModel.ScopeAdmin),
+		middleware2.RequireRole(serv, userModel.RoleModerator, userModel.RoleAdmin),
+		middleware2.RequireTwoFactor(serv),
+	)
+	{
+		moderation.POST("/genres", genreHandler.Create)
+		moderation.DELETE("/genres/:id", genreHandler.Delete)
+		moderation.PUT("/books/:id/genres", genreHandler.SetBookGenres)
+
+		moderation.GET("/tags/pending", tagHandler.Pending)
+		moderation.POST("/tags/:id/moderate", tagHandler.Moderate)
+
+		moderation.POST("/series", seriesHandler.Create)
+		moderation.PUT("/books/:id/series", seriesHandler.SetBookSeries)
+		moderation.PUT("/books/:id/contributors", bookHandler.SetContributors)
+
+		moderation.POST("/authors", authorHandler.Create)
+		moderation.PUT("/authors/:id", authorHandler.Update)
+		moderation.DELETE("/authors/:id", authorHandler.Delete)
+		moderation.POST("/authors/:id/aliases", authorHandler.AddAlias)
+		moderation.DELETE("/authors/:id/aliases/:aliasId", authorHandler.DeleteAlias)
+		moderation.POST("/authors/:id/merge", authorHandler.Merge)
+	}
+
+	admin := protected.Group("/admin")
+	admin.Use(
+		middleware2.RequireScope(apiTokenModel.ScopeAdmin),
+		middleware2.RequireRole(serv, userModel.RoleAdmin),
+		middleware2.RequireTwoFactor(serv),
+	)
+	{
+		admin.GET("/2fa/policies", twoFactorHandler.Policies)
+		admin.PUT("/2fa/policies", twoFactorHandler.SetPolicy)
+
+		admin.GET("/flags", flagHandler.List)
+		admin.PUT("/flags/:name", flagHandler.Put)
+		admin.DELETE("/flags/:name", flagHandler.Delete)
+
+		admin.GET("/jobs", jobHandler.List)
+		admin.GET("/jobs/:id", jobHandler.Get)
+		admin.POST("/jobs/:id/retry", jobHandler.Retry)
+
+		admin.POST("/books/text-index", bookHandler.Backfill)
+		admin.POST("/books/:id/text-index", bookHandler.Reindex)
+	}
+
+	return handler.router, nil
+}
+
+// newRouter создаёт gin, который берёт IP клиента из X-Forwarded-For и X-Real-IP только
+// от доверенных прокси. По умолчанию gin верит заголовку от кого угодно, и подставной
+// адрес обходил бы счётчик неудачных входов и лимиты по IP
+func newRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.New()
+
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
 	}
 
-	return handler.router
+	return router, nil
 }
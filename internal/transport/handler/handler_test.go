@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	authService "nevermore/internal/service/auth"
+	userService "nevermore/internal/service/user"
+	"nevermore/internal/transport/handler/auth"
+	"nevermore/internal/transport/middleware"
+	"nevermore/pkg/ratelimit"
+)
+
+const clientAddr = "203.0.113.7"
+
+// Фейки встраивают интерфейсы и переопределяют только вход по паролю и чтение профиля
+
+type fakeService struct {
+	service.Service
+	auth  *fakeAuth
+	users *fakeUsers
+}
+
+func (s *fakeService) Auth() authService.Service { return s.auth }
+func (s *fakeService) User() userService.Service { return s.users }
+
+// fakeUsers не может прочитать профиль, как при недоступной базе
+type fakeUsers struct {
+	userService.Service
+}
+
+func (u *fakeUsers) Get(ctx context.Context, userId int) (*dto.UserGetResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+// fakeAuth считает неудачные входы по IP, как счётчик loginguard
+type fakeAuth struct {
+	authService.Service
+	failures map[string]int
+}
+
+func (a *fakeAuth) SignIn(ctx context.Context, req dto.SignInRequest, ip, locale string) (dto.AuthResponse, error) {
+	a.failures[ip]++
+
+	return dto.AuthResponse{}, authService.ErrInvalidCredentials
+}
+
+func signIn(t *testing.T, trustedProxies []string, remoteAddr string, forwardedFor []string) map[string]int {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	router, err := newRouter(trustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeAuth{failures: make(map[string]int)}
+	router.POST("/auth/sign-in", auth.New(&fakeService{auth: fake}).SignIn)
+
+	for _, forwarded := range forwardedFor {
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(`{"email":"a@example.com","password":"wrong-password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwarded)
+		req.RemoteAddr = remoteAddr + ":40000"
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("sign in status = %d, body %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	return fake.failures
+}
+
+// Подставной X-Forwarded-For на каждом запросе не даёт нового IP: все неудачи
+// копятся на адресе соединения
+func TestSpoofedForwardedForKeepsIPCounter(t *testing.T) {
+	failures := signIn(t, nil, clientAddr, []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"})
+
+	if len(failures) != 1 || failures[clientAddr] != 3 {
+		t.Fatalf("failures by IP = %v, want 3 on %s", failures, clientAddr)
+	}
+}
+
+// За доверенным прокси клиентом считается адрес из заголовка
+func TestTrustedProxyForwardedFor(t *testing.T) {
+	failures := signIn(t, []string{"10.0.0.0/8"}, "10.0.0.1", []string{clientAddr, clientAddr})
+
+	if len(failures) != 1 || failures[clientAddr] != 2 {
+		t.Fatalf("failures by IP = %v, want 2 on %s", failures, clientAddr)
+	}
+}
+
+func limitedRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	router, err := newRouter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := &ratelimit.Limit{Burst: 2, Period: time.Minute}
+	policies := ratelimit.NewPolicyStore(ratelimit.Policies{
+		Default: ratelimit.Policy{Anonymous: limit, Authenticated: limit},
+	})
+
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("userID", userID)
+		}
+	})
+	router.Use(middleware.RateLimiter(&fakeService{users: &fakeUsers{}}, ratelimit.NewMemory(), policies))
+	router.GET("/books", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return router
+}
+
+func get(router *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/books", nil)
+	req.RemoteAddr = clientAddr + ":40000"
+	req.Header.Set(header, value)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// Корзина анонимного клиента не обновляется подставным X-Forwarded-For
+func TestRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	router := limitedRouter(t)
+
+	codes := make([]int, 0, 3)
+	for _, forwarded := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+		codes = append(codes, get(router, "X-Forwarded-For", forwarded).Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("status codes = %v, want 200, 200, 429", codes)
+	}
+}
+
+// Профиль не прочитался: запрос считается по пользователю, а не падает с 500
+func TestRateLimiterProfileErrorFallsBack(t *testing.T) {
+	router := limitedRouter(t)
+
+	rec := get(router, "X-Test-User", "7")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	if strings.Contains(rec.Body.String(), "connection refused") || rec.Header().Get("RateLimit-Limit") != "2" {
+		t.Fatalf("response leaks the error or skips the limit: headers %v, body %s", rec.Header(), rec.Body.String())
+	}
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"nevermore/pkg/health"
+)
+
+type Handler struct {
+	checker *health.Checker
+}
+
+func New(checker *health.Checker) *Handler {
+	return &Handler{
+		checker: checker,
+	}
+}
+
+// @Summary Liveness probe
+// @Description Report that the process is up and serving requests. Dependencies are not checked
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report "Process is alive"
+// @Router /healthz [get]
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(200, health.Report{Status: health.StatusOK})
+}
+
+// @Summary Readiness probe
+// @Description Ping Postgres, Redis and object storage with timeouts and report per-dependency status and latency. Redis is optional: while it is down the status is degraded and the instance stays ready. Returns 503 while the instance is shutting down
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report "Required dependencies are available"
+// @Failure 503 {object} health.Report "A required dependency is unavailable or the instance is shutting down"
+// @Router /readyz [get]
+func (h *Handler) Ready(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+
+	if !report.Ready() {
+		c.JSON(503, report)
+		return
+	}
+
+	c.JSON(200, report)
+}
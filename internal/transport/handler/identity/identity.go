@@ -0,0 +1,249 @@
+package identity
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	identityService "nevermore/internal/service/identity"
+	userService "nevermore/internal/service/user"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+const (
+	// stateCookie привязывает вход через провайдера к браузеру, который его начал: без неё
+	// колбэк с чужим state можно подсунуть жертве и войти или привязать провайдера от её имени
+	stateCookie     = "oidc_state"
+	stateCookiePath = "/auth/oidc"
+	stateCookieTTL  = 10 * time.Minute
+)
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List identity providers
+// @Description Get names of configured OpenID Connect providers
+// @Tags identities
+// @Accept json
+// @Produce json
+// @Success 200 {array} string "Provider names"
+// @Router /auth/oidc/providers [get]
+func (h *Handler) Providers(c *gin.Context) {
+	providers := h.srv.Identity().Providers()
+	sort.Strings(providers)
+
+	c.JSON(200, providers)
+}
+
+// @Summary Sign in with identity provider
+// @Description Redirect to the provider login page. The provider redirects back to the callback
+// @Tags identities
+// @Param provider path string true "Provider name"
+// @Success 302 "Redirect to the provider"
+// @Failure 404 {object} string "Unknown provider"
+// @Failure 502 {object} string "Provider is unavailable"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/oidc/{provider}/login [get]
+func (h *Handler) Login(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	url, state, err := h.srv.Identity().LoginURL(ctx, c.Param("provider"), nil)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setStateCookie(c, state, stateCookieTTL)
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// @Summary Identity provider callback
+// @Description Finish sign in or provider linking. Accounts are matched by provider subject, then by verified email; otherwise a new account is created
+// @Tags identities
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} dto.OIDCCallbackResponse "Access token, 2FA challenge or linking result"
+// @Failure 400 {object} string "Invalid state, state cookie mismatch or unverified email"
+// @Failure 401 {object} string "Provider account is linked to a deleted user"
+// @Failure 404 {object} string "Unknown provider"
+// @Failure 409 {object} string "Provider account is linked to another user, or an account with this email is not verified"
+// @Failure 502 {object} string "Provider is unavailable"
+// @Failure 500 {object} string "Internal server error"
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *Handler) Callback(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(400, gin.H{"error": "Provider returned error: " + providerErr})
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+
+	if code == "" || state == "" {
+		c.JSON(400, gin.H{"error": "Code and state are required"})
+		return
+	}
+
+	// Сверяем до Callback, иначе подсунутый колбэк сжёг бы state настоящего входа
+	cookie, err := c.Cookie(stateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		c.JSON(400, gin.H{"error": identityService.ErrInvalidState.Error()})
+		return
+	}
+
+	setStateCookie(c, "", -1)
+
+	result, err := h.srv.Identity().Callback(ctx, c.Param("provider"), code, state)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary List linked providers
+// @Description Get identity providers linked to the current user
+// @Tags identities
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} identity.Identity "Linked providers"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/identities [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.srv.Identity().Identities(ctx, userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, identities)
+}
+
+// @Summary Link identity provider
+// @Description Get the provider login URL; after the callback the provider account is linked to the current user. The response sets the state cookie, so the request must be sent with credentials from the browser that opens the URL
+// @Tags identities
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} dto.OIDCLinkResponse "Provider login URL"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Unknown provider"
+// @Failure 502 {object} string "Provider is unavailable"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/identities/{provider}/link [post]
+func (h *Handler) Link(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	url, state, err := h.srv.Identity().LoginURL(ctx, c.Param("provider"), &userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setStateCookie(c, state, stateCookieTTL)
+
+	c.JSON(200, dto.OIDCLinkResponse{URL: url})
+}
+
+// @Summary Unlink identity provider
+// @Description Unlink a provider from the current user. The last provider of an account without a password can't be unlinked
+// @Tags identities
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} string "Provider unlinked successfully"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Provider is not linked"
+// @Failure 409 {object} string "Last login method"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/identities/{provider} [delete]
+func (h *Handler) Unlink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Identity().Unlink(ctx, userId, c.Param("provider")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Provider unlinked successfully"})
+}
+
+// setStateCookie ставит cookie только для колбэка; ttl < 0 удаляет её.
+// SameSite=Lax пропускает cookie при переходе со страницы провайдера обратно
+func setStateCookie(c *gin.Context, state string, ttl time.Duration) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, state, int(ttl.Seconds()), stateCookiePath, "", secure, true)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, userService.ErrUserNotFound):
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+	case errors.Is(err, identityService.ErrInvalidState),
+		errors.Is(err, identityService.ErrEmailNotVerified):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, identityService.ErrUnknownProvider),
+		errors.Is(err, identityService.ErrIdentityNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, identityService.ErrIdentityTaken),
+		errors.Is(err, identityService.ErrEmailTaken),
+		errors.Is(err, identityService.ErrAccountNotVerified),
+		errors.Is(err, identityService.ErrLastLoginMethod):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, identityService.ErrProviderUnavailable):
+		c.JSON(502, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
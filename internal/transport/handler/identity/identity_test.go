@@ -0,0 +1,417 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	model "nevermore/internal/model/identity"
+	"nevermore/internal/service"
+	authService "nevermore/internal/service/auth"
+	identityService "nevermore/internal/service/identity"
+	userService "nevermore/internal/service/user"
+	"nevermore/internal/storage"
+	"nevermore/internal/storage/postgres"
+	identityRepo "nevermore/internal/storage/postgres/identity"
+	"nevermore/pkg/oidc"
+)
+
+const (
+	testProvider = "mock"
+	testClientID = "nevermore"
+	testSubject  = "alice"
+	testUserId   = 7
+	callbackURL  = "http://app.test/auth/oidc/" + testProvider + "/callback"
+)
+
+// Фейки встраивают интерфейсы и переопределяют только то, что нужно входу через провайдера;
+// остальные методы паникуют на nil, если тест их случайно заденет
+
+type fakeService struct {
+	service.Service
+	identity identityService.Service
+}
+
+func (s *fakeService) Identity() identityService.Service { return s.identity }
+
+// fakeAuth ведёт себя как настоящий CompleteSignIn: удалённый пользователь не найден
+type fakeAuth struct {
+	authService.Service
+	deleted map[int]bool
+}
+
+func (a *fakeAuth) CompleteSignIn(ctx context.Context, userId int) (dto.AuthResponse, error) {
+	if a.deleted[userId] {
+		return dto.AuthResponse{}, userService.ErrUserNotFound
+	}
+
+	return dto.AuthResponse{Token: "token-for-" + strconv.Itoa(userId)}, nil
+}
+
+type fakeStorage struct {
+	storage.Storage
+	db *fakeDB
+}
+
+func (s *fakeStorage) DB() postgres.Repo { return s.db }
+
+type fakeDB struct {
+	postgres.Repo
+	identities *fakeIdentities
+}
+
+func (d *fakeDB) Identity() identityRepo.Repo { return d.identities }
+
+// fakeIdentities хранит state как таблица oidc_states: ConsumeState отдаёт его один раз
+type fakeIdentities struct {
+	identityRepo.Repo
+
+	mu       sync.Mutex
+	states   map[string]model.State
+	consumed int
+}
+
+func (f *fakeIdentities) SaveState(ctx context.Context, state model.State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.states[state.StateHash] = state
+
+	return nil
+}
+
+func (f *fakeIdentities) ConsumeState(ctx context.Context, stateHash string) (model.State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.consumed++
+
+	state, ok := f.states[stateHash]
+	if !ok {
+		return state, sql.ErrNoRows
+	}
+
+	delete(f.states, stateHash)
+
+	return state, nil
+}
+
+func (f *fakeIdentities) Get(ctx context.Context, provider, subject string) (model.Identity, error) {
+	if provider != testProvider || subject != testSubject {
+		return model.Identity{}, sql.ErrNoRows
+	}
+
+	return model.Identity{Id: 1, UserId: testUserId, Provider: provider, Subject: subject}, nil
+}
+
+func (f *fakeIdentities) TouchLogin(ctx context.Context, id int64) error {
+	return nil
+}
+
+// mockIssuer — минимальный OIDC-провайдер: discovery, JWKS, страница входа,
+// которая сразу возвращает пользователя с кодом, и обмен кода с проверкой PKCE
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+type authorization struct {
+	nonce     string
+	challenge string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIssuer{key: key, codes: make(map[string]authorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                m.server.URL,
+		"authorization_endpoint":                m.server.URL + "/authorize",
+		"token_endpoint":                        m.server.URL + "/token",
+		"jwks_uri":                              m.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+	}}})
+}
+
+func (m *mockIssuer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	code := "code-" + query.Get("state")
+
+	m.mu.Lock()
+	m.codes[code] = authorization{nonce: query.Get("nonce"), challenge: query.Get("code_challenge")}
+	m.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	auth, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != auth.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken, err := m.sign(map[string]any{
+		"iss":            m.server.URL,
+		"sub":            testSubject,
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          auth.nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (m *mockIssuer) sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func newTestRouter(t *testing.T) (*gin.Engine, *fakeIdentities) {
+	t.Helper()
+
+	return newTestRouterWithAuth(t, &fakeAuth{})
+}
+
+func newTestRouterWithAuth(t *testing.T, auth *fakeAuth) (*gin.Engine, *fakeIdentities) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	issuer := newMockIssuer(t)
+	identities := &fakeIdentities{states: make(map[string]model.State)}
+
+	srv := identityService.New(&fakeStorage{db: &fakeDB{identities: identities}}, auth, []oidc.Config{{
+		Name:         testProvider,
+		Issuer:       issuer.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  callbackURL,
+	}})
+
+	h := New(&fakeService{identity: srv})
+
+	router := gin.New()
+	router.GET("/auth/oidc/:provider/login", h.Login)
+	router.GET("/auth/oidc/:provider/callback", h.Callback)
+
+	return router, identities
+}
+
+// startLogin проходит вход до возврата от провайдера и отдаёт cookie браузера и адрес колбэка
+func startLogin(t *testing.T, router *gin.Engine) (*http.Cookie, string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/"+testProvider+"/login", nil))
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == stateCookie {
+			cookie = c
+		}
+	}
+
+	if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != stateCookiePath {
+		t.Fatalf("state cookie = %+v, want HttpOnly SameSite=Lax on %s", cookie, stateCookiePath)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cookie, callback.RequestURI()
+}
+
+func callback(router *gin.Engine, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestCallbackSignsInBrowserThatStartedLogin(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	cookie, target := startLogin(t, router)
+
+	rec := callback(router, target, cookie)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var result dto.OIDCCallbackResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Token != "token-for-7" || result.Provider != testProvider {
+		t.Fatalf("callback result = %+v", result)
+	}
+
+	// Повторить колбэк нельзя: state одноразовый
+	if rec := callback(router, target, cookie); rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed callback status = %d, want 400", rec.Code)
+	}
+}
+
+// Колбэк чужого входа, открытый в браузере жертвы, отклоняется и не сжигает state
+func TestCallbackRejectsForeignBrowser(t *testing.T) {
+	router, identities := newTestRouter(t)
+
+	cookie, target := startLogin(t, router)
+	_, otherTarget := startLogin(t, router)
+
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+	}{
+		{name: "no cookie", cookie: nil},
+		{name: "cookie of another login", cookie: &http.Cookie{Name: stateCookie, Value: cookie.Value}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := callback(router, otherTarget, tt.cookie); rec.Code != http.StatusBadRequest {
+				t.Fatalf("callback status = %d, want 400", rec.Code)
+			}
+		})
+	}
+
+	if identities.consumed != 0 {
+		t.Fatalf("state consumed %d times before the cookie check", identities.consumed)
+	}
+
+	if rec := callback(router, target, cookie); rec.Code != http.StatusOK {
+		t.Fatalf("own callback status = %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+// Провайдер привязан к удалённому пользователю: вход отклоняется так же, как его токен
+func TestCallbackRejectsDeletedUser(t *testing.T) {
+	router, _ := newTestRouterWithAuth(t, &fakeAuth{deleted: map[int]bool{testUserId: true}})
+
+	cookie, target := startLogin(t, router)
+
+	if rec := callback(router, target, cookie); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("callback status = %d, want 401, body %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	jobService "nevermore/internal/service/job"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List background jobs
+// @Description Get queued, running and dead background jobs, most recently updated first (admins only)
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "Job status: pending, running or dead"
+// @Param queue query string false "Queue name"
+// @Param type query string false "Job type"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.JobListResponse "Jobs"
+// @Failure 400 {object} string "Bad request - invalid filter or paging"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/jobs [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	req := dto.JobListRequest{
+		Status: c.Query("status"),
+		Queue:  c.Query("queue"),
+		Type:   c.Query("type"),
+	}
+
+	var err error
+
+	if req.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if req.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.Job().List(ctx, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Get background job
+// @Description Get a background job with its payload and last error (admins only)
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} jobs.Job "Job"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Job not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/jobs/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid job id"})
+		return
+	}
+
+	job, err := h.srv.Job().Get(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, job)
+}
+
+// @Summary Retry dead background job
+// @Description Put a dead job back into its queue with a fresh set of attempts (admins only)
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} jobs.Job "Requeued job"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Job not found"
+// @Failure 409 {object} string "Job is not dead"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/jobs/{id}/retry [post]
+func (h *Handler) Retry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid job id"})
+		return
+	}
+
+	job, err := h.srv.Job().Retry(ctx, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, job)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jobService.ErrInvalidJobFilter):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, jobService.ErrJobNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, jobService.ErrJobNotDead):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
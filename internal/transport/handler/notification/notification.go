@@ -0,0 +1,244 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	notificationService "nevermore/internal/service/notification"
+	"nevermore/internal/transport/middleware"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List notifications
+// @Description Get notifications of the current user, newest first
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.NotificationListResponse "Notifications"
+// @Failure 400 {object} string "Bad request - invalid paging"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	req := dto.NotificationListRequest{
+		UserId:     userId,
+		UnreadOnly: c.Query("unread") == "true",
+	}
+
+	var err error
+
+	if req.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if req.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.Notification().List(ctx, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Unread notifications count
+// @Description Get the number of unread notifications of the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.UnreadCountResponse "Unread count"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications/unread-count [get]
+func (h *Handler) UnreadCount(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.srv.Notification().UnreadCount(ctx, userId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, dto.UnreadCountResponse{Count: count})
+}
+
+// @Summary Mark notification as read
+// @Description Mark a single notification of the current user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} string "Notification marked as read"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 404 {object} string "Notification not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid notification id"})
+		return
+	}
+
+	err = h.srv.Notification().MarkRead(ctx, userId, id)
+	if errors.Is(err, notificationService.ErrNotificationNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Notification marked as read"})
+}
+
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} string "Notifications marked as read"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications/read-all [post]
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.srv.Notification().MarkAllRead(ctx, userId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Notifications marked as read", "count": count})
+}
+
+// @Summary Get notification preferences
+// @Description Get in-app and email delivery settings for every notification type
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} notification.Preference "Preferences"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications/preferences [get]
+func (h *Handler) Preferences(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.srv.Notification().Preferences(ctx, userId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, preferences)
+}
+
+// @Summary Update notification preferences
+// @Description Choose per notification type whether it is delivered in-app and/or by email
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param preferences body dto.UpdatePreferencesRequest true "Preferences"
+// @Success 200 {array} notification.Preference "Updated preferences"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /notifications/preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid preferences data"})
+		return
+	}
+
+	preferences, err := h.srv.Notification().UpdatePreferences(ctx, userId, req.Preferences)
+	if errors.Is(err, notificationService.ErrUnknownType) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, preferences)
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary Search catalog
+// @Description Full-text search over book titles, descriptions, author names and biographies with typo tolerance
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param author_id query int false "Filter by author facet"
+// @Param genre_id query int false "Filter by genre facet"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.SearchResponse "Search results"
+// @Failure 400 {object} string "Bad request - invalid query"
+// @Failure 500 {object} string "Internal server error"
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	req := dto.SearchRequest{
+		Query: c.Query("q"),
+	}
+
+	if req.Query == "" {
+		c.JSON(400, gin.H{"error": "Query is required"})
+		return
+	}
+
+	if authorIDStr := c.Query("author_id"); authorIDStr != "" {
+		authorId, err := strconv.Atoi(authorIDStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid author_id"})
+			return
+		}
+
+		req.AuthorId = &authorId
+	}
+
+	if genreIDStr := c.Query("genre_id"); genreIDStr != "" {
+		genreId, err := strconv.Atoi(genreIDStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid genre_id"})
+			return
+		}
+
+		req.GenreId = &genreId
+	}
+
+	var err error
+
+	if req.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if req.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.Search().Catalog(ctx, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
@@ -0,0 +1,163 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	seriesService "nevermore/internal/service/series"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List series
+// @Description Get all book series
+// @Tags series
+// @Accept json
+// @Produce json
+// @Success 200 {array} series.Series "Series"
+// @Failure 500 {object} string "Internal server error"
+// @Router /series [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	series, err := h.srv.Series().List(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, series)
+}
+
+// @Summary Get series
+// @Description Get a series with its volumes ordered by volume number
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Success 200 {object} dto.SeriesResponse "Series with volumes"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 404 {object} string "Series not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /series/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid series id"})
+		return
+	}
+
+	series, err := h.srv.Series().Get(ctx, id)
+	if errors.Is(err, seriesService.ErrSeriesNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, series)
+}
+
+// @Summary Create series
+// @Description Create a book series (moderators only)
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param series body dto.CreateSeriesRequest true "Series data"
+// @Success 201 {object} series.Series "Created series"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /series [post]
+func (h *Handler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	var req dto.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid series data"})
+		return
+	}
+
+	series, err := h.srv.Series().Create(ctx, req)
+	if errors.Is(err, seriesService.ErrInvalidSeries) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(201, series)
+}
+
+// @Summary Set book series
+// @Description Assign a book to a series with a volume number, or remove it with an empty series_id (moderators only)
+// @Tags series
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Param series body dto.SetBookSeriesRequest true "Series and volume"
+// @Success 200 {object} string "Book series updated successfully"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/series [put]
+func (h *Handler) SetBookSeries(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	var req dto.SetBookSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid series data"})
+		return
+	}
+
+	err = h.srv.Series().SetBookSeries(ctx, bookId, req)
+	if errors.Is(err, seriesService.ErrInvalidVolume) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Book series updated successfully"})
+}
@@ -0,0 +1,219 @@
+package shelf
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	shelfService "nevermore/internal/service/shelf"
+	"nevermore/internal/transport/middleware"
+	"nevermore/internal/transport/params"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List shelf
+// @Description Get books on the current user's shelf, recently updated first. Personal access tokens need the read:shelf scope
+// @Tags shelf
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status_id query int false "Filter by reading status"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} dto.ShelfListResponse "Shelf"
+// @Failure 400 {object} string "Bad request - invalid query"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Token scope is missing"
+// @Failure 500 {object} string "Internal server error"
+// @Router /shelf [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	req := dto.ShelfListRequest{
+		UserId: userId,
+	}
+
+	if statusIDStr := c.Query("status_id"); statusIDStr != "" {
+		statusId, err := strconv.Atoi(statusIDStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid status_id"})
+			return
+		}
+
+		req.StatusId = &statusId
+	}
+
+	var err error
+
+	if req.Limit, err = params.QueryInt(c, "limit"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	if req.Offset, err = params.QueryInt(c, "offset"); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid offset"})
+		return
+	}
+
+	result, err := h.srv.Shelf().List(ctx, req)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Put book on shelf
+// @Description Add a book to the shelf or update the given fields if it is already there. Personal access tokens need the write:shelf scope
+// @Tags shelf
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param bookId path int true "Book ID"
+// @Param item body dto.ShelfItemRequest true "Shelf item fields"
+// @Success 200 {object} shelf.Item "Shelf item"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Token scope is missing"
+// @Failure 404 {object} string "Book not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /shelf/{bookId} [put]
+func (h *Handler) Put(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	bookId, err := strconv.Atoi(c.Param("bookId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	var req dto.ShelfItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid shelf item data"})
+		return
+	}
+
+	item, err := h.srv.Shelf().Put(ctx, userId, bookId, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, item)
+}
+
+// @Summary Remove book from shelf
+// @Description Remove a book from the current user's shelf. Personal access tokens need the write:shelf scope
+// @Tags shelf
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param bookId path int true "Book ID"
+// @Success 200 {object} string "Book removed from shelf"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Token scope is missing"
+// @Failure 404 {object} string "Book is not on the shelf"
+// @Failure 500 {object} string "Internal server error"
+// @Router /shelf/{bookId} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	bookId, err := strconv.Atoi(c.Param("bookId"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	if err := h.srv.Shelf().Delete(ctx, userId, bookId); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Book removed from shelf"})
+}
+
+// @Summary Import shelf
+// @Description Put up to 500 books on the shelf in one transaction. Unknown books and invalid items are skipped and reported. Personal access tokens need the write:shelf scope
+// @Tags shelf
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param import body dto.ShelfImportRequest true "Shelf items"
+// @Success 200 {object} dto.ShelfImportResponse "Import result"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Token scope is missing"
+// @Failure 500 {object} string "Internal server error"
+// @Router /shelf/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ShelfImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid import data"})
+		return
+	}
+
+	result, err := h.srv.Shelf().Import(ctx, userId, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, shelfService.ErrInvalidItem),
+		errors.Is(err, shelfService.ErrInvalidImport):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, shelfService.ErrBookNotFound),
+		errors.Is(err, shelfService.ErrItemNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,204 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	tagService "nevermore/internal/service/tag"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary List tags
+// @Description Get all approved user tags
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Success 200 {array} tag.Tag "Approved tags"
+// @Failure 500 {object} string "Internal server error"
+// @Router /tags [get]
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	tags, err := h.srv.Tag().Approved(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, tags)
+}
+
+// @Summary List tags awaiting moderation
+// @Description Get user tags that were not yet approved or rejected (moderators only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} tag.Tag "Pending tags"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /tags/pending [get]
+func (h *Handler) Pending(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	tags, err := h.srv.Tag().Pending(ctx)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, tags)
+}
+
+// @Summary Moderate tag
+// @Description Approve or reject a user tag (moderators only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Tag ID"
+// @Param status body dto.ModerateTagRequest true "New status: approved or rejected"
+// @Success 200 {object} string "Tag moderated successfully"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 404 {object} string "Tag not found"
+// @Failure 500 {object} string "Internal server error"
+// @Router /tags/{id}/moderate [post]
+func (h *Handler) Moderate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	moderatorId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid tag id"})
+		return
+	}
+
+	var req dto.ModerateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid moderation data"})
+		return
+	}
+
+	err = h.srv.Tag().Moderate(ctx, id, req.Status, moderatorId)
+	switch {
+	case errors.Is(err, tagService.ErrInvalidStatus):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, tagService.ErrTagNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Tag moderated successfully"})
+}
+
+// @Summary Get book tags
+// @Description Get approved tags attached to a book
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} tag.Tag "Book tags"
+// @Failure 400 {object} string "Bad request - invalid id"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/tags [get]
+func (h *Handler) GetByBook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	tags, err := h.srv.Tag().GetByBook(ctx, bookId)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, tags)
+}
+
+// @Summary Tag a book
+// @Description Attach a free-form tag to a book; new tags become visible after moderation
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Book ID"
+// @Param tag body dto.AddBookTagRequest true "Tag name"
+// @Success 200 {object} tag.Tag "Attached tag"
+// @Failure 400 {object} string "Bad request - invalid data"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 422 {object} string "Tag was rejected by moderators"
+// @Failure 500 {object} string "Internal server error"
+// @Router /books/{id}/tags [post]
+func (h *Handler) AddToBook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid book id"})
+		return
+	}
+
+	var req dto.AddBookTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid tag data"})
+		return
+	}
+
+	tag, err := h.srv.Tag().Suggest(ctx, bookId, userId, req.Name)
+	switch {
+	case errors.Is(err, tagService.ErrInvalidTag):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, tagService.ErrTagRejected):
+		c.JSON(422, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, tag)
+}
@@ -0,0 +1,269 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/dto"
+	"nevermore/internal/service"
+	twoFactorService "nevermore/internal/service/twofactor"
+	"nevermore/internal/transport/middleware"
+)
+
+const timeout = 15 * time.Second
+
+type Handler struct {
+	srv service.Service
+}
+
+func New(srv service.Service) *Handler {
+	return &Handler{
+		srv: srv,
+	}
+}
+
+// @Summary Two-factor status
+// @Description Get whether 2FA is enabled, whether the user's role requires it and how many recovery codes are left
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.TwoFactorStatus "Two-factor status"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/2fa [get]
+func (h *Handler) Status(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.srv.TwoFactor().Status(ctx, userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, status)
+}
+
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and an otpauth URI for the authenticator app. 2FA is enabled only after confirmation
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dto.TwoFactorEnrollResponse "Secret and otpauth URI"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 409 {object} string "Two-factor authentication is already enabled"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/2fa/enroll [post]
+func (h *Handler) Enroll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.srv.TwoFactor().Enroll(ctx, userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+// @Summary Confirm two-factor enrollment
+// @Description Enable 2FA with the first code from the authenticator app. Recovery codes are shown only once
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code body dto.TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} dto.RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} string "Invalid code or enrollment not started"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 409 {object} string "Two-factor authentication is already enabled"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/2fa/confirm [post]
+func (h *Handler) Confirm(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Code is required"})
+		return
+	}
+
+	codes, err := h.srv.TwoFactor().Confirm(ctx, userId, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, dto.RecoveryCodesResponse{Codes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Disable 2FA with a code from the authenticator app or a recovery code. Not allowed when the role requires 2FA
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code body dto.TwoFactorCodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} string "Two-factor authentication disabled"
+// @Failure 400 {object} string "Invalid code"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Required by policy"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/2fa/disable [post]
+func (h *Handler) Disable(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Code is required"})
+		return
+	}
+
+	if err := h.srv.TwoFactor().Disable(ctx, userId, req.Code); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes with new ones. Requires a valid authenticator or recovery code
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code body dto.TwoFactorCodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} dto.RecoveryCodesResponse "New recovery codes"
+// @Failure 400 {object} string "Invalid code"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 500 {object} string "Internal server error"
+// @Router /user/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Code is required"})
+		return
+	}
+
+	codes, err := h.srv.TwoFactor().RegenerateRecoveryCodes(ctx, userId, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, dto.RecoveryCodesResponse{Codes: codes})
+}
+
+// @Summary List two-factor policies
+// @Description Get which roles are required to enable 2FA (admins only)
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} twofactor.Policy "Policies"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/2fa/policies [get]
+func (h *Handler) Policies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	policies, err := h.srv.TwoFactor().Policies(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, policies)
+}
+
+// @Summary Set two-factor policy
+// @Description Require or stop requiring 2FA for a role (admins only)
+// @Tags two-factor
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param policy body dto.SetTwoFactorPolicyRequest true "Role policy"
+// @Success 200 {array} twofactor.Policy "Updated policies"
+// @Failure 400 {object} string "Bad request - invalid role"
+// @Failure 401 {object} string "Unauthorized"
+// @Failure 403 {object} string "Forbidden"
+// @Failure 500 {object} string "Internal server error"
+// @Router /admin/2fa/policies [put]
+func (h *Handler) SetPolicy(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	userId, ok := middleware.UserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.SetTwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid policy data"})
+		return
+	}
+
+	policies, err := h.srv.TwoFactor().SetPolicy(ctx, userId, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(200, policies)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, twoFactorService.ErrInvalidCode),
+		errors.Is(err, twoFactorService.ErrNotEnrolled),
+		errors.Is(err, twoFactorService.ErrNotEnabled),
+		errors.Is(err, twoFactorService.ErrInvalidPolicyRole):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, twoFactorService.ErrRequiredByPolicy):
+		c.JSON(403, gin.H{"error": err.Error()})
+	case errors.Is(err, twoFactorService.ErrAlreadyEnabled):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
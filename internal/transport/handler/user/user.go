@@ -3,13 +3,13 @@ package user
 import (
 	"context"
 	"encoding/json"
-	"nevermore/internal/model/user"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"nevermore/internal/dto"
 	"nevermore/internal/service"
+	"nevermore/internal/transport/middleware"
 )
 
 const timeout = 15 * time.Second
@@ -35,24 +35,11 @@ func New(srv service.Service) *Handler {
 // @Failure 500 {object} string "Internal server error"
 // @Router /user/get [get]
 func (h *Handler) Get(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userIDStr, ok := userID.(string)
+	userId, ok := middleware.UserID(c)
 	if !ok {
-		c.JSON(500, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	userId, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
 
@@ -66,12 +53,12 @@ func (h *Handler) Get(c *gin.Context) {
 }
 
 // @Summary Update user profile
-// @Description Update current user profile information with optional photo upload
+// @Description Update name, phone number, photo and email language of the current user. Email, role and password cannot be changed here
 // @Tags users
 // @Accept multipart/form-data
 // @Produce json
 // @Security ApiKeyAuth
-// @Param user formData string true "User data in JSON format"
+// @Param user formData string true "Profile data in JSON format: name, phone_number, photo, locale"
 // @Param photo formData file false "Profile photo"
 // @Success 200 {object} string "User updated successfully"
 // @Failure 400 {object} string "Bad request - invalid data"
@@ -79,12 +66,11 @@ func (h *Handler) Get(c *gin.Context) {
 // @Failure 500 {object} string "Internal server error"
 // @Router /user/update [put]
 func (h *Handler) Update(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
-	_, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
+	userId, ok := middleware.UserID(c)
+	if !ok {
 		return
 	}
 
@@ -102,15 +88,14 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
-	var userData user.User
-	if err := json.Unmarshal([]byte(userJSON), &userData); err != nil {
+	// Изменяется только пользователь из токена, лишние поля в JSON игнорируются
+	var req dto.UserUpdateRequest
+	if err := json.Unmarshal([]byte(userJSON), &req); err != nil {
 		c.JSON(400, gin.H{"error": "Invalid user data"})
 		return
 	}
 
-	// Получаем файл фото
-
-	err := h.srv.User().Update(ctx, userData)
+	err := h.srv.User().Update(ctx, userId, req)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -130,25 +115,15 @@ func (h *Handler) Update(c *gin.Context) {
 // @Failure 500 {object} string "Internal server error"
 // @Router /user/delete [delete]
 func (h *Handler) Delete(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
-	// Получаем userID из контекста
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(401, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	userIDStr, ok := userID.(string)
+	userId, ok := middleware.UserID(c)
 	if !ok {
-		c.JSON(500, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	userId, err := strconv.Atoi(userIDStr)
-
-	err = h.srv.User().Delete(ctx, userId)
+	err := h.srv.User().Delete(ctx, userId)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"nevermore/pkg/logger"
+)
+
+// AccessLog кладёт в контекст запроса дочерний логгер с методом, маршрутом и id запроса,
+// чтобы записи сервисов и репозиториев можно было связать с запросом, а после ответа
+// пишет строку журнала доступа. Ставится после RequestID и Tracing
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		// Логгер из контекста уже несёт request_id от RequestID и trace_id от Tracing
+		log := logger.FromContext(c.Request.Context()).With().
+			Str("method", c.Request.Method).
+			Str("route", route).
+			Logger()
+
+		ctx := logger.WrapToContext(c.Request.Context(), log)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// Authenticate мог дополнить логгер id пользователя
+		log = logger.NamedFromContext(c.Request.Context(), "http")
+
+		var event *zerolog.Event
+		switch status := c.Writer.Status(); {
+		case status >= 500:
+			event = log.Error()
+		case status >= 400:
+			event = log.Warn()
+		default:
+			event = log.Info()
+		}
+
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+
+		event.
+			Str("path", c.Request.URL.Path).
+			Int("status", c.Writer.Status()).
+			Dur("latency", time.Since(start)).
+			Int("size", max(c.Writer.Size(), 0)).
+			Str("ip", c.ClientIP()).
+			Msg("request")
+	}
+}
+
+// setUser запоминает пользователя в контексте gin и добавляет его id в логгер запроса
+func setUser(c *gin.Context, userID string) {
+	c.Set("userID", userID)
+
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx).With().Str("user_id", userID).Logger()
+	c.Request = c.Request.WithContext(logger.WrapToContext(ctx, log))
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apiTokenModel "nevermore/internal/model/apitoken"
+	"nevermore/internal/service"
+	apiTokenService "nevermore/internal/service/apitoken"
+	userService "nevermore/internal/service/user"
+)
+
+const authTimeout = 5 * time.Second
+
+// Authenticate проверяет токен из заголовка Authorization и кладёт id пользователя в контекст.
+// Принимает как JWT сессии, так и персональные токены — для них в контекст кладутся ещё и области доступа.
+// JWT удалённого пользователя остаётся валидным до истечения, поэтому пользователь проверяется по профилю из кэша
+func Authenticate(srv service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.JSON(401, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if strings.HasPrefix(token, apiTokenModel.Prefix) {
+			authenticateAPIToken(c, srv, token)
+			return
+		}
+
+		userID, err := srv.Auth().ParseToken(token)
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		userId, err := strconv.Atoi(userID)
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), authTimeout)
+		defer cancel()
+
+		_, err = srv.User().Get(ctx, userId)
+		if errors.Is(err, userService.ErrUserNotFound) {
+			c.JSON(401, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		setUser(c, userID)
+
+		c.Next()
+	}
+}
+
+func authenticateAPIToken(c *gin.Context, srv service.Service, secret string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), authTimeout)
+	defer cancel()
+
+	token, err := srv.APIToken().Authenticate(ctx, secret)
+	if errors.Is(err, apiTokenService.ErrUnauthorized) {
+		c.JSON(401, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+
+	setUser(c, strconv.Itoa(token.UserId))
+	c.Set("apiToken", token)
+
+	c.Next()
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"nevermore/pkg/flags"
+)
+
+// Feature прячет маршруты выключенной возможности, как будто их нет
+func Feature(toggles *flags.Toggles, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !toggles.Enabled(name) {
+			c.JSON(404, gin.H{"error": "Not found"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
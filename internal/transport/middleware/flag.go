@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/service"
+	"nevermore/pkg/flags"
+)
+
+const flagTimeout = 5 * time.Second
+
+// RequireFlag прячет маршрут, пока флаг выключен для пользователя. Ставится после
+// Authenticate, иначе запрос проверяется как анонимный
+func RequireFlag(srv service.Service, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FlagEnabled(c, srv, name) {
+			c.JSON(404, gin.H{"error": "Not found"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// FlagEnabled проверяет флаг внутри обработчика, например чтобы добавить
+// в ответ поле только для участников раскатки
+func FlagEnabled(c *gin.Context, srv service.Service, name string) bool {
+	subject, ok := FlagSubject(c, srv)
+	if !ok {
+		return false
+	}
+
+	return srv.Flag().Enabled(name, subject)
+}
+
+// FlagSubject определяет пользователя и его роль для проверки флагов и запоминает
+// результат в контексте запроса. Если роль узнать не удалось, флаги считаются выключенными
+func FlagSubject(c *gin.Context, srv service.Service) (flags.Subject, bool) {
+	if cached, exists := c.Get("flagSubject"); exists {
+		subject, ok := cached.(flags.Subject)
+		return subject, ok
+	}
+
+	var subject flags.Subject
+
+	userId, exists, err := lookupUserID(c)
+	if err != nil {
+		return subject, false
+	}
+
+	if exists {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), flagTimeout)
+		defer cancel()
+
+		user, err := srv.User().Get(ctx, userId)
+		if err != nil {
+			return subject, false
+		}
+
+		subject = flags.Subject{UserId: userId, Role: user.Role}
+	}
+
+	c.Set("flagSubject", subject)
+
+	return subject, true
+}
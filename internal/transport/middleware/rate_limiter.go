@@ -1,48 +1,88 @@
 package middleware
 
 import (
-	"sync"
+	"context"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	userModel "nevermore/internal/model/user"
+	"nevermore/internal/service"
+	"nevermore/pkg/logger"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/ratelimit"
 )
 
-// RateLimiter создает middleware для ограничения частоты запросов
-func RateLimiter(duration time.Duration) gin.HandlerFunc {
-	limits := make(map[string]time.Time)
-	var mu sync.Mutex
+const rateLimitTimeout = 2 * time.Second
 
+// RateLimiter ограничивает запросы корзиной токенов. Авторизованные запросы считаются
+// по пользователю с лимитом его роли, анонимные — по IP клиента, который gin берёт из
+// заголовков только от доверенных прокси. Ставится после Authenticate, если он есть у группы.
+// Ошибки лимитера и чтения профиля запрос не блокируют
+func RateLimiter(srv service.Service, limiter ratelimit.Limiter, policies *ratelimit.PolicyStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Получаем userID из контекста (установленного в AuthMiddleware)
-		userID, exists := c.Get("userID")
-		if !exists {
-			c.JSON(401, gin.H{"error": "Unauthorized"})
+		ctx, cancel := context.WithTimeout(c.Request.Context(), rateLimitTimeout)
+		defer cancel()
+
+		subject := "ip:" + c.ClientIP()
+		role := ""
+
+		userId, exists, err := lookupUserID(c)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
 			c.Abort()
 			return
 		}
 
-		// Преобразуем userID в строку (предполагая, что это string)
-		userIDStr, ok := userID.(string)
+		if exists {
+			subject = "user:" + strconv.Itoa(userId)
+
+			// Без профиля роль неизвестна: запрос считается по пользователю с лимитом
+			// обычной роли, а не блокируется
+			user, err := srv.User().Get(ctx, userId)
+			if err != nil {
+				log := logger.NamedFromContext(ctx, "ratelimit")
+				log.Error().Err(err).Int("user_id", userId).Msg("failed to load user role, using the default role limit")
+				role = userModel.RoleUser
+			} else {
+				role = user.Role
+			}
+		}
+
+		scope, limit, ok := policies.Resolve(c.Request.Method+" "+c.FullPath(), role)
 		if !ok {
-			c.JSON(500, gin.H{"error": "Internal server error"})
-			c.Abort()
+			c.Next()
+			return
+		}
+
+		res, err := limiter.Allow(ctx, scope+":"+subject, limit)
+		if err != nil {
+			log := logger.NamedFromContext(ctx, "ratelimit")
+			log.Error().Err(err).Str("scope", scope).Msg("rate limiter failed, request allowed")
+			c.Next()
 			return
 		}
 
-		mu.Lock()
-		lastTime, exists := limits[userIDStr]
-		now := time.Now()
+		// Заголовки по черновику IETF RateLimit header fields
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.ResetAfter))
+		c.Header("RateLimit-Policy", strconv.Itoa(limit.Burst)+";w="+seconds(limit.Period))
 
-		if exists && now.Sub(lastTime) < duration {
-			mu.Unlock()
+		if !res.Allowed {
+			metrics.RateLimitRejections.WithLabelValues(scope).Inc()
+			c.Header("Retry-After", seconds(res.RetryAfter))
 			c.JSON(429, gin.H{"error": "Rate limit exceeded"})
 			c.Abort()
 			return
 		}
 
-		limits[userIDStr] = now
-		mu.Unlock()
-
 		c.Next()
 	}
 }
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
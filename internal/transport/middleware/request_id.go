@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"nevermore/pkg/requestid"
+)
+
+// RequestID принимает X-Request-ID от клиента или прокси либо создаёт новый, возвращает его
+// в ответе и кладёт в контекст запроса. Оттуда он попадает в логи и в фоновые задачи
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.Attach(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
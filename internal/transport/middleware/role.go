@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/service"
+)
+
+const roleTimeout = 5 * time.Second
+
+// RequireRole пропускает только пользователей с одной из указанных ролей
+func RequireRole(srv service.Service, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), roleTimeout)
+		defer cancel()
+
+		userId, ok := UserID(c)
+		if !ok {
+			return
+		}
+
+		user, err := srv.User().Get(ctx, userId)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !slices.Contains(roles, user.Role) {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
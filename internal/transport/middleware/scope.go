@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	apiTokenModel "nevermore/internal/model/apitoken"
+)
+
+// RequireScope пропускает запросы с персональным токеном, только если ему выдана область доступа.
+// Запросы с JWT сессии проходят без проверки — их ограничивают роли
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := apiToken(c)
+		if ok && !token.HasScope(scope) {
+			c.JSON(403, gin.H{"error": "Token does not have the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession не пускает персональные токены туда, где область доступа не объявлена:
+// управление аккаунтом и токенами доступно только после входа
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := apiToken(c); ok {
+			c.JSON(403, gin.H{"error": "Personal access tokens are not allowed here"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func apiToken(c *gin.Context) (apiTokenModel.Token, bool) {
+	value, exists := c.Get("apiToken")
+	if !exists {
+		return apiTokenModel.Token{}, false
+	}
+
+	token, ok := value.(apiTokenModel.Token)
+
+	return token, ok
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"nevermore/pkg/logger"
+	"nevermore/pkg/requestid"
+	"nevermore/pkg/tracing"
+)
+
+// Tracing открывает серверный спан запроса, продолжая трассу из заголовка traceparent,
+// и добавляет trace_id в логгер запроса. Ставится после RequestID и до AccessLog
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracing.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		if id := requestid.FromContext(ctx); id != "" {
+			span.SetAttributes(semconv.HTTPRequestHeader("x-request-id", id))
+		}
+
+		// При выключенной трассировке спан пустой и trace_id в логах не нужен
+		if sc := span.SpanContext(); sc.IsValid() {
+			log := logger.FromContext(ctx).With().Str("trace_id", sc.TraceID().String()).Logger()
+			ctx = logger.WrapToContext(ctx, log)
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+
+		// Для серверного спана ошибка — только 5xx, 4xx — ошибка клиента
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/service"
+)
+
+const twoFactorTimeout = 5 * time.Second
+
+// RequireTwoFactor не пускает пользователей, чья роль обязана включить 2FA, но ещё не включила
+func RequireTwoFactor(srv service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), twoFactorTimeout)
+		defer cancel()
+
+		userId, ok := UserID(c)
+		if !ok {
+			return
+		}
+
+		satisfied, err := srv.TwoFactor().Satisfied(ctx, userId)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !satisfied {
+			c.JSON(403, gin.H{"error": "Two-factor authentication is required for your role"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
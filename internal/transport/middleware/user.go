@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserID возвращает id пользователя, которого в контекст положил Authenticate.
+// Если пользователя нет, сам отвечает клиенту и прерывает цепочку — вызывающему остаётся выйти
+func UserID(c *gin.Context) (int, bool) {
+	userId, exists, err := lookupUserID(c)
+	if !exists {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+
+	if err != nil {
+		c.AbortWithStatusJSON(500, gin.H{"error": "Internal server error"})
+		return 0, false
+	}
+
+	return userId, true
+}
+
+// lookupUserID — для маршрутов, где авторизация необязательна: exists=false у анонимных запросов
+func lookupUserID(c *gin.Context) (int, bool, error) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return 0, false, nil
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return 0, true, errors.New("userID is not a string")
+	}
+
+	userId, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return 0, true, err
+	}
+
+	return userId, true, nil
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"nevermore/internal/service"
+)
+
+const verifiedTimeout = 5 * time.Second
+
+// RequireVerifiedEmail пропускает только пользователей с подтверждённой почтой
+func RequireVerifiedEmail(srv service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), verifiedTimeout)
+		defer cancel()
+
+		userId, ok := UserID(c)
+		if !ok {
+			return
+		}
+
+		verified, err := srv.Auth().IsEmailVerified(ctx, userId)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !verified {
+			c.JSON(403, gin.H{"error": "Email is not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+// Package params разбирает параметры запроса, общие для нескольких обработчиков
+package params
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryInt возвращает целый query-параметр; отсутствующий параметр — это 0
+func QueryInt(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenManager выпускает и проверяет JWT доступа
+type TokenManager interface {
+	NewJWT(userId string, ttl time.Duration) (string, error)
+	Parse(accessToken string) (string, error)
+}
+
+type Manager struct {
+	signingKey string
+}
+
+func NewManager(signingKey string) (*Manager, error) {
+	if signingKey == "" {
+		return nil, errors.New("empty signing key")
+	}
+
+	return &Manager{signingKey: signingKey}, nil
+}
+
+func (m *Manager) NewJWT(userId string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   userId,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	})
+
+	return token.SignedString([]byte(m.signingKey))
+}
+
+// Parse проверяет подпись и срок действия и возвращает id пользователя
+func (m *Manager) Parse(accessToken string) (string, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return []byte(m.signingKey), nil
+	})
+	if err != nil || !token.Valid {
+		return "", ErrInvalidToken
+	}
+
+	subject, err := token.Claims.GetSubject()
+	if err != nil || subject == "" {
+		return "", ErrInvalidToken
+	}
+
+	return subject, nil
+}
+
+// NewOpaqueToken возвращает случайный токен для ссылок из писем
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken — хэш токена для хранения в базе. Токены случайные и длинные,
+// поэтому соль и медленное хэширование не нужны
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
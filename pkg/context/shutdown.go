@@ -0,0 +1,83 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Phase — шаг остановки. Fn получает контекст с таймаутом фазы
+type Phase struct {
+	Name    string
+	Timeout time.Duration
+	Fn      func(ctx context.Context) error
+}
+
+// PhaseResult — итог фазы для журнала остановки
+type PhaseResult struct {
+	Name     string
+	Duration time.Duration
+	TimedOut bool
+	Err      error
+}
+
+// Shutdown выполняет фазы остановки по порядку, каждую не дольше её таймаута
+type Shutdown struct {
+	phases []Phase
+	report func(PhaseResult)
+}
+
+// NewShutdown создаёт координатор. report вызывается после каждой фазы
+func NewShutdown(report func(PhaseResult)) *Shutdown {
+	return &Shutdown{
+		report: report,
+	}
+}
+
+func (s *Shutdown) Add(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	s.phases = append(s.phases, Phase{Name: name, Timeout: timeout, Fn: fn})
+}
+
+// Run выполняет все фазы и возвращает ошибки тех, что не удались. Фаза, не уложившаяся
+// в таймаут, бросается, даже если не слушает ctx, и остановка идёт дальше:
+// лучше закрыть хранилище, не дождавшись задач, чем не остановиться вовсе
+func (s *Shutdown) Run(ctx context.Context) error {
+	var errs []error
+
+	for _, phase := range s.phases {
+		result := s.run(ctx, phase)
+		s.report(result)
+
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", phase.Name, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Shutdown) run(ctx context.Context, phase Phase) PhaseResult {
+	ctx, cancel := context.WithTimeout(ctx, phase.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- phase.Fn(ctx)
+	}()
+
+	result := PhaseResult{Name: phase.Name}
+
+	select {
+	case result.Err = <-done:
+	case <-ctx.Done():
+		result.Err = ctx.Err()
+	}
+
+	result.Duration = time.Since(start)
+	result.TimedOut = errors.Is(result.Err, context.DeadlineExceeded)
+
+	return result
+}
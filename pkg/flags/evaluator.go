@@ -0,0 +1,105 @@
+package flags
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"nevermore/pkg/logger"
+	"nevermore/pkg/tracing"
+)
+
+// Source отдаёт все флаги; Evaluator перечитывает их целиком
+type Source interface {
+	List(ctx context.Context) ([]Flag, error)
+}
+
+// Evaluator проверяет флаги по копии в памяти, не обращаясь к базе на каждом запросе.
+// Выключатель из секции features конфигурации перекрывает флаг с тем же именем,
+// чтобы возможность можно было срочно погасить без базы
+type Evaluator struct {
+	source  Source
+	toggles *Toggles
+	flags   atomic.Pointer[map[string]Flag]
+}
+
+func NewEvaluator(source Source, toggles *Toggles) *Evaluator {
+	result := &Evaluator{
+		source:  source,
+		toggles: toggles,
+	}
+
+	empty := make(map[string]Flag)
+	result.flags.Store(&empty)
+
+	return result
+}
+
+// Reload перечитывает флаги. При ошибке остаются прежние, а до первой
+// успешной загрузки все флаги считаются выключенными
+func (e *Evaluator) Reload(ctx context.Context) error {
+	list, err := e.source.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]Flag, len(list))
+	for _, flag := range list {
+		loaded[flag.Name] = flag
+	}
+
+	e.flags.Store(&loaded)
+
+	return nil
+}
+
+// Run перечитывает флаги с интервалом, чтобы изменения с других экземпляров
+// доходили и сюда. Останавливается вместе с ctx
+func (e *Evaluator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Evaluator) refresh(ctx context.Context) {
+	ctx, span := tracing.StartJob(ctx, "flags.Reload")
+	defer span.End()
+
+	if err := e.Reload(ctx); err != nil && ctx.Err() == nil {
+		tracing.Fail(span, err)
+
+		log := logger.Named("flags")
+		log.Error().Err(err).Msg("failed to reload feature flags")
+	}
+}
+
+func (e *Evaluator) Enabled(name string, subject Subject) bool {
+	if !e.toggles.Enabled(name) {
+		return false
+	}
+
+	flag, ok := (*e.flags.Load())[name]
+
+	return ok && flag.Evaluate(subject)
+}
+
+// For возвращает все флаги со значениями для subject, например для фронтенда
+func (e *Evaluator) For(subject Subject) map[string]bool {
+	flags := *e.flags.Load()
+
+	result := make(map[string]bool, len(flags))
+	for name := range flags {
+		result[name] = e.Enabled(name, subject)
+	}
+
+	return result
+}
@@ -0,0 +1,58 @@
+package flags
+
+import (
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Flag включает возможность постепенно. Выключенный флаг закрыт для всех.
+// Включённый открыт ролям из Roles, пользователям из UserIds и Percentage процентам
+// остальных авторизованных пользователей; при 100 — всем, включая анонимов
+type Flag struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Roles       []string  `json:"roles"`
+	UserIds     []int     `json:"user_ids"`
+	Percentage  int       `json:"percentage"`
+	UpdatedBy   *int      `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subject — тот, для кого проверяется флаг. Нулевой UserId — анонимный запрос
+type Subject struct {
+	UserId int
+	Role   string
+}
+
+func (f Flag) Evaluate(subject Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if f.Percentage >= 100 {
+		return true
+	}
+
+	if subject.UserId == 0 {
+		return false
+	}
+
+	if slices.Contains(f.UserIds, subject.UserId) || slices.Contains(f.Roles, subject.Role) {
+		return true
+	}
+
+	return bucket(f.Name, subject.UserId) < f.Percentage
+}
+
+// bucket раскладывает пользователей по сотне корзин. Корзина зависит от имени флага,
+// поэтому при 10% у разных флагов попадают разные пользователи, а при увеличении
+// процента уже получившие возможность её не теряют
+func bucket(name string, userId int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + strconv.Itoa(userId)))
+
+	return int(h.Sum32() % 100)
+}
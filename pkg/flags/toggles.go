@@ -0,0 +1,33 @@
+package flags
+
+import (
+	"sync/atomic"
+)
+
+// Toggles — выключатели возможностей из конфигурации. Их можно поменять на лету,
+// не перезапуская сервер. Выключатель, которого нет в конфигурации, считается включённым
+type Toggles struct {
+	values atomic.Pointer[map[string]bool]
+}
+
+func NewToggles(values map[string]bool) *Toggles {
+	result := &Toggles{}
+	result.Replace(values)
+
+	return result
+}
+
+func (t *Toggles) Replace(values map[string]bool) {
+	copied := make(map[string]bool, len(values))
+	for name, enabled := range values {
+		copied[name] = enabled
+	}
+
+	t.values.Store(&copied)
+}
+
+func (t *Toggles) Enabled(name string) bool {
+	enabled, ok := (*t.values.Load())[name]
+
+	return !ok || enabled
+}
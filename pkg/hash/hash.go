@@ -2,13 +2,71 @@ package hash
 
 import (
 	"crypto/sha1"
+	"crypto/subtle"
 	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+// MaxPasswordLength — bcrypt учитывает только первые 72 байта пароля
+const MaxPasswordLength = 72
+
 type PasswordHasher interface {
 	Hash(password string) (string, error)
+	// Verify сверяет пароль с сохранённым хэшем. rehash сообщает, что хэш посчитан
+	// устаревшим способом и после успешного входа его стоит пересчитать
+	Verify(password, hashed string) (ok bool, rehash bool)
+}
+
+// BcryptHasher хранит пароли в bcrypt: соль своя у каждого хэша и записана в нём же.
+// Хэши прежнего SHA1Hasher принимаются, пока пользователи не войдут и не получат новый
+type BcryptHasher struct {
+	cost   int
+	legacy *SHA1Hasher
+}
+
+// NewBcryptHasher с нулевой cost берёт стоимость bcrypt по умолчанию. legacy — хэшер,
+// которым посчитаны старые пароли, nil если таких нет
+func NewBcryptHasher(cost int, legacy *SHA1Hasher) *BcryptHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+
+	return &BcryptHasher{cost: cost, legacy: legacy}
 }
 
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashed), nil
+}
+
+func (h *BcryptHasher) Verify(password, hashed string) (bool, bool) {
+	if !strings.HasPrefix(hashed, "$2") {
+		if h.legacy == nil {
+			return false, false
+		}
+
+		ok, _ := h.legacy.Verify(password, hashed)
+
+		return ok, ok
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
+	if err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashed))
+
+	return true, err == nil && cost != h.cost
+}
+
+// SHA1Hasher — прежний способ хранения паролей, оставлен только для проверки старых хэшей
 type SHA1Hasher struct {
 	salt string
 }
@@ -26,3 +84,15 @@ func (h *SHA1Hasher) Hash(password string) (string, error) {
 
 	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
 }
+
+// Verify всегда просит пересчитать хэш: SHA1 без соли во входе для паролей не годится
+func (h *SHA1Hasher) Verify(password, hashed string) (bool, bool) {
+	expected, err := h.Hash(password)
+	if err != nil || hashed == "" {
+		return false, false
+	}
+
+	ok := subtle.ConstantTimeCompare([]byte(expected), []byte(hashed)) == 1
+
+	return ok, ok
+}
@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusDegraded     = "degraded"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check проверяет одну зависимость и должен уложиться в ctx
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	Optional  bool    `json:"optional,omitempty"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Ready сообщает, может ли экземпляр принимать трафик: недоступные необязательные
+// зависимости только помечают его как degraded
+func (r Report) Ready() bool {
+	return r.Status == StatusOK || r.Status == StatusDegraded
+}
+
+// Checker проверяет зависимости для готовности. Проверки идут параллельно,
+// каждая со своим таймаутом, так что одна зависшая не задерживает остальные
+type Checker struct {
+	timeout  time.Duration
+	names    []string
+	checks   map[string]Check
+	optional map[string]bool
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		checks:   make(map[string]Check),
+		optional: make(map[string]bool),
+	}
+}
+
+// Add регистрирует проверку. Вызывается до начала обслуживания запросов
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// AddOptional регистрирует проверку зависимости, без которой сервис работает в
+// урезанном режиме, например Redis с запасным лимитером в памяти. Её отказ виден
+// в отчёте, но не снимает экземпляр с балансировки
+func (c *Checker) AddOptional(name string, check Check) {
+	c.Add(name, check)
+	c.optional[name] = true
+}
+
+// Drain переводит экземпляр в неготовые до конца жизни процесса, чтобы балансировщик
+// снял с него трафик раньше, чем сервер перестанет принимать соединения
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Ready проверяет все зависимости. При остановке зависимости не проверяются
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.Draining() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	result := Report{
+		Status: StatusOK,
+		Checks: make(map[string]Result, len(c.names)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, name := range c.names {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res := c.run(ctx, c.checks[name])
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			res.Optional = c.optional[name]
+			result.Checks[name] = res
+
+			switch {
+			case res.Status == StatusOK:
+			case !res.Optional:
+				result.Status = StatusUnavailable
+			case result.Status == StatusOK:
+				result.Status = StatusDegraded
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return result
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+
+	result := Result{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// HTTP проверяет зависимость, у которой есть HTTP-проба, например MinIO
+// с /minio/health/live. Готовность — любой ответ 2xx
+func HTTP(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		postgres  Check
+		redis     Check
+		want      string
+		wantReady bool
+	}{
+		{name: "all up", postgres: ok, redis: ok, want: StatusOK, wantReady: true},
+		{name: "optional down", postgres: ok, redis: down, want: StatusDegraded, wantReady: true},
+		{name: "required down", postgres: down, redis: ok, want: StatusUnavailable, wantReady: false},
+		{name: "both down", postgres: down, redis: down, want: StatusUnavailable, wantReady: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(time.Second)
+			checker.Add("postgres", tt.postgres)
+			checker.AddOptional("redis", tt.redis)
+
+			report := checker.Ready(context.Background())
+
+			if report.Status != tt.want || report.Ready() != tt.wantReady {
+				t.Fatalf("report status = %s, ready = %v, want %s, %v", report.Status, report.Ready(), tt.want, tt.wantReady)
+			}
+
+			redis := report.Checks["redis"]
+			if !redis.Optional || report.Checks["postgres"].Optional {
+				t.Fatalf("optional flags = redis %v, postgres %v", redis.Optional, report.Checks["postgres"].Optional)
+			}
+
+			// Отказ необязательной зависимости всё равно виден в отчёте
+			if tt.want == StatusDegraded && (redis.Status != StatusUnavailable || redis.Error == "") {
+				t.Fatalf("redis result = %+v in a degraded report", redis)
+			}
+		})
+	}
+}
+
+func TestReadyWhileDraining(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddOptional("redis", ok)
+	checker.Drain()
+
+	if report := checker.Ready(context.Background()); report.Ready() {
+		t.Fatalf("draining report = %+v, want not ready", report)
+	}
+}
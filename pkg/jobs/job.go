@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	// StatusDead — задача исчерпала попытки и ждёт разбора или ручного повтора
+	StatusDead = "dead"
+)
+
+// DefaultQueue — очередь для типов, у которых она не указана
+const DefaultQueue = "default"
+
+// Job — строка очереди. Успешно выполненные задачи удаляются, так что в таблице
+// остаются только ждущие, выполняющиеся и мёртвые
+type Job struct {
+	Id          int64           `db:"id" json:"id"`
+	Queue       string          `db:"queue" json:"queue"`
+	Type        string          `db:"type" json:"type"`
+	Payload     json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	Status      string          `db:"status" json:"status"`
+	Attempts    int             `db:"attempts" json:"attempts"`
+	MaxAttempts int             `db:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time       `db:"run_at" json:"run_at"`
+	LockedUntil *time.Time      `db:"locked_until" json:"locked_until,omitempty"`
+	LastError   *string         `db:"last_error" json:"last_error,omitempty"`
+	RequestId   *string         `db:"request_id" json:"request_id,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Count — число задач очереди в одном статусе
+type Count struct {
+	Queue  string `db:"queue" json:"queue"`
+	Status string `db:"status" json:"status"`
+	Count  int    `db:"count" json:"count"`
+}
+
+// Store хранит задачи. Claim должен забирать строки через FOR UPDATE SKIP LOCKED,
+// чтобы несколько экземпляров не взяли одну задачу
+type Store interface {
+	Insert(ctx context.Context, job Job) (Job, error)
+	// Claim помечает до limit готовых задач очереди выполняющимися до now+lease и увеличивает
+	// им счётчик попыток. Выполняющиеся задачи с истёкшей арендой считаются готовыми:
+	// их воркер упал или экземпляр остановился, не доделав
+	Claim(ctx context.Context, queue string, limit int, lease time.Duration) ([]Job, error)
+	// Complete, Reschedule и Bury записывают итог попытки job.Attempts
+	Complete(ctx context.Context, job Job) error
+	Reschedule(ctx context.Context, job Job, runAt time.Time, lastError string) error
+	Bury(ctx context.Context, job Job, lastError string) error
+	Counts(ctx context.Context) ([]Count, error)
+}
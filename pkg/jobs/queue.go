@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval  = time.Second
+	defaultRetryDelay    = 10 * time.Second
+	defaultMaxRetryDelay = time.Hour
+	defaultMaxAttempts   = 5
+	defaultTimeout       = time.Minute
+	defaultConcurrency   = 1
+
+	// leaseSlack — запас аренды сверх таймаута задачи, чтобы медленная запись итога
+	// не отдала задачу второму воркеру
+	leaseSlack = 30 * time.Second
+	// storeTimeout ограничивает запись итога задачи
+	storeTimeout = 5 * time.Second
+	// countsInterval — как часто обновляются метрики глубины очередей
+	countsInterval = 15 * time.Second
+)
+
+type Config struct {
+	// PollInterval — как часто пустая очередь проверяется на новые задачи
+	PollInterval time.Duration
+	// RetryDelay — задержка перед второй попыткой, дальше она удваивается до MaxRetryDelay
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// Concurrency — сколько задач каждой очереди выполняется одновременно на экземпляре
+	Concurrency map[string]int
+}
+
+// Definition описывает тип задачи. Нулевые поля берутся из значений по умолчанию и Config
+type Definition struct {
+	Type        string
+	Queue       string
+	MaxAttempts int
+	// RetryDelay перекрывает Config.RetryDelay для этого типа
+	RetryDelay time.Duration
+	// Timeout — сколько может выполняться одна попытка
+	Timeout time.Duration
+}
+
+type handler struct {
+	def Definition
+	run func(ctx context.Context, payload json.RawMessage) error
+}
+
+// Queue раздаёт задачи из Store обработчикам зарегистрированных типов
+type Queue struct {
+	store    Store
+	cfg      Config
+	handlers map[string]handler
+
+	wg sync.WaitGroup
+	// cancelJobs прерывает начатые задачи, если остановка не дождалась их
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+}
+
+func NewQueue(store Store, cfg Config) *Queue {
+	cfg = validateConfig(cfg)
+
+	jobsCtx, cancel := context.WithCancel(context.Background())
+
+	return &Queue{
+		store:      store,
+		cfg:        cfg,
+		handlers:   make(map[string]handler),
+		jobsCtx:    jobsCtx,
+		cancelJobs: cancel,
+	}
+}
+
+func validateConfig(cfg Config) Config {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		cfg.MaxRetryDelay = max(defaultMaxRetryDelay, cfg.RetryDelay)
+	}
+
+	return cfg
+}
+
+// Type — зарегистрированный тип задачи с полезной нагрузкой T
+type Type[T any] struct {
+	queue *Queue
+	name  string
+}
+
+// Register добавляет тип задачи. Вызывается при сборке приложения, до Run
+func Register[T any](q *Queue, def Definition, fn func(ctx context.Context, payload T) error) Type[T] {
+	if def.Queue == "" {
+		def.Queue = DefaultQueue
+	}
+
+	if def.MaxAttempts <= 0 {
+		def.MaxAttempts = defaultMaxAttempts
+	}
+
+	if def.RetryDelay <= 0 {
+		def.RetryDelay = q.cfg.RetryDelay
+	}
+
+	if def.Timeout <= 0 {
+		def.Timeout = defaultTimeout
+	}
+
+	if _, ok := q.handlers[def.Type]; ok {
+		panic(fmt.Sprintf("jobs: type %q registered twice", def.Type))
+	}
+
+	q.handlers[def.Type] = handler{
+		def: def,
+		run: func(ctx context.Context, raw json.RawMessage) error {
+			var payload T
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				return fmt.Errorf("decode payload: %w", err)
+			}
+
+			return fn(ctx, payload)
+		},
+	}
+
+	return Type[T]{queue: q, name: def.Type}
+}
+
+// Option меняет задачу перед постановкой в очередь
+type Option func(job *Job)
+
+// RunAt откладывает задачу до времени t
+func RunAt(t time.Time) Option {
+	return func(job *Job) {
+		job.RunAt = t
+	}
+}
+
+// Enqueue сохраняет задачу. Она переживёт перезапуск и выполнится на любом экземпляре
+func (t Type[T]) Enqueue(ctx context.Context, payload T, opts ...Option) (Job, error) {
+	h := t.queue.handlers[t.name]
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("encode %s payload: %w", t.name, err)
+	}
+
+	job := Job{
+		Queue:       h.def.Queue,
+		Type:        h.def.Type,
+		Payload:     raw,
+		Status:      StatusPending,
+		MaxAttempts: h.def.MaxAttempts,
+		RunAt:       time.Now(),
+	}
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	return t.queue.store.Insert(ctx, attachRequestId(ctx, job))
+}
+
+// backoff — задержка перед следующей попыткой после attempt неудачных, с разбросом
+// до 20%, чтобы задачи, упавшие вместе, не возвращались одновременно
+func (q *Queue) backoff(def Definition, attempt int) time.Duration {
+	delay := def.RetryDelay
+	for i := 1; i < attempt && delay < q.cfg.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, q.cfg.MaxRetryDelay)
+
+	return delay + rand.N(delay/5+1)
+}
+
+// queues возвращает очереди зарегистрированных типов с арендой по самому долгому типу
+func (q *Queue) queues() map[string]time.Duration {
+	result := make(map[string]time.Duration)
+
+	for _, h := range q.handlers {
+		result[h.def.Queue] = max(result[h.def.Queue], h.def.Timeout+leaseSlack)
+	}
+
+	return result
+}
+
+func (q *Queue) concurrency(queue string) int {
+	if n := q.cfg.Concurrency[queue]; n > 0 {
+		return n
+	}
+
+	return defaultConcurrency
+}
@@ -0,0 +1,351 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nevermore/pkg/requestid"
+)
+
+// fakeStore держит задачи в памяти и, как SQL-хранилище, принимает итог попытки,
+// только пока задача выполняется именно с этим номером попытки
+type fakeStore struct {
+	mu     sync.Mutex
+	nextId int64
+	jobs   map[int64]Job
+	// calls — какие итоги записывались, по порядку
+	calls []string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{jobs: make(map[int64]Job)}
+}
+
+func (s *fakeStore) Insert(_ context.Context, job Job) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextId++
+	job.Id = s.nextId
+	s.jobs[job.Id] = job
+
+	return job, nil
+}
+
+// claim выдаёт задачу id как очередную попытку, не глядя на run_at
+func (s *fakeStore) claim(id int64) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[id]
+	job.Status = StatusRunning
+	job.Attempts++
+	s.jobs[id] = job
+
+	return job
+}
+
+func (s *fakeStore) Claim(context.Context, string, int, time.Duration) ([]Job, error) {
+	return nil, nil
+}
+
+// current проверяет, что итог пишет попытка, которая сейчас владеет задачей
+func (s *fakeStore) current(job Job) (Job, bool) {
+	stored, ok := s.jobs[job.Id]
+
+	return stored, ok && stored.Status == StatusRunning && stored.Attempts == job.Attempts
+}
+
+func (s *fakeStore) Complete(_ context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, "complete")
+	if _, ok := s.current(job); ok {
+		delete(s.jobs, job.Id)
+	}
+
+	return nil
+}
+
+func (s *fakeStore) Reschedule(_ context.Context, job Job, runAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, "reschedule")
+	if stored, ok := s.current(job); ok {
+		stored.Status = StatusPending
+		stored.RunAt = runAt
+		stored.LastError = &lastError
+		s.jobs[job.Id] = stored
+	}
+
+	return nil
+}
+
+func (s *fakeStore) Bury(_ context.Context, job Job, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, "bury")
+	if stored, ok := s.current(job); ok {
+		stored.Status = StatusDead
+		stored.LastError = &lastError
+		s.jobs[job.Id] = stored
+	}
+
+	return nil
+}
+
+func (s *fakeStore) Counts(context.Context) ([]Count, error) {
+	return nil, nil
+}
+
+type testPayload struct {
+	Value string `json:"value"`
+}
+
+func TestBackoff(t *testing.T) {
+	q := NewQueue(newFakeStore(), Config{RetryDelay: 10 * time.Second, MaxRetryDelay: time.Minute})
+	def := Definition{RetryDelay: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		// Дальше упираемся в MaxRetryDelay
+		{4, time.Minute},
+		{10, time.Minute},
+		// Удвоение останавливается на потолке и не переполняется
+		{1000, time.Minute},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			delay := q.backoff(def, tt.attempt)
+			if delay < tt.base || delay > tt.base+tt.base/5 {
+				t.Fatalf("backoff(%d) = %v, want %v plus up to 20%%", tt.attempt, delay, tt.base)
+			}
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := validateConfig(Config{RetryDelay: 2 * time.Hour, MaxRetryDelay: time.Minute})
+
+	// Потолок не может быть меньше первой задержки
+	if cfg.MaxRetryDelay != 2*time.Hour {
+		t.Fatalf("MaxRetryDelay = %v, want 2h", cfg.MaxRetryDelay)
+	}
+
+	cfg = validateConfig(Config{})
+	if cfg.PollInterval != defaultPollInterval || cfg.RetryDelay != defaultRetryDelay || cfg.MaxRetryDelay != defaultMaxRetryDelay {
+		t.Fatalf("defaults not applied: %+v", cfg)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	store := newFakeStore()
+	q := NewQueue(store, Config{})
+	typ := Register(q, Definition{Type: "test.enqueue", MaxAttempts: 3}, func(context.Context, testPayload) error {
+		return nil
+	})
+
+	runAt := time.Now().Add(time.Hour)
+	ctx := requestid.Attach(context.Background(), "req-1")
+
+	job, err := typ.Enqueue(ctx, testPayload{Value: "x"}, RunAt(runAt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Queue != DefaultQueue || job.Status != StatusPending || job.MaxAttempts != 3 || !job.RunAt.Equal(runAt) {
+		t.Fatalf("enqueued %+v", job)
+	}
+
+	if string(job.Payload) != `{"value":"x"}` {
+		t.Fatalf("payload = %s", job.Payload)
+	}
+
+	if job.RequestId == nil || *job.RequestId != "req-1" {
+		t.Fatalf("request id = %v, want req-1", job.RequestId)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name string
+		// claims — сколько раз задачу уже выдавали, включая текущую попытку
+		claims      int
+		handler     func(context.Context, testPayload) error
+		payload     string
+		typ         string
+		wantRan     bool
+		wantCall    string
+		wantStatus  string
+		wantErrPart string
+	}{
+		{
+			name:     "success deletes the job",
+			claims:   1,
+			handler:  func(context.Context, testPayload) error { return nil },
+			wantRan:  true,
+			wantCall: "complete",
+		},
+		{
+			name:        "failure is retried",
+			claims:      1,
+			handler:     func(context.Context, testPayload) error { return errBoom },
+			wantRan:     true,
+			wantCall:    "reschedule",
+			wantStatus:  StatusPending,
+			wantErrPart: "boom",
+		},
+		{
+			name:        "last attempt goes to dead letters",
+			claims:      3,
+			handler:     func(context.Context, testPayload) error { return errBoom },
+			wantRan:     true,
+			wantCall:    "bury",
+			wantStatus:  StatusDead,
+			wantErrPart: "boom",
+		},
+		{
+			name:        "lease expired on the last attempt",
+			claims:      4,
+			handler:     func(context.Context, testPayload) error { return nil },
+			wantCall:    "bury",
+			wantStatus:  StatusDead,
+			wantErrPart: "lease expired",
+		},
+		{
+			name:        "panic is a failed attempt",
+			claims:      1,
+			handler:     func(context.Context, testPayload) error { panic("oops") },
+			wantRan:     true,
+			wantCall:    "reschedule",
+			wantStatus:  StatusPending,
+			wantErrPart: "panic: oops",
+		},
+		{
+			name:        "broken payload is a failed attempt",
+			claims:      1,
+			handler:     func(context.Context, testPayload) error { return nil },
+			payload:     `{"value": 1}`,
+			wantCall:    "reschedule",
+			wantStatus:  StatusPending,
+			wantErrPart: "decode payload",
+		},
+		{
+			name:        "unknown type is buried at once",
+			claims:      1,
+			handler:     func(context.Context, testPayload) error { return nil },
+			typ:         "test.unknown",
+			wantCall:    "bury",
+			wantStatus:  StatusDead,
+			wantErrPart: "unknown job type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			q := NewQueue(store, Config{RetryDelay: time.Minute})
+
+			ran := false
+			Register(q, Definition{Type: "test.process", MaxAttempts: 3}, func(ctx context.Context, payload testPayload) error {
+				ran = true
+				return tt.handler(ctx, payload)
+			})
+
+			job := Job{Queue: DefaultQueue, Type: "test.process", Payload: json.RawMessage(`{"value":"x"}`), Status: StatusPending, MaxAttempts: 3}
+			if tt.payload != "" {
+				job.Payload = json.RawMessage(tt.payload)
+			}
+			if tt.typ != "" {
+				job.Type = tt.typ
+			}
+
+			job, _ = store.Insert(context.Background(), job)
+			for i := 0; i < tt.claims; i++ {
+				job = store.claim(job.Id)
+			}
+
+			before := time.Now()
+			q.process(job)
+
+			if ran != tt.wantRan {
+				t.Fatalf("handler ran = %v, want %v", ran, tt.wantRan)
+			}
+
+			if len(store.calls) != 1 || store.calls[0] != tt.wantCall {
+				t.Fatalf("store calls = %v, want [%s]", store.calls, tt.wantCall)
+			}
+
+			stored, exists := store.jobs[job.Id]
+			if tt.wantStatus == "" {
+				if exists {
+					t.Fatalf("job still stored: %+v", stored)
+				}
+				return
+			}
+
+			if stored.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+
+			if stored.LastError == nil || !strings.Contains(*stored.LastError, tt.wantErrPart) {
+				t.Fatalf("last error = %v, want it to mention %q", stored.LastError, tt.wantErrPart)
+			}
+
+			if tt.wantStatus == StatusPending && stored.RunAt.Before(before.Add(time.Minute)) {
+				t.Fatalf("rescheduled to %v, want at least a minute later", stored.RunAt)
+			}
+		})
+	}
+}
+
+// Воркер, чья аренда истекла, дописывает итог уже после того, как задачу выдали снова:
+// его запись должна ссылаться на его попытку, чтобы хранилище её отбросило
+func TestProcessStaleAttemptDoesNotOverwrite(t *testing.T) {
+	store := newFakeStore()
+	q := NewQueue(store, Config{})
+
+	release := make(chan struct{})
+	Register(q, Definition{Type: "test.stale"}, func(context.Context, testPayload) error {
+		<-release
+		return errors.New("too late")
+	})
+
+	job, _ := store.Insert(context.Background(), Job{Type: "test.stale", Payload: json.RawMessage(`{}`), Status: StatusPending, MaxAttempts: 1})
+	stale := store.claim(job.Id)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.process(stale)
+	}()
+
+	// Пока первая попытка висит, аренда истекает и задачу забирает другой воркер
+	current := store.claim(job.Id)
+	close(release)
+	<-done
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	stored := store.jobs[job.Id]
+	if stored.Status != StatusRunning || stored.Attempts != current.Attempts || stored.LastError != nil {
+		t.Fatalf("stale attempt changed the job: %+v", stored)
+	}
+}
@@ -0,0 +1,225 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"nevermore/pkg/logger"
+	"nevermore/pkg/metrics"
+	"nevermore/pkg/requestid"
+	"nevermore/pkg/tracing"
+)
+
+// Run забирает задачи всех очередей, пока не отменён ctx. Начатые задачи после
+// отмены продолжают выполняться, их дожидается Wait
+func (q *Queue) Run(ctx context.Context) {
+	queues := q.queues()
+
+	for queue, lease := range queues {
+		q.wg.Add(1)
+
+		go func() {
+			defer q.wg.Done()
+			q.poll(ctx, queue, lease)
+		}()
+	}
+
+	q.reportCounts(ctx)
+}
+
+// Wait дожидается начатых задач. Если ctx истёк раньше, задачи прерываются, а их
+// аренда истечёт и их заберёт следующий запуск
+func (q *Queue) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancelJobs()
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) poll(ctx context.Context, queue string, lease time.Duration) {
+	log := logger.Named("jobs").With().Str("queue", queue).Logger()
+
+	limit := q.concurrency(queue)
+	slots := make(chan struct{}, limit)
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		free := limit - len(slots)
+		claimed := 0
+
+		if free > 0 {
+			list, err := q.store.Claim(ctx, queue, free, lease)
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("failed to claim jobs")
+			}
+
+			for _, job := range list {
+				slots <- struct{}{}
+				q.wg.Add(1)
+
+				go func() {
+					defer func() {
+						<-slots
+						q.wg.Done()
+					}()
+
+					q.process(job)
+				}()
+			}
+
+			claimed = len(list)
+		}
+
+		// Забрали сколько могли — скорее всего, готово ещё, не ждём тика
+		if claimed > 0 && claimed == free {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) process(job Job) {
+	ctx := q.jobsCtx
+	if job.RequestId != nil {
+		ctx = requestid.Attach(ctx, *job.RequestId)
+	}
+
+	ctx, span := tracing.StartJob(ctx, "job "+job.Type)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("job.id", job.Id),
+		attribute.String("job.queue", job.Queue),
+		attribute.Int("job.attempt", job.Attempts),
+	)
+
+	log := logger.NamedFromContext(ctx, "jobs").With().
+		Int64("job_id", job.Id).
+		Str("queue", job.Queue).
+		Str("type", job.Type).
+		Int("attempt", job.Attempts).
+		Logger()
+
+	h, ok := q.handlers[job.Type]
+
+	var err error
+	switch {
+	case !ok:
+		err = fmt.Errorf("unknown job type %q", job.Type)
+	case job.Attempts > job.MaxAttempts:
+		// Последняя попытка не записала итог: экземпляр остановился посреди задачи
+		err = errors.New("lease expired on the last attempt")
+	default:
+		start := time.Now()
+		active := metrics.JobsActive.WithLabelValues(job.Queue)
+
+		active.Inc()
+		err = q.run(ctx, h, job)
+		active.Dec()
+
+		metrics.JobDuration.WithLabelValues(job.Queue, job.Type).Observe(time.Since(start).Seconds())
+	}
+
+	// Итог пишется и тогда, когда задачу прервала остановка
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+
+	var (
+		result   string
+		storeErr error
+	)
+
+	switch {
+	case err == nil:
+		result = "done"
+		storeErr = q.store.Complete(storeCtx, job)
+	case !ok || job.Attempts >= job.MaxAttempts:
+		result = "dead"
+		tracing.Fail(span, err)
+		log.Error().Err(err).Msg("job failed, moved to dead letters")
+		storeErr = q.store.Bury(storeCtx, job, err.Error())
+	default:
+		result = "retry"
+		delay := q.backoff(h.def, job.Attempts)
+		tracing.Fail(span, err)
+		log.Warn().Err(err).Dur("retry_in", delay).Msg("job failed, will retry")
+		storeErr = q.store.Reschedule(storeCtx, job, time.Now().Add(delay), err.Error())
+	}
+
+	metrics.JobsProcessed.WithLabelValues(job.Queue, job.Type, result).Inc()
+
+	// Не записали итог — после аренды задача вернётся и выполнится ещё раз
+	if storeErr != nil {
+		log.Error().Err(storeErr).Str("result", result).Msg("failed to store job result")
+	}
+}
+
+// run выполняет попытку с таймаутом типа; паника обработчика — ошибка попытки
+func (q *Queue) run(ctx context.Context, h handler, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, h.def.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return h.run(ctx, job.Payload)
+}
+
+// reportCounts обновляет метрики глубины очередей, пока не отменён ctx
+func (q *Queue) reportCounts(ctx context.Context) {
+	ticker := time.NewTicker(countsInterval)
+	defer ticker.Stop()
+
+	for {
+		counts, err := q.store.Counts(ctx)
+		if err == nil {
+			metrics.JobsQueued.Reset()
+
+			for _, c := range counts {
+				metrics.JobsQueued.WithLabelValues(c.Queue, c.Status).Set(float64(c.Count))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func attachRequestId(ctx context.Context, job Job) Job {
+	if id := requestid.FromContext(ctx); id != "" {
+		job.RequestId = &id
+	}
+
+	return job
+}
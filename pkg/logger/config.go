@@ -70,6 +70,16 @@ func validateConfig(cfg Config) Config {
 	return cfg
 }
 
+// ValidLevel сообщает, знает ли логгер такой уровень; неизвестный уровень превратился бы в DEBUG
+func ValidLevel(s string) bool {
+	switch s {
+	case LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic:
+		return true
+	default:
+		return false
+	}
+}
+
 func parseLogLevel(s string) zerolog.Level {
 	switch s {
 	case LevelDebug:
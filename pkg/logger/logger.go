@@ -20,6 +20,7 @@ var (
 
 var (
 	baseLogger *zerolog.Logger
+	output     *lumberjack.Logger
 )
 
 func Get() zerolog.Logger {
@@ -42,13 +43,23 @@ func WrapToContext(ctx context.Context, logger zerolog.Logger) context.Context {
 	return context.WithValue(ctx, txContextKey{}, logger)
 }
 
+// FromContext возвращает логгер запроса. Если его в контексте нет, например в фоновой
+// задаче, возвращается базовый логгер, а до Init — логгер, который ничего не пишет
 func FromContext(ctx context.Context) zerolog.Logger {
-	logger, ok := ctx.Value(txContextKey{}).(zerolog.Logger)
-	if !ok {
-		panic(errNilBaseLogger)
+	if logger, ok := ctx.Value(txContextKey{}).(zerolog.Logger); ok {
+		return logger
+	}
+
+	if baseLogger != nil {
+		return *baseLogger
 	}
 
-	return logger
+	return zerolog.Nop()
+}
+
+// NamedFromContext — как Named, но сохраняет поля запроса из контекста
+func NamedFromContext(ctx context.Context, name string) zerolog.Logger {
+	return FromContext(ctx).With().Str("name", name).Logger()
 }
 
 func Init(cfg Config) error {
@@ -76,13 +87,29 @@ func Init(cfg Config) error {
 
 	zerolog.TimeFieldFormat = cfg.TimeFormat
 
+	SetLevel(cfg.Level)
+
 	logger := zerolog.New(w).
-		Level(parseLogLevel(cfg.Level)).
 		With().
 		Timestamp().
 		Logger()
 
 	baseLogger = &logger
+	output = rot
 
 	return nil
 }
+
+// Close закрывает файл журнала при остановке. Записи после Close откроют его заново
+func Close() error {
+	if output == nil {
+		return nil
+	}
+
+	return output.Close()
+}
+
+// SetLevel меняет уровень на лету, в том числе для уже созданных именованных логгеров
+func SetLevel(level string) {
+	zerolog.SetGlobalLevel(parseLogLevel(level))
+}
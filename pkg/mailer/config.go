@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"time"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverDir  = "dir"
+)
+
+const (
+	defaultDriver      = DriverDir
+	defaultDir         = "runtime/mail"
+	defaultPort        = 587
+	defaultFrom        = "Nevermore <no-reply@nevermore.local>"
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = 5 * time.Second
+	defaultSendTimeout = 30 * time.Second
+)
+
+type Config struct {
+	Driver      string
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	ImplicitTLS bool
+	From        string
+	Dir         string
+	MaxAttempts int
+	RetryDelay  time.Duration
+	SendTimeout time.Duration
+}
+
+func validateConfig(cfg Config) Config {
+	if cfg.Driver == "" {
+		cfg.Driver = defaultDriver
+	}
+
+	if cfg.Dir == "" {
+		cfg.Dir = defaultDir
+	}
+
+	if cfg.Port == 0 {
+		cfg.Port = defaultPort
+	}
+
+	if cfg.From == "" {
+		cfg.From = defaultFrom
+	}
+
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+
+	if cfg.RetryDelay == 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	if cfg.SendTimeout == 0 {
+		cfg.SendTimeout = defaultSendTimeout
+	}
+
+	return cfg
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirMailer складывает письма в каталог файлами .eml вместо отправки.
+// Используется в разработке и тестах
+type DirMailer struct {
+	dir  string
+	from string
+}
+
+func NewDir(dir, from string) (*DirMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir '%s' failed to mail dir: %w", dir, err)
+	}
+
+	return &DirMailer{dir: dir, from: from}, nil
+}
+
+func (m *DirMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body, err := msg.build(m.from)
+	if err != nil {
+		return err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000"), id[:8])
+
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
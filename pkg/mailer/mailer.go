@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+type Message struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text"`
+	HTML    string   `json:"html"`
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создаёт отправителя по cfg.Driver: SMTP для продакшена или запись в каталог для разработки и тестов
+func New(cfg Config) (Mailer, error) {
+	cfg = validateConfig(cfg)
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("mailer: smtp host is required")
+		}
+
+		return NewSMTP(cfg), nil
+	case DriverDir:
+		return NewDir(cfg.Dir, cfg.From)
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver '%s'", cfg.Driver)
+	}
+}
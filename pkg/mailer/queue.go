@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+
+	"nevermore/pkg/jobs"
+)
+
+const (
+	// SendJob — тип задачи отправки письма
+	SendJob = "mail.send"
+	// JobQueue — отдельная очередь, чтобы письма не ждали за долгими задачами
+	JobQueue = "mail"
+)
+
+// Queue отправляет письма через постоянную очередь задач: письмо переживает перезапуск,
+// а неудачные попытки повторяются с экспоненциальной задержкой
+type Queue struct {
+	send jobs.Type[Message]
+}
+
+func NewQueue(m Mailer, q *jobs.Queue, cfg Config) *Queue {
+	cfg = validateConfig(cfg)
+
+	return &Queue{
+		send: jobs.Register(q, jobs.Definition{
+			Type:        SendJob,
+			Queue:       JobQueue,
+			MaxAttempts: cfg.MaxAttempts,
+			RetryDelay:  cfg.RetryDelay,
+			Timeout:     cfg.SendTimeout,
+		}, m.Send),
+	}
+}
+
+// Enqueue сохраняет письмо в очередь. Id запроса из ctx попадает в задачу для логов
+func (q *Queue) Enqueue(ctx context.Context, msg Message) error {
+	_, err := q.send.Enqueue(ctx, msg)
+
+	return err
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// JobsQueued — задачи в таблице по очереди и статусу, обновляется раз в несколько секунд
+	JobsQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "queued",
+		Help:      "Jobs stored in the queue by queue and status.",
+	}, []string{"queue", "status"})
+
+	JobsActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "active",
+		Help:      "Jobs running on this instance by queue.",
+	}, []string{"queue"})
+
+	JobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "processed_total",
+		Help:      "Finished job attempts by queue, type and result: done, retry or dead.",
+	}, []string{"queue", "type", "result"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "jobs",
+		Name:      "duration_seconds",
+		Help:      "Job attempt run time by queue and type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"queue", "type"})
+)
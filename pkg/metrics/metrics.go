@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace — префикс всех метрик приложения. Метрики рантайма Go и процесса
+// отдаёт стандартный реестр prometheus
+const namespace = "nevermore"
+
+// Методы входа и регистрации для доменных счётчиков
+const (
+	MethodPassword  = "password"
+	MethodTwoFactor = "2fa"
+	MethodOIDC      = "oidc"
+)
+
+// Виды загружаемого контента для счётчика Uploads. Отдельной загрузки файлов в API нет,
+// поэтому считаются пути, по которым контент попадает в систему
+const (
+	UploadBookText    = "book_text"
+	UploadShelfImport = "shelf_import"
+)
+
+var (
+	// HTTPRequests и HTTPDuration размечены шаблоном маршрута, а не путём,
+	// чтобы id в путях не раздували число рядов
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejections_total",
+		Help:      "Requests rejected by the rate limiter by policy scope.",
+	}, []string{"scope"})
+
+	SignUps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sign_ups_total",
+		Help:      "Created accounts by sign-up method.",
+	}, []string{"method"})
+
+	SessionsStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_started_total",
+		Help:      "Issued access tokens by the way the user got in.",
+	}, []string{"method"})
+
+	Uploads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Ingested content by kind: indexed book texts and imported shelves.",
+	}, []string{"kind"})
+)
+
+// Handler отдаёт метрики в текстовом формате prometheus
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"nevermore/pkg/logger"
+)
+
+// probeInterval — как часто после сбоя снова пробовать основной лимитер,
+// чтобы запросы не ждали таймаута недоступного Redis каждый раз
+const probeInterval = 5 * time.Second
+
+// FallbackLimiter обращается к основному лимитеру, а при его ошибке — к запасному.
+// Переход на запасной и возврат к основному пишутся в лог один раз
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	// failedAt — время последнего сбоя основного лимитера в наносекундах, 0 — работает
+	failedAt atomic.Int64
+}
+
+func NewFallback(primary, fallback Limiter) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (f *FallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	failedAt := f.failedAt.Load()
+	if failedAt != 0 && time.Since(time.Unix(0, failedAt)) < probeInterval {
+		return f.fallback.Allow(ctx, key, limit)
+	}
+
+	res, err := f.primary.Allow(ctx, key, limit)
+	if err == nil {
+		if f.failedAt.Swap(0) != 0 {
+			log := logger.Named("ratelimit")
+			log.Info().Msg("primary limiter recovered")
+		}
+
+		return res, nil
+	}
+
+	if f.failedAt.Swap(time.Now().UnixNano()) == 0 {
+		log := logger.Named("ratelimit")
+		log.Warn().Err(err).Msg("primary limiter failed, using in-memory fallback")
+	}
+
+	return f.fallback.Allow(ctx, key, limit)
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"sync/atomic"
+)
+
+const defaultScope = "default"
+
+// Policy задаёт лимиты для анонимных запросов (по IP), для авторизованных
+// пользователей и отдельно для некоторых ролей. Пустые поля не ограничивают
+type Policy struct {
+	Anonymous     *Limit
+	Authenticated *Limit
+	Roles         map[string]Limit
+}
+
+// limit выбирает лимит для роли; пустая роль — анонимный запрос
+func (p Policy) limit(role string) (Limit, bool) {
+	if role == "" {
+		if p.Anonymous == nil {
+			return Limit{}, false
+		}
+
+		return *p.Anonymous, true
+	}
+
+	if limit, ok := p.Roles[role]; ok {
+		return limit, true
+	}
+
+	if p.Authenticated == nil {
+		return Limit{}, false
+	}
+
+	return *p.Authenticated, true
+}
+
+// Policies — общие лимиты и переопределения для маршрутов вида "POST /shelf/import".
+// У переопределённого маршрута своя корзина, остальные маршруты делят общую
+type Policies struct {
+	Default Policy
+	Routes  map[string]Policy
+}
+
+// Resolve возвращает область корзины и лимит для маршрута и роли
+func (p Policies) Resolve(route, role string) (string, Limit, bool) {
+	if policy, ok := p.Routes[route]; ok {
+		if limit, ok := policy.limit(role); ok {
+			return route, limit, true
+		}
+	}
+
+	limit, ok := p.Default.limit(role)
+
+	return defaultScope, limit, ok
+}
+
+// PolicyStore хранит текущие политики и позволяет заменить их на лету,
+// например при перечитывании конфигурации
+type PolicyStore struct {
+	policies atomic.Pointer[Policies]
+}
+
+func NewPolicyStore(policies Policies) *PolicyStore {
+	result := &PolicyStore{}
+	result.Replace(policies)
+
+	return result
+}
+
+func (s *PolicyStore) Replace(policies Policies) {
+	s.policies.Store(&policies)
+}
+
+func (s *PolicyStore) Resolve(route, role string) (string, Limit, bool) {
+	return s.policies.Load().Resolve(route, role)
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit — корзина токенов: Burst запросов подряд, полностью восполняется за Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// rate — сколько токенов восполняется за миллисекунду
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / float64(l.Period.Milliseconds())
+}
+
+func (l Limit) Valid() bool {
+	return l.Burst > 0 && l.Period >= time.Millisecond
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter — когда появится следующий токен, если запрос отклонён
+	RetryAfter time.Duration
+	// ResetAfter — когда корзина наполнится полностью
+	ResetAfter time.Duration
+}
+
+// Limiter списывает токен из корзины key
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result пересчитывает остаток корзины в ответ лимитера
+func result(limit Limit, allowed bool, tokens float64) Result {
+	rate := limit.rate()
+
+	res := Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(limit.Burst)-tokens)/rate) * time.Millisecond,
+	}
+
+	if !allowed {
+		res.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+
+	return res
+}
@@ -0,0 +1,250 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"nevermore/pkg/logger"
+)
+
+// testLimit восполняет ровно 1/1024 токена за миллисекунду, чтобы арифметика
+// с плавающей точкой в проверках была точной
+var testLimit = Limit{Burst: 2, Period: 2048 * time.Millisecond}
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "ratelimit-test")
+	if err != nil {
+		panic(err)
+	}
+
+	// FallbackLimiter пишет в лог переходы между лимитерами
+	if err := logger.Init(logger.Config{Dir: dir, Level: "ERROR"}); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed bool
+		tokens  float64
+		want    Result
+	}{
+		{"full after take", true, 1, Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 1024 * time.Millisecond}},
+		{"fractional remaining rounds down", true, 1.5, Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 512 * time.Millisecond}},
+		{"last token taken", true, 0, Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 2048 * time.Millisecond}},
+		{"denied empty", false, 0, Result{Limit: 2, RetryAfter: 1024 * time.Millisecond, ResetAfter: 2048 * time.Millisecond}},
+		{"denied partly refilled", false, 0.25, Result{Limit: 2, RetryAfter: 768 * time.Millisecond, ResetAfter: 1792 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result(testLimit, tt.allowed, tt.tokens); got != tt.want {
+				t.Fatalf("result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultRetryAfterRoundsUp(t *testing.T) {
+	limit := Limit{Burst: 3, Period: time.Second}
+
+	// До токена 333.33 мс: клиенту нельзя советовать прийти раньше
+	res := result(limit, false, 0)
+	if res.RetryAfter != 334*time.Millisecond {
+		t.Fatalf("RetryAfter = %v, want 334ms", res.RetryAfter)
+	}
+}
+
+func TestLimitValid(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		want  bool
+	}{
+		{Limit{Burst: 1, Period: time.Millisecond}, true},
+		{Limit{Burst: 0, Period: time.Second}, false},
+		{Limit{Burst: 1, Period: time.Microsecond}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limit.Valid(); got != tt.want {
+			t.Errorf("%+v.Valid() = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
+
+// rewind сдвигает последнее обновление корзины в прошлое вместо ожидания
+func rewind(m *MemoryLimiter, key string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.buckets[key].updated = m.buckets[key].updated.Add(-d)
+}
+
+func TestMemoryLimiterRefill(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	steps := []struct {
+		name          string
+		rewind        time.Duration
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{"first request", 0, true, 1},
+		{"burst exhausted", 0, true, 0},
+		{"empty bucket", 0, false, 0},
+		{"one token refilled", 1024 * time.Millisecond, true, 0},
+		{"refill is capped at burst", time.Hour, true, 1},
+	}
+
+	for _, step := range steps {
+		if step.rewind > 0 {
+			rewind(m, "user:1", step.rewind)
+		}
+
+		res, err := m.Allow(ctx, "user:1", testLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Allowed != step.wantAllowed || res.Remaining != step.wantRemaining {
+			t.Fatalf("%s: Allow = %+v, want allowed %v remaining %d", step.name, res, step.wantAllowed, step.wantRemaining)
+		}
+
+		if !res.Allowed && res.RetryAfter <= 0 {
+			t.Fatalf("%s: denied without RetryAfter", step.name)
+		}
+	}
+
+	// Корзины разных ключей не влияют друг на друга
+	if res, _ := m.Allow(ctx, "user:2", testLimit); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("other key: Allow = %+v", res)
+	}
+}
+
+func TestMemoryLimiterSweepsFullBuckets(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	m.Allow(ctx, "stale", testLimit)
+	rewind(m, "stale", time.Hour)
+
+	m.mu.Lock()
+	m.buckets["stale"].full = time.Now().Add(-time.Minute)
+	m.lastSweep = time.Now().Add(-2 * sweepInterval)
+	m.mu.Unlock()
+
+	m.Allow(ctx, "fresh", testLimit)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.buckets["stale"]; ok {
+		t.Fatal("full bucket survived the sweep")
+	}
+
+	if _, ok := m.buckets["fresh"]; !ok {
+		t.Fatal("bucket in use was swept")
+	}
+}
+
+type fakeLimiter struct {
+	err   error
+	calls int
+}
+
+func (f *fakeLimiter) Allow(_ context.Context, _ string, limit Limit) (Result, error) {
+	f.calls++
+	if f.err != nil {
+		return Result{}, f.err
+	}
+
+	return Result{Allowed: true, Limit: limit.Burst}, nil
+}
+
+func TestFallbackLimiter(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeLimiter{err: errors.New("redis is down")}
+	fallback := &fakeLimiter{}
+	f := NewFallback(primary, fallback)
+
+	allow := func() {
+		t.Helper()
+
+		if res, err := f.Allow(ctx, "ip:1", testLimit); err != nil || !res.Allowed {
+			t.Fatalf("Allow = %+v, %v", res, err)
+		}
+	}
+
+	// Сбой основного: запрос обслуживает запасной
+	allow()
+	if primary.calls != 1 || fallback.calls != 1 {
+		t.Fatalf("after failure: primary %d, fallback %d calls", primary.calls, fallback.calls)
+	}
+
+	// До probeInterval основной не трогаем, даже если он уже поднялся
+	primary.err = nil
+	allow()
+	if primary.calls != 1 || fallback.calls != 2 {
+		t.Fatalf("within probe interval: primary %d, fallback %d calls", primary.calls, fallback.calls)
+	}
+
+	// После probeInterval основной снова пробуется и, раз он работает, остаётся основным
+	f.failedAt.Store(time.Now().Add(-probeInterval - time.Second).UnixNano())
+	allow()
+	allow()
+	if primary.calls != 3 || fallback.calls != 2 {
+		t.Fatalf("after recovery: primary %d, fallback %d calls", primary.calls, fallback.calls)
+	}
+
+	if f.failedAt.Load() != 0 {
+		t.Fatal("failure mark is not cleared after recovery")
+	}
+}
+
+func TestPoliciesResolve(t *testing.T) {
+	anonymous := Limit{Burst: 10, Period: time.Minute}
+	authenticated := Limit{Burst: 60, Period: time.Minute}
+	admin := Limit{Burst: 600, Period: time.Minute}
+	upload := Limit{Burst: 1, Period: time.Minute}
+
+	policies := Policies{
+		Default: Policy{Anonymous: &anonymous, Authenticated: &authenticated, Roles: map[string]Limit{"admin": admin}},
+		Routes: map[string]Policy{
+			"POST /shelf/import": {Authenticated: &upload},
+		},
+	}
+
+	tests := []struct {
+		route, role string
+		wantScope   string
+		wantLimit   Limit
+		wantOk      bool
+	}{
+		{"GET /books", "", defaultScope, anonymous, true},
+		{"GET /books", "user", defaultScope, authenticated, true},
+		{"GET /books", "admin", defaultScope, admin, true},
+		{"POST /shelf/import", "user", "POST /shelf/import", upload, true},
+		// Роль без своего лимита на маршруте проваливается в общие лимиты
+		{"POST /shelf/import", "", defaultScope, anonymous, true},
+	}
+
+	for _, tt := range tests {
+		scope, limit, ok := policies.Resolve(tt.route, tt.role)
+		if scope != tt.wantScope || limit != tt.wantLimit || ok != tt.wantOk {
+			t.Errorf("Resolve(%q, %q) = %q, %+v, %v", tt.route, tt.role, scope, limit, ok)
+		}
+	}
+
+	if _, _, ok := (Policies{}).Resolve("GET /books", ""); ok {
+		t.Error("empty policies should not limit")
+	}
+}
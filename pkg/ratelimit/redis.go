@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// tokenBucket атомарно восполняет и списывает токен. Время берётся у Redis,
+// чтобы расхождение часов между экземплярами не влияло на лимиты
+var tokenBucket = goredis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1]) or capacity
+local updated = tonumber(bucket[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter хранит корзины в Redis, поэтому лимиты общие для всех экземпляров
+type RedisLimiter struct {
+	client *goredis.Client
+}
+
+func NewRedis(client *goredis.Client) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+	}
+}
+
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	// Корзина живёт, пока не наполнится: полная корзина равна отсутствующей
+	ttl := limit.Period.Milliseconds()
+
+	values, err := tokenBucket.Run(ctx, r.client, []string{keyPrefix + key},
+		limit.Burst,
+		strconv.FormatFloat(limit.rate(), 'f', -1, 64),
+		ttl,
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+
+	tokens, err := strconv.ParseFloat(values[1].(string), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result(limit, allowed == 1, tokens), nil
+}
@@ -0,0 +1,57 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"nevermore/pkg/logger"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	// maxLength ограничивает id от клиента, чтобы он не раздувал логи
+	maxLength = 128
+)
+
+type contextKey struct{}
+
+// New возвращает случайный id для запроса или фоновой задачи
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Valid пропускает id от клиента, только если он короткий и из безопасных символов
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, r := range id {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '-' || r == '_' || r == '.' || r == ':'
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Attach кладёт id в контекст и добавляет его к логгеру из контекста
+func Attach(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, contextKey{}, id)
+	log := logger.FromContext(ctx).With().Str("request_id", id).Logger()
+
+	return logger.WrapToContext(ctx, log)
+}
+
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+
+	return id
+}
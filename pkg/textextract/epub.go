@@ -0,0 +1,293 @@
+package textextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxEntrySize ограничивает распакованный размер одного файла внутри EPUB
+const maxEntrySize = 32 << 20
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		Id   string `xml:"id,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IdRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// extractEPUB возвращает абзацы каждого документа из spine — в порядке чтения —
+// вместе с EPUB CFI элемента, в котором абзац начинается
+func extractEPUB(data []byte) ([][]paragraph, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open epub: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var container epubContainer
+	if err := readXML(files, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("epub: no rootfile in container.xml")
+	}
+
+	opfPath := container.Rootfiles[0].FullPath
+
+	opf, err := readEntry(files, opfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg epubPackage
+	if err := xml.Unmarshal(opf, &pkg); err != nil {
+		return nil, fmt.Errorf("epub: parse %s: %w", opfPath, err)
+	}
+
+	spineStep, err := childStep(opf, "spine")
+	if err != nil {
+		return nil, fmt.Errorf("epub: parse %s: %w", opfPath, err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.Id] = item.Href
+	}
+
+	result := make([][]paragraph, 0, len(pkg.Spine))
+	for i, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IdRef]
+		if !ok {
+			continue
+		}
+
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+
+		content, err := readEntry(files, path.Join(path.Dir(opfPath), href))
+		if err != nil {
+			return nil, err
+		}
+
+		// Шаги CFI чётные: k-й дочерний элемент — это 2k
+		document := fmt.Sprintf("/%d/%d!", spineStep, 2*(i+1))
+		result = append(result, htmlParagraphs(content, document))
+	}
+
+	return result, nil
+}
+
+// childStep возвращает шаг CFI дочернего элемента name у корня документа, обычно spine в package — /6
+func childStep(content []byte, name string) (int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	depth, children := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("no %s element", name)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 {
+				continue
+			}
+
+			children++
+			if t.Name.Local == name {
+				return 2 * children, nil
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+func readXML(files map[string]*zip.File, name string, v any) error {
+	content, err := readEntry(files, name)
+	if err != nil {
+		return err
+	}
+
+	if err := xml.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("epub: parse %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func readEntry(files map[string]*zip.File, name string) ([]byte, error) {
+	file, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("epub: missing %s", name)
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("epub: open %s: %w", name, err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(io.LimitReader(r, maxEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("epub: read %s: %w", name, err)
+	}
+
+	if len(content) > maxEntrySize {
+		return nil, fmt.Errorf("epub: %s is too large", name)
+	}
+
+	return content, nil
+}
+
+// htmlParagraphs собирает текст документа, начиная новый абзац на каждом блочном элементе.
+// CFI абзаца — document и путь от корня html до элемента, где начинается его текст
+func htmlParagraphs(content []byte, document string) []paragraph {
+	result := make([]paragraph, 0)
+
+	var (
+		current strings.Builder
+		cfi     string
+	)
+	flush := func() {
+		if text := normalizeSpace(current.String()); text != "" {
+			result = append(result, paragraph{text: text, cfi: cfi})
+		}
+		current.Reset()
+		cfi = ""
+	}
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(content))
+	skip := 0
+	path := newElementPath()
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			flush()
+			return result
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+
+			text := tokenizer.Text()
+			if cfi == "" && len(bytes.TrimSpace(text)) > 0 {
+				cfi = "epubcfi(" + document + path.String() + ")"
+			}
+			current.Write(text)
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			switch token.Type {
+			case html.StartTagToken:
+				path.push(token.Data)
+				if voidElements[token.DataAtom] {
+					path.pop(token.Data)
+				}
+			case html.SelfClosingTagToken:
+				path.push(token.Data)
+				path.pop(token.Data)
+			case html.EndTagToken:
+				path.pop(token.Data)
+			}
+
+			switch token.DataAtom {
+			case atom.Script, atom.Style, atom.Head:
+				if token.Type == html.StartTagToken {
+					skip++
+				} else if token.Type == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			case atom.P, atom.Div, atom.Br, atom.Li, atom.Blockquote, atom.Tr, atom.Section,
+				atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				flush()
+			}
+		}
+	}
+}
+
+// voidElements не имеют закрывающего тега, даже если документ записан как HTML, а не XHTML
+var voidElements = map[atom.Atom]bool{
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true, atom.Embed: true, atom.Hr: true,
+	atom.Img: true, atom.Input: true, atom.Link: true, atom.Meta: true, atom.Source: true, atom.Track: true, atom.Wbr: true,
+}
+
+// elementPath следит за открытыми элементами и номерами их шагов CFI.
+// Корень html в путь не входит: шаги после «!» отсчитываются от него, body обычно /4
+type elementPath struct {
+	names    []string
+	steps    []int
+	children []int
+}
+
+func newElementPath() *elementPath {
+	return &elementPath{children: []int{0}}
+}
+
+func (p *elementPath) push(name string) {
+	last := len(p.children) - 1
+	p.children[last]++
+
+	p.names = append(p.names, name)
+	p.steps = append(p.steps, 2*p.children[last])
+	p.children = append(p.children, 0)
+}
+
+// pop закрывает name вместе с незакрытыми внутри него элементами; лишний закрывающий тег игнорируется
+func (p *elementPath) pop(name string) {
+	for i := len(p.names) - 1; i >= 0; i-- {
+		if p.names[i] != name {
+			continue
+		}
+
+		p.names = p.names[:i]
+		p.steps = p.steps[:i]
+		p.children = p.children[:i+1]
+		return
+	}
+}
+
+func (p *elementPath) String() string {
+	var result strings.Builder
+	for i, step := range p.steps {
+		// Первый шаг — сам html
+		if i == 0 {
+			continue
+		}
+		fmt.Fprintf(&result, "/%d", step)
+	}
+
+	return result.String()
+}
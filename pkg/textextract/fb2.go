@@ -0,0 +1,101 @@
+package textextract
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// extractFB2 возвращает абзацы каждой секции верхнего уровня основного тела книги.
+// Тела с примечаниями и комментариями (body name="notes") пропускаются
+func extractFB2(data []byte) ([][]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// FB2 часто в windows-1251
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	result := make([][]string, 0)
+
+	var (
+		current      strings.Builder
+		inBody       bool
+		sectionDepth int
+		// paragraphs — абзацы текущей главы; текст до первой секции идёт в отдельную главу
+		paragraphs []string
+	)
+
+	flushParagraph := func() {
+		if paragraph := normalizeSpace(current.String()); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+		current.Reset()
+	}
+
+	flushChapter := func() {
+		flushParagraph()
+		if len(paragraphs) > 0 {
+			result = append(result, paragraphs)
+		}
+		paragraphs = nil
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("fb2: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "body":
+				inBody = attr(t, "name") == ""
+			case "section":
+				if inBody && sectionDepth == 0 {
+					flushChapter()
+				}
+				sectionDepth++
+			case "p", "v", "subtitle", "text-author":
+				flushParagraph()
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "body":
+				if inBody {
+					flushChapter()
+				}
+				inBody = false
+			case "section":
+				sectionDepth--
+			case "p", "v", "subtitle", "text-author":
+				flushParagraph()
+			}
+		case xml.CharData:
+			if inBody {
+				current.Write(t)
+			}
+		}
+	}
+
+	flushChapter()
+
+	return result, nil
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
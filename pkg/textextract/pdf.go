@@ -0,0 +1,53 @@
+package textextract
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// extractPDF возвращает абзацы всех страниц одной главой: оглавление PDF не говорит,
+// где глава начинается в тексте. У каждого абзаца проставлен номер его страницы
+func extractPDF(data []byte) (result [][]paragraph, err error) {
+	// Разбор битого файла в библиотеке может закончиться паникой
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open pdf: %w", err)
+	}
+
+	paragraphs := make([]paragraph, 0)
+	// Шрифты общие для страниц, разбирать их карты символов каждый раз дорого
+	fonts := make(map[string]*pdf.Font)
+
+	for number := 1; number <= reader.NumPage(); number++ {
+		page := reader.Page(number)
+		if page.V.IsNull() {
+			continue
+		}
+
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("pdf: page %d: %w", number, err)
+		}
+
+		for _, text := range splitParagraphs(text) {
+			paragraphs = append(paragraphs, paragraph{text: text, page: number})
+		}
+	}
+
+	return [][]paragraph{paragraphs}, nil
+}
@@ -0,0 +1,190 @@
+// Package textextract достаёт текст из файлов книг для полнотекстового поиска.
+// Поддерживаются EPUB, FB2, PDF и обычный текст
+package textextract
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fragmentRunes — примерный размер фрагмента: достаточно для осмысленного сниппета,
+// но не так много, чтобы совпадение терялось в длинном тексте
+const fragmentRunes = 1500
+
+var ErrUnsupportedFormat = errors.New("unsupported book format")
+
+// Fragment — кусок текста книги. Chapter — номер главы в порядке чтения, если формат их знает,
+// Cfi — EPUB CFI начала фрагмента, Page — номер страницы PDF с единицы
+type Fragment struct {
+	Chapter *int
+	Cfi     *string
+	Page    *int
+	Text    string
+}
+
+// paragraph — абзац с местом в книге, откуда он начинается; пустые cfi и page — места нет
+type paragraph struct {
+	text string
+	cfi  string
+	page int
+}
+
+// Extract определяет формат по содержимому, а при неоднозначности — по расширению name
+func Extract(name string, data []byte) ([]Fragment, error) {
+	ext := strings.ToLower(path.Ext(name))
+
+	var (
+		chapters [][]paragraph
+		err      error
+	)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		chapters, err = extractEPUB(data)
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		chapters, err = extractPDF(data)
+	case ext == ".fb2" || bytes.Contains(head(data), []byte("<FictionBook")):
+		var texts [][]string
+		texts, err = extractFB2(data)
+		for _, chapter := range texts {
+			chapters = append(chapters, plain(chapter))
+		}
+	case ext == ".txt" || (ext == "" && utf8.Valid(data)):
+		chapters = [][]paragraph{plain(splitParagraphs(strings.ToValidUTF8(string(data), "")))}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// У обычного текста глав нет
+	numbered := len(chapters) > 1 || ext == ".epub" || ext == ".fb2"
+
+	result := make([]Fragment, 0)
+	for i, paragraphs := range chapters {
+		var chapter *int
+		if numbered {
+			chapter = &i
+		}
+
+		for _, packed := range pack(paragraphs) {
+			fragment := Fragment{Chapter: chapter, Text: packed.text}
+			if packed.cfi != "" {
+				fragment.Cfi = &packed.cfi
+			}
+			if packed.page > 0 {
+				fragment.Page = &packed.page
+			}
+
+			result = append(result, fragment)
+		}
+	}
+
+	return result, nil
+}
+
+func plain(texts []string) []paragraph {
+	result := make([]paragraph, 0, len(texts))
+	for _, text := range texts {
+		result = append(result, paragraph{text: text})
+	}
+
+	return result
+}
+
+// pack склеивает абзацы во фрагменты около fragmentRunes, слишком длинные абзацы режет по словам.
+// Фрагмент получает место своего первого абзаца и не переходит через границу страницы,
+// иначе номер страницы у него был бы неверным
+func pack(paragraphs []paragraph) []paragraph {
+	result := make([]paragraph, 0)
+
+	var (
+		current  strings.Builder
+		location paragraph
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			location.text = current.String()
+			result = append(result, location)
+			current.Reset()
+		}
+	}
+
+	for _, p := range paragraphs {
+		if p.page != location.page {
+			flush()
+		}
+
+		for _, part := range splitLong(p.text) {
+			if current.Len() > 0 && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(part) > fragmentRunes {
+				flush()
+			}
+
+			if current.Len() > 0 {
+				current.WriteString("\n")
+			} else {
+				location = paragraph{cfi: p.cfi, page: p.page}
+			}
+			current.WriteString(part)
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+func splitLong(paragraph string) []string {
+	if utf8.RuneCountInString(paragraph) <= fragmentRunes {
+		return []string{paragraph}
+	}
+
+	result := make([]string, 0)
+
+	var current strings.Builder
+	for _, word := range strings.Fields(paragraph) {
+		if current.Len() > 0 && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(word) >= fragmentRunes {
+			result = append(result, current.String())
+			current.Reset()
+		}
+
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(word)
+	}
+
+	if current.Len() > 0 {
+		result = append(result, current.String())
+	}
+
+	return result
+}
+
+// splitParagraphs делит обычный текст на абзацы по пустым строкам
+func splitParagraphs(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	result := make([]string, 0)
+	for _, block := range strings.Split(text, "\n\n") {
+		if paragraph := normalizeSpace(block); paragraph != "" {
+			result = append(result, paragraph)
+		}
+	}
+
+	return result
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+func head(data []byte) []byte {
+	return data[:min(len(data), 1024)]
+}
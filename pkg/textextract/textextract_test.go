@@ -0,0 +1,237 @@
+package textextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestExtractEPUBFollowsSpine(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package>
+			<metadata/>
+			<manifest><item id="a" href="one.xhtml"/><item id="b" href="text/two%20b.xhtml"/></manifest>
+			<spine><itemref idref="b"/><itemref idref="a"/></spine>
+		</package>`,
+		"OEBPS/one.xhtml":        `<html><head><title>skip</title></head><body><p>First  chapter</p><p>second<br/>line</p></body></html>`,
+		"OEBPS/text/two b.xhtml": `<html><body><h1>Opening</h1><script>var x;</script><div>Text</div></body></html>`,
+	}
+
+	for _, name := range []string{"mimetype", "META-INF/container.xml", "OEBPS/content.opf", "OEBPS/one.xhtml", "OEBPS/text/two b.xhtml"} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(files[name]))
+	}
+	archive.Close()
+
+	fragments, err := Extract("book.epub", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Фрагмент получает CFI элемента, где начинается его первый абзац: spine — третий
+	// элемент package (/6), документ — номер itemref, дальше путь от html. Во втором
+	// документе нет head, поэтому body в нём первый элемент — /2
+	want := []struct {
+		chapter int
+		cfi     string
+		text    string
+	}{
+		{0, "epubcfi(/6/2!/2/2)", "Opening\nText"},
+		{1, "epubcfi(/6/4!/4/2)", "First chapter\nsecond\nline"},
+	}
+
+	if len(fragments) != len(want) {
+		t.Fatalf("got %d fragments, want %d: %+v", len(fragments), len(want), fragments)
+	}
+
+	for i, w := range want {
+		if fragments[i].Chapter == nil || *fragments[i].Chapter != w.chapter || fragments[i].Text != w.text {
+			t.Errorf("fragment %d = %v %q, want %d %q", i, fragments[i].Chapter, fragments[i].Text, w.chapter, w.text)
+		}
+
+		if fragments[i].Cfi == nil {
+			t.Errorf("fragment %d has no cfi, want %s", i, w.cfi)
+		} else if *fragments[i].Cfi != w.cfi {
+			t.Errorf("fragment %d cfi = %s, want %s", i, *fragments[i].Cfi, w.cfi)
+		}
+
+		if fragments[i].Page != nil {
+			t.Errorf("fragment %d page = %d, epub has no pages", i, *fragments[i].Page)
+		}
+	}
+}
+
+func TestHTMLParagraphsCfi(t *testing.T) {
+	content := `<?xml version="1.0"?>
+<html><head><meta charset="utf-8"><title>skip</title></head>
+<body><section><h1>Title</h1><img src="a.png"><p>One <em>two</em></p></section><hr/><p>Three</p></body></html>`
+
+	got := htmlParagraphs([]byte(content), "/6/2!")
+
+	// meta и img без закрывающего тега не сдвигают нумерацию соседей
+	want := []paragraph{
+		{text: "Title", cfi: "epubcfi(/6/2!/4/2/2)"},
+		{text: "One two", cfi: "epubcfi(/6/2!/4/2/6)"},
+		{text: "Three", cfi: "epubcfi(/6/2!/4/6)"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("paragraph %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractPDFPages(t *testing.T) {
+	data := buildPDF("First page", "Second page")
+
+	fragments, err := Extract("book.pdf", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Фрагмент не переходит через границу страницы, даже если обе влезли бы в один
+	if len(fragments) != 2 {
+		t.Fatalf("got %d fragments, want 2: %+v", len(fragments), fragments)
+	}
+
+	for i, fragment := range fragments {
+		if fragment.Page == nil || *fragment.Page != i+1 {
+			t.Errorf("fragment %d page = %v, want %d", i, fragment.Page, i+1)
+		}
+
+		if fragment.Chapter != nil || fragment.Cfi != nil {
+			t.Errorf("fragment %d has chapter %v and cfi %v, pdf has neither", i, fragment.Chapter, fragment.Cfi)
+		}
+	}
+
+	if !strings.Contains(fragments[1].Text, "Second page") {
+		t.Errorf("second fragment = %q", fragments[1].Text)
+	}
+}
+
+// buildPDF собирает минимальный PDF: по странице на строку, шрифт Helvetica
+func buildPDF(pages ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	kids := make([]string, 0, len(pages))
+	for _, text := range pages {
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+		contents := len(objects)
+
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contents))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)))
+	}
+
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, 0, len(objects))
+	for i, object := range objects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func TestExtractFB2DecodesCharsetAndSkipsNotes(t *testing.T) {
+	source := `<?xml version="1.0" encoding="windows-1251"?>
+<FictionBook>
+	<description><title-info><book-title>Название</book-title></title-info></description>
+	<body>
+		<section><title><p>Глава 1</p></title><p>Текст первой главы</p><section><p>Вложенная</p></section></section>
+		<section><p>Вторая</p></section>
+	</body>
+	<body name="notes"><section><p>Примечание</p></section></body>
+</FictionBook>`
+
+	encoded, err := charmap.Windows1251.NewEncoder().String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fragments, err := Extract("book.fb2", []byte(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fragments) != 2 {
+		t.Fatalf("got %d fragments, want 2: %+v", len(fragments), fragments)
+	}
+
+	if got := fragments[0].Text; got != "Глава 1\nТекст первой главы\nВложенная" {
+		t.Errorf("first chapter = %q", got)
+	}
+
+	if got := fragments[1].Text; got != "Вторая" || *fragments[1].Chapter != 1 {
+		t.Errorf("second chapter = %d %q", *fragments[1].Chapter, got)
+	}
+}
+
+func TestExtractPlainTextPacksParagraphs(t *testing.T) {
+	medium := strings.Repeat("слово ", fragmentRunes/12)
+	huge := strings.Repeat("слово ", fragmentRunes/2)
+
+	fragments, err := Extract("book.txt", []byte("Первый\r\nабзац\r\n\r\n"+medium+"\n\n"+medium+"\n\n"+huge))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Короткий абзац склеивается со средним, второй средний уже не влезает,
+	// огромный режется по словам на три части
+	if len(fragments) != 5 {
+		t.Fatalf("got %d fragments, want 5", len(fragments))
+	}
+
+	if fragments[0].Chapter != nil {
+		t.Errorf("plain text should have no chapters")
+	}
+
+	if !strings.HasPrefix(fragments[0].Text, "Первый абзац\n") {
+		t.Errorf("first fragment = %q", fragments[0].Text)
+	}
+
+	for i, fragment := range fragments {
+		if n := len([]rune(fragment.Text)); n > fragmentRunes {
+			t.Errorf("fragment %d has %d runes, limit %d", i, n, fragmentRunes)
+		}
+	}
+}
+
+func TestExtractUnsupported(t *testing.T) {
+	if _, err := Extract("book.djvu", []byte("AT&TFORM\x00\xff")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ключ из приложения B к RFC 6238 для SHA-1
+var rfcKey = []byte("12345678901234567890")
+
+// Векторы RFC 6238 даны для 8 цифр; шестизначный код — их последние 6 цифр
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		if got := generate(rfcKey, tt.unix/int64(Period.Seconds())); got != tt.code {
+			t.Errorf("generate at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := encoding.EncodeToString(rfcKey)
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / int64(Period.Seconds())
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{"current step", secret, "050471", step, true},
+		{"lowercase secret", strings.ToLower(secret), "050471", step, true},
+		{"previous step within skew", secret, generate(rfcKey, step-1), step - 1, true},
+		{"next step within skew", secret, generate(rfcKey, step+1), step + 1, true},
+		{"outside skew", secret, generate(rfcKey, step-2), 0, false},
+		{"wrong code", secret, "000000", 0, false},
+		{"wrong length", secret, "94287082", 0, false},
+		{"broken secret", "not base32!", "050471", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(tt.secret, tt.code, now)
+			if ok != tt.wantOk || gotStep != tt.wantStep {
+				t.Fatalf("Validate = %d, %v, want %d, %v", gotStep, ok, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGenerateSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(key) != secretSize {
+		t.Fatalf("secret has %d bytes, want %d", len(key), secretSize)
+	}
+
+	now := time.Now()
+	if _, ok := Validate(secret, generate(key, now.Unix()/int64(Period.Seconds())), now); !ok {
+		t.Fatal("code for a fresh secret is rejected")
+	}
+}
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE books
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED;
+
+ALTER TABLE authors
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(biography, '')), 'D') ||
+        setweight(to_tsvector('english', coalesce(biography, '')), 'D')
+    ) STORED;
+
+CREATE INDEX books_search_vector_idx ON books USING GIN (search_vector);
+CREATE INDEX authors_search_vector_idx ON authors USING GIN (search_vector);
+
+-- Триграммы для нечёткого поиска с опечатками
+CREATE INDEX books_title_trgm_idx ON books USING GIN (title gin_trgm_ops);
+CREATE INDEX authors_name_trgm_idx ON authors USING GIN (name gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS books_search_vector_idx, authors_search_vector_idx, books_title_trgm_idx, authors_name_trgm_idx;
+
+ALTER TABLE books DROP COLUMN search_vector;
+ALTER TABLE authors DROP COLUMN search_vector;
+
+DROP EXTENSION IF EXISTS pg_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE book_text_fragments (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+                                     position INTEGER NOT NULL, -- Порядковый номер фрагмента в книге
+                                     chapter_index INTEGER, -- Номер главы (EPUB/FB2)
+                                     cfi VARCHAR(255), -- EPUB CFI начала фрагмента
+                                     page INTEGER, -- Страница (PDF)
+                                     content TEXT NOT NULL,
+                                     search_vector tsvector GENERATED ALWAYS AS (
+                                         to_tsvector('russian', content) || to_tsvector('english', content)
+                                     ) STORED,
+                                     UNIQUE(book_id, position)
+);
+
+CREATE INDEX book_text_fragments_search_vector_idx ON book_text_fragments USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE book_text_fragments;
+-- +goose StatementEnd
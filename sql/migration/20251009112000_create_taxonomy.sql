@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE genres (
+                        id SERIAL PRIMARY KEY,
+                        parent_id INTEGER REFERENCES genres(id) ON DELETE CASCADE, -- Родительский жанр (NULL для корневых)
+                        name VARCHAR(100) NOT NULL,
+                        slug VARCHAR(100) NOT NULL UNIQUE,
+                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE book_genres (
+                             book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+                             genre_id INTEGER NOT NULL REFERENCES genres(id) ON DELETE CASCADE,
+                             PRIMARY KEY (book_id, genre_id)
+);
+
+CREATE TABLE tags (
+                      id SERIAL PRIMARY KEY,
+                      name VARCHAR(50) NOT NULL,
+                      status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+                      created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                      moderated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                      created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                      moderated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX tags_name_lower_idx ON tags (lower(name));
+
+CREATE TABLE book_tags (
+                           book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+                           tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                           added_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                           created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                           PRIMARY KEY (book_id, tag_id)
+);
+
+CREATE TABLE series (
+                        id SERIAL PRIMARY KEY,
+                        title VARCHAR(255) NOT NULL,
+                        description TEXT,
+                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE books
+    ADD COLUMN series_id INTEGER REFERENCES series(id) ON DELETE SET NULL,
+    ADD COLUMN series_volume INTEGER CHECK (series_volume > 0), -- Номер тома в серии
+    ADD CONSTRAINT books_series_volume_key UNIQUE (series_id, series_volume);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE books
+    DROP CONSTRAINT books_series_volume_key,
+    DROP COLUMN series_volume,
+    DROP COLUMN series_id;
+
+DROP TABLE series, book_tags, tags, book_genres, genres;
+-- +goose StatementEnd
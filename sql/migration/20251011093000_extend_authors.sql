@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE authors
+    ADD COLUMN birth_date DATE,
+    ADD COLUMN death_date DATE,
+    ADD CONSTRAINT authors_life_dates_check CHECK (death_date IS NULL OR birth_date IS NULL OR death_date >= birth_date);
+
+CREATE TABLE author_aliases (
+                                id SERIAL PRIMARY KEY,
+                                author_id INTEGER NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+                                name VARCHAR(255) NOT NULL,
+                                kind VARCHAR(20) NOT NULL DEFAULT 'alternate' CHECK (kind IN ('alternate', 'transliteration', 'pseudonym')),
+                                locale VARCHAR(10), -- Язык написания, например: "ru", "en"
+                                created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX author_aliases_author_name_idx ON author_aliases (author_id, lower(name));
+CREATE INDEX author_aliases_name_trgm_idx ON author_aliases USING GIN (name gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE author_aliases;
+
+ALTER TABLE authors
+    DROP CONSTRAINT authors_life_dates_check,
+    DROP COLUMN death_date,
+    DROP COLUMN birth_date;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE book_contributors (
+                                   book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+                                   author_id INTEGER NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+                                   role VARCHAR(20) NOT NULL DEFAULT 'author' CHECK (role IN ('author', 'translator', 'editor', 'illustrator')),
+                                   position SMALLINT NOT NULL DEFAULT 0, -- Порядок в списке участников
+                                   PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX book_contributors_author_id_idx ON book_contributors (author_id);
+
+INSERT INTO book_contributors (book_id, author_id, role)
+SELECT id, author_id, 'author' FROM books;
+
+ALTER TABLE books DROP COLUMN author_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE books ADD COLUMN author_id INTEGER REFERENCES authors(id) ON DELETE CASCADE;
+
+UPDATE books b
+SET author_id = (
+    SELECT bc.author_id
+    FROM book_contributors bc
+    WHERE bc.book_id = b.id
+    ORDER BY (bc.role = 'author') DESC, bc.position
+    LIMIT 1
+);
+
+-- Книгу без участников откатить некуда: лучше остановить откат, чем молча удалить её из каталога
+DO $$
+DECLARE
+    orphans TEXT;
+BEGIN
+    SELECT string_agg(id::TEXT, ', ' ORDER BY id) INTO orphans FROM books WHERE author_id IS NULL;
+
+    IF orphans IS NOT NULL THEN
+        RAISE EXCEPTION 'cannot roll back book_contributors: books without contributors: %', orphans
+            USING HINT = 'add an author to these books or delete them explicitly, then roll back again';
+    END IF;
+END
+$$;
+
+ALTER TABLE books ALTER COLUMN author_id SET NOT NULL;
+
+DROP TABLE book_contributors;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notifications (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               type VARCHAR(50) NOT NULL,
+                               payload JSONB NOT NULL DEFAULT '{}',
+                               read_at TIMESTAMP WITH TIME ZONE,
+                               created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC);
+
+-- Отметка о том, что подписчики авторов уже получили уведомление о книге
+ALTER TABLE books ADD COLUMN followers_notified_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE books SET followers_notified_at = COALESCE(created_at, CURRENT_TIMESTAMP);
+
+CREATE INDEX books_followers_pending_idx ON books (created_at) WHERE followers_notified_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX books_followers_pending_idx;
+
+ALTER TABLE books DROP COLUMN followers_notified_at;
+
+DROP TABLE notifications;
+-- +goose StatementEnd
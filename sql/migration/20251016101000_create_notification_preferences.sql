@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE notification_preferences (
+                                          user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                          type VARCHAR(50) NOT NULL,
+                                          in_app BOOLEAN NOT NULL DEFAULT TRUE,
+                                          email BOOLEAN NOT NULL DEFAULT FALSE,
+                                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                          PRIMARY KEY (user_id, type)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE notification_preferences;
+
+DROP INDEX notifications_unread_idx;
+-- +goose StatementEnd
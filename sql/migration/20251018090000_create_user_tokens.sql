@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Аккаунты, созданные до появления подтверждения почты, считаем подтверждёнными
+UPDATE users SET email_verified_at = created_at;
+
+CREATE TABLE user_tokens (
+                             id BIGSERIAL PRIMARY KEY,
+                             user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+                             token_hash CHAR(64) NOT NULL UNIQUE,
+                             expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                             used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+                             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX user_tokens_user_purpose_idx ON user_tokens (user_id, purpose, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_tokens;
+
+ALTER TABLE users DROP COLUMN email_verified_at;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64) DEFAULT NULL;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+-- Последний принятый 30-секундный шаг, чтобы один код нельзя было использовать дважды
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT DEFAULT NULL;
+
+CREATE TABLE user_recovery_codes (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     code_hash CHAR(64) NOT NULL,
+                                     used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+                                     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                     UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE two_factor_policies (
+                                     role VARCHAR(20) PRIMARY KEY CHECK (role IN ('admin', 'moderator', 'user')),
+                                     required BOOLEAN NOT NULL DEFAULT FALSE,
+                                     updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                                     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Токен второго шага входа живёт в той же таблице, что и токены из писем
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password', 'two_factor'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM user_tokens WHERE purpose = 'two_factor';
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password'));
+
+DROP TABLE two_factor_policies;
+DROP TABLE user_recovery_codes;
+
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_enabled_at;
+ALTER TABLE users DROP COLUMN totp_secret;
+-- +goose StatementEnd
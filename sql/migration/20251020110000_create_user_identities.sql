@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_identities (
+                                 id BIGSERIAL PRIMARY KEY,
+                                 user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 provider VARCHAR(50) NOT NULL,
+                                 subject VARCHAR(255) NOT NULL,
+                                 email VARCHAR(129),
+                                 created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                 last_login_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+                                 UNIQUE (provider, subject),
+                                 UNIQUE (user_id, provider)
+);
+
+-- Состояние входа через провайдера между редиректом и колбэком
+CREATE TABLE oidc_states (
+                             state_hash CHAR(64) PRIMARY KEY,
+                             provider VARCHAR(50) NOT NULL,
+                             nonce VARCHAR(64) NOT NULL,
+                             code_verifier VARCHAR(128) NOT NULL,
+                             link_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+                             expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oidc_states;
+DROP TABLE user_identities;
+-- +goose StatementEnd
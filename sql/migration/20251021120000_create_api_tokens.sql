@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Персональные токены для скриптов и интеграций. Хранится только хэш, сам токен показывается один раз
+CREATE TABLE api_tokens (
+                            id BIGSERIAL PRIMARY KEY,
+                            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                            name VARCHAR(100) NOT NULL,
+                            token_prefix VARCHAR(16) NOT NULL, -- начало токена, чтобы пользователь узнал его в списке
+                            token_hash CHAR(64) NOT NULL UNIQUE,
+                            scopes TEXT[] NOT NULL,
+                            expires_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+                            last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+                            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                            CHECK (scopes <@ ARRAY['read:shelf', 'write:shelf', 'admin:*']::TEXT[])
+);
+
+CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_tokens;
+-- +goose StatementEnd
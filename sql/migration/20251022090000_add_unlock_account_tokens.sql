@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ссылка из письма о блокировке входа после серии неудачных попыток
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password', 'two_factor', 'unlock_account'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM user_tokens WHERE purpose = 'unlock_account';
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password', 'two_factor'));
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Флаги постепенного включения возможностей. Выключенный флаг закрыт для всех, включённый
+-- открыт ролям из roles, пользователям из user_ids и percentage процентам остальных пользователей
+CREATE TABLE feature_flags (
+                               name VARCHAR(64) PRIMARY KEY CHECK (name ~ '^[a-z][a-z0-9_]*$'),
+                               description TEXT,
+                               enabled BOOLEAN NOT NULL DEFAULT FALSE,
+                               roles TEXT[] NOT NULL DEFAULT '{}',
+                               user_ids INTEGER[] NOT NULL DEFAULT '{}',
+                               percentage SMALLINT NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+                               updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                               created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                               updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                               CHECK (roles <@ ARRAY['admin', 'moderator', 'user']::TEXT[])
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE feature_flags;
+-- +goose StatementEnd
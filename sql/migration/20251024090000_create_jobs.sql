@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Очередь фоновых задач. Воркеры забирают готовые строки через FOR UPDATE SKIP LOCKED
+-- и держат их в running до locked_until. Успешные задачи удаляются, исчерпавшие
+-- попытки остаются в статусе dead до ручного повтора
+CREATE TABLE jobs (
+                      id BIGSERIAL PRIMARY KEY,
+                      queue VARCHAR(64) NOT NULL,
+                      type VARCHAR(64) NOT NULL,
+                      payload JSONB NOT NULL DEFAULT '{}',
+                      status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'dead')),
+                      attempts INTEGER NOT NULL DEFAULT 0,
+                      max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+                      run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                      locked_until TIMESTAMP WITH TIME ZONE,
+                      last_error TEXT,
+                      request_id VARCHAR(128),
+                      created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                      updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX jobs_queue_run_at_pending_idx ON jobs (queue, run_at) WHERE status = 'pending';
+CREATE INDEX jobs_queue_locked_until_running_idx ON jobs (queue, locked_until) WHERE status = 'running';
+CREATE INDEX jobs_updated_at_dead_idx ON jobs (updated_at DESC) WHERE status = 'dead';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE jobs;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Язык писем, которые уходят без запроса пользователя: уведомления и дайджесты
+ALTER TABLE users ADD COLUMN locale VARCHAR(8) NOT NULL DEFAULT 'ru';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN locale;
+-- +goose StatementEnd
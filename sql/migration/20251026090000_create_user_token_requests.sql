@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Когда пользователь последний раз запросил письмо со ссылкой. Токен выпускает задача в очереди,
+-- поэтому интервал между письмами отсчитывается от запроса, а не от user_tokens.created_at
+CREATE TABLE user_token_requests (
+                                     user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     purpose VARCHAR(20) NOT NULL,
+                                     requested_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                     PRIMARY KEY (user_id, purpose)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_token_requests;
+-- +goose StatementEnd
@@ -37,17 +37,29 @@ INSERT INTO authors (name, biography, photo_url) VALUES
                                                      ('Джоан Роулинг', 'Британская писательница, сценаристка и кинопродюсер, наиболее известная как автор серии романов о Гарри Поттере.', 'https://example.com/photos/rowling.jpg');
 
 -- 3. Добавление книг (предполагая, что uploaded_by ссылается на существующих пользователей с id 1-10)
-INSERT INTO books (title, description, cover_image_url, file_url, author_id, uploaded_by) VALUES
-                                                                                              ('Война и мир', 'Роман-эпопея, описывающий русское общество в эпоху войн против Наполеона.', 'https://example.com/covers/war_and_peace.jpg', '/books/war_and_peace.pdf', 1, 1),
-                                                                                              ('Преступление и наказание', 'Роман о бывшем студенте Родионе Раскольникове, совершившем убийство.', 'https://example.com/covers/crime_and_punishment.jpg', '/books/crime_and_punishment.pdf', 2, 2),
-                                                                                              ('Вишнёвый сад', 'Лирическая пьеса в четырёх действиях, последняя пьеса Чехова.', 'https://example.com/covers/cherry_orchard.jpg', '/books/cherry_orchard.pdf', 3, 3),
-                                                                                              ('Евгений Онегин', 'Роман в стихах, одно из самых значительных произведений русской словесности.', 'https://example.com/covers/eugene_onegin.jpg', '/books/eugene_onegin.pdf', 4, 4),
-                                                                                              ('Мастер и Маргарита', 'Роман, сочетающий в себе элементы сатиры, фантастики и философской притчи.', 'https://example.com/covers/master_margarita.jpg', '/books/master_margarita.pdf', 5, 5),
-                                                                                              ('1984', 'Роман-антиутопия, изображающий тоталитарное общество.', 'https://example.com/covers/1984.jpg', '/books/1984.pdf', 6, 1),
-                                                                                              ('451 градус по Фаренгейту', 'Роман-антиутопия о обществе, где книги находятся под запретом.', 'https://example.com/covers/fahrenheit451.jpg', '/books/fahrenheit451.pdf', 7, 2),
-                                                                                              ('Старик и море', 'Повесть о старом рыбаке Сантьяго и его борьбе с гигантской рыбой.', 'https://example.com/covers/old_man_sea.jpg', '/books/old_man_sea.pdf', 8, 3),
-                                                                                              ('Убийство в Восточном экспрессе', 'Детективный роман о расследовании убийства в поезде.', 'https://example.com/covers/murder_orient_express.jpg', '/books/murder_orient_express.pdf', 9, 4),
-                                                                                              ('Гарри Поттер и философский камень', 'Первый роман в серии книг о юном волшебнике Гарри Поттере.', 'https://example.com/covers/harry_potter1.jpg', '/books/harry_potter1.pdf', 10, 5);
+INSERT INTO books (title, description, cover_image_url, file_url, uploaded_by) VALUES
+                                                                                              ('Война и мир', 'Роман-эпопея, описывающий русское общество в эпоху войн против Наполеона.', 'https://example.com/covers/war_and_peace.jpg', '/books/war_and_peace.pdf', 1),
+                                                                                              ('Преступление и наказание', 'Роман о бывшем студенте Родионе Раскольникове, совершившем убийство.', 'https://example.com/covers/crime_and_punishment.jpg', '/books/crime_and_punishment.pdf', 2),
+                                                                                              ('Вишнёвый сад', 'Лирическая пьеса в четырёх действиях, последняя пьеса Чехова.', 'https://example.com/covers/cherry_orchard.jpg', '/books/cherry_orchard.pdf', 3),
+                                                                                              ('Евгений Онегин', 'Роман в стихах, одно из самых значительных произведений русской словесности.', 'https://example.com/covers/eugene_onegin.jpg', '/books/eugene_onegin.pdf', 4),
+                                                                                              ('Мастер и Маргарита', 'Роман, сочетающий в себе элементы сатиры, фантастики и философской притчи.', 'https://example.com/covers/master_margarita.jpg', '/books/master_margarita.pdf', 5),
+                                                                                              ('1984', 'Роман-антиутопия, изображающий тоталитарное общество.', 'https://example.com/covers/1984.jpg', '/books/1984.pdf', 1),
+                                                                                              ('451 градус по Фаренгейту', 'Роман-антиутопия о обществе, где книги находятся под запретом.', 'https://example.com/covers/fahrenheit451.jpg', '/books/fahrenheit451.pdf', 2),
+                                                                                              ('Старик и море', 'Повесть о старом рыбаке Сантьяго и его борьбе с гигантской рыбой.', 'https://example.com/covers/old_man_sea.jpg', '/books/old_man_sea.pdf', 3),
+                                                                                              ('Убийство в Восточном экспрессе', 'Детективный роман о расследовании убийства в поезде.', 'https://example.com/covers/murder_orient_express.jpg', '/books/murder_orient_express.pdf', 4),
+                                                                                              ('Гарри Поттер и философский камень', 'Первый роман в серии книг о юном волшебнике Гарри Поттере.', 'https://example.com/covers/harry_potter1.jpg', '/books/harry_potter1.pdf', 5);
+
+INSERT INTO book_contributors (book_id, author_id, role) VALUES
+                                                             (1, 1, 'author'),
+                                                             (2, 2, 'author'),
+                                                             (3, 3, 'author'),
+                                                             (4, 4, 'author'),
+                                                             (5, 5, 'author'),
+                                                             (6, 6, 'author'),
+                                                             (7, 7, 'author'),
+                                                             (8, 8, 'author'),
+                                                             (9, 9, 'author'),
+                                                             (10, 10, 'author');
 
 -- 4. Добавление рецензий
 INSERT INTO reviews (book_id, user_id, rating, title, content) VALUES